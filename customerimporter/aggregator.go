@@ -0,0 +1,77 @@
+package customerimporter
+
+import (
+	"strings"
+	"sync"
+)
+
+// Aggregator counts customers per email domain from addresses fed one at a time, decoupled from
+// files and CSV, for services that receive customer records continuously. It validates and
+// normalizes each address exactly like an import and can be snapshotted at any point. It is
+// safe for concurrent use.
+type Aggregator struct {
+	mu  sync.Mutex
+	run *importRun
+}
+
+// NewAggregator returns an empty Aggregator configured with the importer options that apply to
+// single addresses: validation (WithStrictMode, WithEmailPattern, WithEmailRegex, ...),
+// normalization (WithStripWWWPrefix), WithDomainFilter, WithDeduplication, WithEnsureDomains and
+// the per-domain details of DomainData. Options describing input rows or files, such as column
+// indexes, error policies, rejects output, WithExternalAggregation and WithCountMinSketch, are
+// ignored.
+func NewAggregator(opts ...Option) *Aggregator {
+	ci := NewCustomerImporter("", opts...)
+	// Addresses fed to Add carry no customer ID
+	ci.config.CustomerIDColumn = nil
+
+	var data counter = make(mapCounter)
+	if ci.config.trieCounter {
+		data = &trieCounter{}
+	}
+	return &Aggregator{run: ci.newRun(data)}
+}
+
+// Add validates email and counts it towards its domain. An invalid address is not counted and
+// its validation error is returned; the aggregation stays usable.
+func (a *Aggregator) Add(email string) error {
+	ci := a.run.ci
+	value, err := ci.emailField(email)
+	if err != nil {
+		return err
+	}
+	domain, err := ci.extractDomain(value)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.run.summary.Rows++
+	if strings.TrimSpace(value) != value {
+		a.run.summary.TrimmedValues++
+	}
+	var key string
+	if a.run.seen != nil {
+		key = a.run.dedupKey("", value, domain)
+	}
+	a.run.countDomain(domain, value, key, "", 1)
+	return nil
+}
+
+// Snapshot returns the domains counted so far, sorted alphabetically like an import result.
+// Later calls to Add do not modify the returned slice.
+func (a *Aggregator) Snapshot() []DomainData {
+	data, _ := a.SnapshotWithSummary()
+	return data
+}
+
+// SnapshotWithSummary is like Snapshot and additionally returns the statistics collected so
+// far, with Rows counting the accepted addresses.
+func (a *Aggregator) SnapshotWithSummary() ([]DomainData, Summary) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Only an external aggregation can fail, and the aggregator never spills
+	data, summary, _ := a.run.result()
+	return data, summary
+}
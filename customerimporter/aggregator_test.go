@@ -0,0 +1,93 @@
+package customerimporter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAggregator(t *testing.T) {
+	a := NewAggregator(WithStripWWWPrefix(), WithDeduplication())
+	if got := a.Snapshot(); len(got) != 0 {
+		t.Errorf("empty snapshot = %v, want none", got)
+	}
+
+	steps := []struct {
+		emails []string
+		want   string
+	}{
+		{
+			emails: []string{"john@example.com", "jane@www.example.com"},
+			want:   "[example.com 2]",
+		},
+		{
+			emails: []string{"max@other.com", "JOHN@example.com"},
+			want:   "[example.com 2 other.com 1]",
+		},
+		{
+			emails: []string{" amy@other.com "},
+			want:   "[example.com 2 other.com 2]",
+		},
+	}
+
+	var previous []DomainData
+	for i, step := range steps {
+		for _, email := range step.emails {
+			if err := a.Add(email); err != nil {
+				t.Fatalf("Add(%q): %v", email, err)
+			}
+		}
+		snapshot := a.Snapshot()
+		var got []any
+		for _, d := range snapshot {
+			got = append(got, d.Domain, d.CustomerQuantity)
+		}
+		if fmt.Sprint(got) != step.want {
+			t.Errorf("snapshot %d = %v, want %s", i, got, step.want)
+		}
+		if i > 0 && (len(previous) != i || previous[0].CustomerQuantity != 2) {
+			t.Errorf("earlier snapshot modified: %v", previous)
+		}
+		previous = snapshot
+	}
+
+	_, summary := a.SnapshotWithSummary()
+	if summary.Rows != 5 || summary.Duplicates != 1 || summary.TrimmedValues != 1 || summary.Customers != 4 {
+		t.Errorf("summary = %+v, want 5 rows, 1 duplicate, 1 trimmed value and 4 customers", summary)
+	}
+}
+
+func TestAggregatorInvalidEmail(t *testing.T) {
+	a := NewAggregator(WithStrictMode())
+	for _, email := range []string{"not-an-email", "john@localhost", "john@"} {
+		if err := a.Add(email); err == nil {
+			t.Errorf("Add(%q) = nil, want error", email)
+		}
+	}
+	if err := a.Add("john@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	data, summary := a.SnapshotWithSummary()
+	if len(data) != 1 || data[0].Domain != "example.com" || summary.Rows != 1 {
+		t.Errorf("got %v with %d rows, want only example.com after 1 row", data, summary.Rows)
+	}
+}
+
+func TestAggregatorConcurrent(t *testing.T) {
+	a := NewAggregator()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = a.Add("john@example.com")
+				_ = a.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	if got := a.Snapshot(); len(got) != 1 || got[0].CustomerQuantity != 800 {
+		t.Errorf("got %v, want example.com counted 800 times", got)
+	}
+}
@@ -0,0 +1,97 @@
+package customerimporter
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+)
+
+// aggregationCache is the content of a WithAggregationCache file.
+type aggregationCache struct {
+	// Key is the Fingerprint of the import that produced the entry.
+	Key     string
+	Data    []DomainData
+	Summary Summary
+}
+
+// importCached is ImportWithSummary under WithAggregationCache: it returns the cached result
+// when the cache key matches the Fingerprint of the input and configuration, and otherwise
+// imports the file and stores the result for the next run. The CacheKey parts are hashed
+// along, and without them the cache is bypassed while a function option changes the result.
+func (ci CustomerImporter) importCached(load func() ([]DomainData, Summary, error)) ([]DomainData, Summary, error) {
+	if ci.config.unkeyedFuncs() {
+		slog.Warn("function options are set without a cache key, bypassing aggregation cache", "cache", ci.config.CacheFile)
+		return load()
+	}
+	key, err := ci.Fingerprint(ci.config.CacheKey...)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	if cached, ok := ci.readCache(key); ok {
+		slog.Info("loaded aggregation from cache", "cache", ci.config.CacheFile, "unique_domains", cached.Summary.Domains)
+		return cached.Data, cached.Summary, nil
+	}
+
+	data, summary, err := load()
+	if err != nil {
+		// Partial results are never cached
+		return data, summary, err
+	}
+	if err := ci.writeCache(aggregationCache{Key: key, Data: data, Summary: summary}); err != nil {
+		slog.Warn("failed to write aggregation cache", "error", err, "cache", ci.config.CacheFile)
+	}
+	return data, summary, nil
+}
+
+// unkeyedFuncs reports whether a function option that changes the result is set without
+// CacheKey parts describing it, so Config.String cannot tell two such configurations apart.
+func (c Config) unkeyedFuncs() bool {
+	return len(c.CacheKey) == 0 && (c.DomainFilter != nil || c.LinePreprocessor != nil || c.RowValidator != nil)
+}
+
+// readCache returns the cache entry when it exists and was stored under key. A cache that cannot
+// be read is treated as a miss and overwritten after the import.
+func (ci CustomerImporter) readCache(key string) (aggregationCache, bool) {
+	file, err := os.Open(ci.config.CacheFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return aggregationCache{}, false
+	}
+	if err != nil {
+		slog.Warn("failed to open aggregation cache", "error", err, "cache", ci.config.CacheFile)
+		return aggregationCache{}, false
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var cached aggregationCache
+	if err := gob.NewDecoder(file).Decode(&cached); err != nil {
+		slog.Warn("ignoring unreadable aggregation cache", "error", err, "cache", ci.config.CacheFile)
+		return aggregationCache{}, false
+	}
+	if cached.Key != key {
+		slog.Info("aggregation cache is stale, re-reading input", "cache", ci.config.CacheFile)
+		return aggregationCache{}, false
+	}
+	if cached.Data == nil {
+		// gob does not distinguish empty from nil slices; imports return an empty one
+		cached.Data = []DomainData{}
+	}
+	return cached, true
+}
+
+// writeCache stores entry in the cache file, replacing the previous entry.
+func (ci CustomerImporter) writeCache(entry aggregationCache) error {
+	file, err := os.Create(ci.config.CacheFile)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(file).Encode(entry); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to encode aggregation cache: %w", err)
+	}
+	return file.Close()
+}
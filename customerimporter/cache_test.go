@@ -0,0 +1,138 @@
+package customerimporter
+
+import (
+	"context"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithAggregationCache(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "customers.csv")
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@gmail.com,Male,192.168.1.1
+Jane,Doe,jane@acme.com,Female,192.168.1.2
+Max,Roe,max@gmail.com,Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	cachePath := filepath.Join(dir, "aggregation.gob")
+	ci := NewCustomerImporter(csvPath, WithAggregationCache(cachePath), WithSampleEmail())
+
+	want, wantSummary, err := ci.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, summary, err := ci.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) || summary != wantSummary {
+		t.Errorf("cached run = %+v, %+v, want %+v, %+v", got, summary, want, wantSummary)
+	}
+
+	// Plant a marker domain in the cache entry: a run reading the cache returns it
+	entry := readTestCache(t, cachePath)
+	entry.Data = append(entry.Data, DomainData{Domain: "from-cache.test", CustomerQuantity: 1})
+	writeTestCache(t, cachePath, entry)
+	got, _, err = ci.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want)+1 || got[len(got)-1].Domain != "from-cache.test" {
+		t.Errorf("second run did not use the cache: %+v", got)
+	}
+
+	// Changed content invalidates the entry
+	if err := writeTestCSV(csvPath, content+"\nAnn,Poe,ann@beta.org,Female,192.168.1.4"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	got, _, err = ci.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	domains := make([]string, len(got))
+	for i, d := range got {
+		domains[i] = d.Domain
+	}
+	if wantDomains := []string{"acme.com", "beta.org", "gmail.com"}; !reflect.DeepEqual(domains, wantDomains) {
+		t.Errorf("domains after a change = %v, want %v", domains, wantDomains)
+	}
+	if entry := readTestCache(t, cachePath); len(entry.Data) != 3 {
+		t.Errorf("cache not replaced after a change: %+v", entry.Data)
+	}
+}
+
+func TestWithAggregationCacheFilter(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "customers.csv")
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@github.io,Male,192.168.1.1
+Jane,Doe,jane@hubpages.com,Female,192.168.1.2
+Max,Roe,max@cnet.com,Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	cachePath := filepath.Join(dir, "aggregation.gob")
+	importFiltered := func(substr string, opts ...Option) []string {
+		t.Helper()
+		opts = append(opts, WithAggregationCache(cachePath), WithDomainFilter(DomainContains(substr)))
+		data, err := NewCustomerImporter(csvPath, opts...).ImportDomainData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		domains := make([]string, len(data))
+		for i, d := range data {
+			domains[i] = d.Domain
+		}
+		return domains
+	}
+
+	// A filter described by the cache key misses the entry of another filter
+	if got := importFiltered("hub", WithCacheKey("grep=hub")); !reflect.DeepEqual(got, []string{"github.io", "hubpages.com"}) {
+		t.Fatalf("first run = %v", got)
+	}
+	if got := importFiltered("cnet", WithCacheKey("grep=cnet")); !reflect.DeepEqual(got, []string{"cnet.com"}) {
+		t.Errorf("run with another filter = %v, want [cnet.com]", got)
+	}
+	if entry := readTestCache(t, cachePath); len(entry.Data) != 1 {
+		t.Errorf("cache not replaced for the new filter: %+v", entry.Data)
+	}
+
+	// Without a cache key the cache is bypassed, neither read nor written
+	if got := importFiltered("hub"); !reflect.DeepEqual(got, []string{"github.io", "hubpages.com"}) {
+		t.Errorf("run without a cache key = %v, want [github.io hubpages.com]", got)
+	}
+	if entry := readTestCache(t, cachePath); len(entry.Data) != 1 {
+		t.Errorf("cache written without a cache key: %+v", entry.Data)
+	}
+}
+
+func readTestCache(t *testing.T, path string) aggregationCache {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var entry aggregationCache
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func writeTestCache(t *testing.T, path string, entry aggregationCache) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := gob.NewEncoder(file).Encode(entry); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,82 @@
+package customerimporter
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// CasingVariants lists the distinct spellings a domain arrived with, as reported by
+// WithCasingReport.
+type CasingVariants struct {
+	// Domain is the case-folded domain, lowercased for ASCII domains.
+	Domain string
+	// Variants holds every distinct spelling seen, in the order first seen, e.g.
+	// ["Example.com", "example.com"].
+	Variants []string
+}
+
+// LogCasingVariants is a WithCasingReport callback logging one warning per inconsistently cased
+// domain with its spellings.
+func LogCasingVariants(variants []CasingVariants) {
+	for _, v := range variants {
+		slog.Warn("domain seen with inconsistent casing; upstream sources may normalize differently",
+			"domain", v.Domain, "variants", v.Variants)
+	}
+}
+
+// casingTracker records the spellings of every counted domain for WithCasingReport. Consistently
+// cased domains only cost their first spelling.
+type casingTracker struct {
+	// first maps each case-folded domain to its first spelling.
+	first map[string]string
+	// variants maps each inconsistently cased domain to all of its spellings.
+	variants map[string][]string
+}
+
+func newCasingTracker() *casingTracker {
+	return &casingTracker{first: make(map[string]string), variants: make(map[string][]string)}
+}
+
+// add records one occurrence of domain as spelled.
+func (t *casingTracker) add(domain string) {
+	key := FoldDomain(domain)
+	first, ok := t.first[key]
+	if !ok {
+		t.first[key] = domain
+		return
+	}
+	if first == domain {
+		return
+	}
+	spellings, ok := t.variants[key]
+	if !ok {
+		t.variants[key] = []string{first, domain}
+		return
+	}
+	if !slices.Contains(spellings, domain) {
+		t.variants[key] = append(spellings, domain)
+	}
+}
+
+// report returns the inconsistently cased domains sorted by domain.
+func (t *casingTracker) report() []CasingVariants {
+	report := make([]CasingVariants, 0, len(t.variants))
+	for domain, spellings := range t.variants {
+		report = append(report, CasingVariants{Domain: domain, Variants: spellings})
+	}
+	slices.SortFunc(report, func(a, b CasingVariants) int {
+		return strings.Compare(a.Domain, b.Domain)
+	})
+	return report
+}
+
+// merge records the spellings seen by other, which tracked input following that of t.
+func (t *casingTracker) merge(other *casingTracker) {
+	for key, first := range other.first {
+		t.add(first)
+		for _, spelling := range other.variants[key] {
+			t.add(spelling)
+		}
+	}
+}
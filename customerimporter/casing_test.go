@@ -0,0 +1,45 @@
+package customerimporter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithCasingReport(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@Example.com,Male,192.168.1.1
+Jane,Doe,jane@example.com,Female,192.168.1.2
+Jim,Beam,jim@www.EXAMPLE.com,Male,192.168.1.3
+Joe,Beam,joe@Example.com,Male,192.168.1.4
+Ann,Lee,ann@other.org,Female,192.168.1.5
+Bob,Lee,bob@other.org,Male,192.168.1.6`
+
+	var got []CasingVariants
+	calls := 0
+	importer := NewCustomerImporter("", WithStripWWWPrefix(), WithCasingReport(func(variants []CasingVariants) {
+		got = variants
+		calls++
+	}))
+	data, _, err := importer.ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CasingVariants{{Domain: "example.com", Variants: []string{"Example.com", "example.com", "EXAMPLE.com"}}}
+	if calls != 1 || !reflect.DeepEqual(got, want) {
+		t.Errorf("report called %d times with %+v, want once with %+v", calls, got, want)
+	}
+	// The report does not change the aggregation, which stays case-sensitive.
+	if len(data) != 4 {
+		t.Errorf("got %d domains, want 4: %+v", len(data), data)
+	}
+
+	_, _, err = importer.ImportReader(context.Background(), strings.NewReader("a,b,email\nAnn,Lee,ann@other.org\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || got == nil || len(got) != 0 {
+		t.Errorf("consistent casing reported %+v (call %d), want an empty report", got, calls)
+	}
+}
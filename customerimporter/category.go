@@ -0,0 +1,74 @@
+package customerimporter
+
+import "strings"
+
+// Category is a coarse classification of an aggregated domain (see WithCategory).
+type Category string
+
+const (
+	// CategoryFreemail marks domains of well-known free email providers such as gmail.com.
+	CategoryFreemail Category = "freemail"
+	// CategoryCorporate marks registrable domains that are not free email providers.
+	CategoryCorporate Category = "corporate"
+	// CategoryUnknown marks domains that cannot be classified, such as bare public suffixes
+	// ("localhost", "co.uk") or numeric addresses.
+	CategoryUnknown Category = "unknown"
+)
+
+// FreemailProviders is the built-in list of free email provider domains used by WithCategory
+// unless it is overridden with WithFreemailProviders.
+var FreemailProviders = []string{
+	"gmail.com", "googlemail.com",
+	"outlook.com", "hotmail.com", "live.com", "msn.com",
+	"yahoo.com", "ymail.com", "aol.com",
+	"icloud.com", "me.com", "mac.com",
+	"proton.me", "protonmail.com", "zoho.com", "gmx.com", "gmx.de", "web.de",
+	"mail.com", "mail.ru", "yandex.ru", "qq.com", "163.com",
+}
+
+// categorizer classifies domains against a set of free email providers.
+type categorizer struct {
+	providers map[string]struct{}
+	suffixes  suffixSet
+}
+
+func newCategorizer(providers []string, suffixes suffixSet) *categorizer {
+	c := &categorizer{providers: make(map[string]struct{}, len(providers)), suffixes: suffixes}
+	for _, domain := range providers {
+		c.providers[FoldDomain(domain)] = struct{}{}
+	}
+	return c
+}
+
+// classify returns the category of domain. A domain is freemail when its registrable domain is a
+// listed provider, so "mail.gmail.com" is freemail too.
+func (c *categorizer) classify(domain string) Category {
+	domain = FoldDomain(domain)
+	if c.suffixes.isPublicSuffix(domain) {
+		return CategoryUnknown
+	}
+	tld := domain[strings.LastIndexByte(domain, '.')+1:]
+	if strings.Trim(tld, "0123456789") == "" {
+		return CategoryUnknown
+	}
+	if _, ok := c.providers[c.suffixes.registrableDomain(domain)]; ok {
+		return CategoryFreemail
+	}
+	return CategoryCorporate
+}
+
+// registrableDomain returns the longest public suffix of domain plus one label, e.g.
+// "example.co.uk" for "mail.example.co.uk". Suffixes span more than one label only when they are
+// in the set; otherwise the last label is the suffix.
+func (s suffixSet) registrableDomain(domain string) string {
+	labels := strings.Split(domain, ".")
+	for i := 1; i < len(labels)-1; i++ {
+		if s.isPublicSuffix(strings.Join(labels[i:], ".")) {
+			return strings.Join(labels[i-1:], ".")
+		}
+	}
+	if len(labels) <= 2 {
+		return domain
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
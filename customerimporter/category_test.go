@@ -0,0 +1,152 @@
+package customerimporter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   Category
+	}{
+		{domain: "gmail.com", want: CategoryFreemail},
+		{domain: "GMail.com", want: CategoryFreemail},
+		{domain: "mail.yahoo.com", want: CategoryFreemail},
+		{domain: "acme-widgets.com", want: CategoryCorporate},
+		{domain: "sales.example.co.uk", want: CategoryCorporate},
+		{domain: "gmail.co.uk", want: CategoryCorporate},
+		{domain: "localhost", want: CategoryUnknown},
+		{domain: "co.uk", want: CategoryUnknown},
+		{domain: "192.168.1.1", want: CategoryUnknown},
+	}
+
+	categories := newCategorizer(FreemailProviders, defaultSuffixes)
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := categories.classify(tt.domain); got != tt.want {
+				t.Errorf("classify(%q) = %q, want %q", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{domain: "example.com", want: "example.com"},
+		{domain: "a.b.example.com", want: "example.com"},
+		{domain: "mail.example.co.uk", want: "example.co.uk"},
+		{domain: "example.co.uk", want: "example.co.uk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := defaultSuffixes.registrableDomain(tt.domain); got != tt.want {
+				t.Errorf("registrableDomain(%q) = %q, want %q", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCategory(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@gmail.com,Male,192.168.1.1
+Jane,Roe,jane@acme-widgets.com,Female,192.168.1.2
+Max,Poe,max@example.org,Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]Category
+	}{
+		{
+			name: "disabled",
+			want: map[string]Category{"acme-widgets.com": "", "example.org": "", "gmail.com": ""},
+		},
+		{
+			name: "built-in providers",
+			opts: []Option{WithCategory()},
+			want: map[string]Category{
+				"acme-widgets.com": CategoryCorporate,
+				"example.org":      CategoryCorporate,
+				"gmail.com":        CategoryFreemail,
+			},
+		},
+		{
+			name: "overridden providers",
+			opts: []Option{WithCategory(), WithFreemailProviders([]string{"Example.org"})},
+			want: map[string]Category{
+				"acme-widgets.com": CategoryCorporate,
+				"example.org":      CategoryFreemail,
+				"gmail.com":        CategoryCorporate,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewCustomerImporter(csvPath, tt.opts...).ImportDomainData()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range data {
+				if d.Category != tt.want[d.Domain] {
+					t.Errorf("%s category = %q, want %q", d.Domain, d.Category, tt.want[d.Domain])
+				}
+			}
+		})
+	}
+}
+
+func TestWithPublicSuffixes(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@cs.ox.ac.uk,Male,192.168.1.1`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantGroup    string
+		wantCategory Category
+	}{
+		{name: "built-in", wantGroup: "ox.ac.uk", wantCategory: CategoryFreemail},
+		{
+			name:         "added suffix",
+			opts:         []Option{WithPublicSuffixes(append(slices.Clone(MultiLevelSuffixes), "ox.ac.uk"))},
+			wantGroup:    "cs.ox.ac.uk",
+			wantCategory: CategoryCorporate,
+		},
+		{
+			name:         "disabled suffix",
+			opts:         []Option{WithPublicSuffixes([]string{"co.uk"})},
+			wantGroup:    "ac.uk",
+			wantCategory: CategoryCorporate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := NewCustomerImporter(csvPath, append(tt.opts, WithCategory(), WithFreemailProviders([]string{"ox.ac.uk"}))...)
+			if got := ci.config.publicSuffixes().registrableDomain("cs.ox.ac.uk"); got != tt.wantGroup {
+				t.Errorf("registrableDomain() = %q, want %q", got, tt.wantGroup)
+			}
+			data, err := ci.ImportDomainData()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != 1 || data[0].Category != tt.wantCategory {
+				t.Errorf("data = %+v, want category %q", data, tt.wantCategory)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// aggregateConcurrent is aggregateFiles under WithFileConcurrency: up to FileConcurrency
+// workers each read one file at a time into a run of its own, and the runs are merged in path
+// order once all files are read, so the result matches reading them in order. The first failing
+// file cancels the files still being read.
+func (ci CustomerImporter) aggregateConcurrent(ctx context.Context, paths []string) (*importRun, error) {
+	switch c := ci.config; {
+	case c.Deduplicate:
+		return nil, errors.New("file concurrency cannot be combined with deduplication")
+	case c.SampleEvery > 1:
+		return nil, errors.New("file concurrency cannot be combined with sampling")
+	case c.RejectsOutput != "":
+		return nil, errors.New("file concurrency cannot be combined with a rejects output")
+	case c.SpillThreshold > 0 || c.SketchTopK != 0:
+		return nil, errors.New("file concurrency cannot be combined with external or count-min sketch aggregation")
+	}
+	ctx, cancel := ci.withMaxDuration(ctx)
+	defer cancel()
+	workCtx, cancelWork := context.WithCancel(ctx)
+	defer cancelWork()
+
+	runs := make([]*importRun, len(paths))
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(ci.config.FileConcurrency, len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				run, err := ci.aggregateFileRun(workCtx, paths[i])
+				runs[i] = run
+				if err != nil && !isPartial(err) {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancelWork()
+				}
+			}
+		}()
+	}
+feed:
+	for i := range paths {
+		select {
+		case indexes <- i:
+		case <-workCtx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	var merged *importRun
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		if merged == nil {
+			merged = run
+			continue
+		}
+		merged.merge(run)
+	}
+	if merged == nil {
+		// Only a cancellation stops every worker before its first file
+		return nil, fmt.Errorf("%w after 0 rows: %w", ErrPartialResult, contextError(ctx))
+	}
+	if ctx.Err() != nil {
+		return merged, fmt.Errorf("%w after %d rows: %w", ErrPartialResult, merged.summary.Rows, contextError(ctx))
+	}
+	return merged, merged.finish()
+}
+
+// aggregateFileRun reads the file at path into a new run, without the final checks.
+func (ci CustomerImporter) aggregateFileRun(ctx context.Context, path string) (*importRun, error) {
+	var run *importRun
+	err := readFile(path, func(r io.Reader) error {
+		records, header, err := ci.openRecords(r)
+		if err != nil {
+			return err
+		}
+		if run, err = ci.newImportRun(header); err != nil {
+			return err
+		}
+		return run.consume(ctx, records)
+	})
+	return run, err
+}
+
+// merge adds the counts and statistics of other, which read input following that of r, to r.
+func (r *importRun) merge(other *importRun) {
+	other.data.each(func(domain string, count uint64) {
+		r.data.add(domain, count)
+	})
+	r.summary.add(other.summary)
+	for key, count := range other.months {
+		r.months[key] += count
+	}
+	for prefix, count := range other.subnetCounts {
+		r.subnetCounts[prefix] += count
+	}
+	for domain, count := range other.roles {
+		r.roles[domain] += count
+	}
+	for domain, email := range other.samples {
+		if _, ok := r.samples[domain]; !ok || r.ci.config.PreferLastSeen {
+			r.samples[domain] = email
+		}
+	}
+	if r.casings != nil {
+		r.casings.merge(other.casings)
+	}
+}
@@ -0,0 +1,94 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportFilesConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	domains := []string{"example.com", "Example.com", "other.org", "gmail.com", "acme.io"}
+	var paths []string
+	for i := 0; i < 8; i++ {
+		var b strings.Builder
+		b.WriteString("first_name,last_name,email\n")
+		for row := 0; row <= i*25; row++ {
+			domain := domains[(i+row)%len(domains)]
+			fmt.Fprintf(&b, "F%d,L%d,user%d.%d@%s\n", i, row, i, row, domain)
+		}
+		if i == 5 {
+			b.WriteString("Bad,Row,not-an-email\n")
+		}
+		path := filepath.Join(dir, fmt.Sprintf("part-%d.csv", i))
+		if err := writeTestCSV(path, b.String()); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	importFiles := func(opts ...Option) ([]DomainData, Summary, []CasingVariants) {
+		t.Helper()
+		var casings []CasingVariants
+		opts = append(opts, WithSampleEmail(), WithRoleAccounts(), WithErrorPolicy(ErrorPolicySkip),
+			WithCasingReport(func(variants []CasingVariants) { casings = variants }))
+		data, summary, err := NewCustomerImporter("", opts...).ImportFiles(context.Background(), paths...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data, summary, casings
+	}
+	for _, opts := range [][]Option{nil, {WithPreferLastSeen()}} {
+		want, wantSummary, wantCasings := importFiles(opts...)
+		got, summary, casings := importFiles(append(opts, WithFileConcurrency(3))...)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("concurrent import = %v, want %v", got, want)
+		}
+		if summary != wantSummary {
+			t.Errorf("concurrent summary = %+v, want %+v", summary, wantSummary)
+		}
+		if !reflect.DeepEqual(casings, wantCasings) {
+			t.Errorf("concurrent casing report = %v, want %v", casings, wantCasings)
+		}
+		if wantSummary.SkippedRows != 1 || len(wantCasings) != 1 {
+			t.Errorf("sequential import skipped %d rows and reported %v, want 1 row and example.com", wantSummary.SkippedRows, wantCasings)
+		}
+	}
+}
+
+func TestImportFilesConcurrentErrors(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 4; i++ {
+		content := "first_name,last_name,email\nJohn,Doe,john@example.com\n"
+		if i == 2 {
+			content += "Jane,Doe,not-an-email\n"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("part-%d.csv", i))
+		if err := writeTestCSV(path, content); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	_, _, err := NewCustomerImporter("", WithFileConcurrency(2)).ImportFiles(context.Background(), paths...)
+	if err == nil || !strings.HasPrefix(err.Error(), paths[2]+": ") || isPartial(err) {
+		t.Errorf("ImportFiles() error = %v, want the error of %s", err, paths[2])
+	}
+
+	_, _, err = NewCustomerImporter("", WithFileConcurrency(2), WithDeduplication()).ImportFiles(context.Background(), paths...)
+	if err == nil || !strings.Contains(err.Error(), "deduplication") {
+		t.Errorf("ImportFiles() with deduplication = %v, want an error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = NewCustomerImporter("", WithFileConcurrency(2)).ImportFiles(ctx, paths...)
+	if !errors.Is(err, ErrPartialResult) || !errors.Is(err, context.Canceled) {
+		t.Errorf("ImportFiles() with a canceled context = %v, want a partial result", err)
+	}
+}
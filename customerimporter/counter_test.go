@@ -0,0 +1,112 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestTrieCounter(t *testing.T) {
+	trie := &trieCounter{}
+	for _, domain := range []string{"example.com", "mail.example.com", "com", "example.com", "example.org", "foo..com"} {
+		trie.add(domain, 2)
+	}
+
+	want := map[string]uint64{"example.com": 4, "mail.example.com": 2, "com": 2, "example.org": 2, "foo..com": 2}
+	if trie.len() != len(want) {
+		t.Errorf("len() = %d, want %d", trie.len(), len(want))
+	}
+	for domain, count := range want {
+		if got := trie.count(domain); got != count {
+			t.Errorf("count(%q) = %d, want %d", domain, got, count)
+		}
+	}
+	for _, domain := range []string{"org", "example", "www.example.com", "a.mail.example.com"} {
+		if got := trie.count(domain); got != 0 {
+			t.Errorf("count(%q) = %d for a domain never added, want 0", domain, got)
+		}
+	}
+
+	got := make(map[string]uint64)
+	trie.each(func(domain string, count uint64) {
+		got[domain] = count
+	})
+	if len(got) != len(want) {
+		t.Errorf("each() visited %v, want %v", got, want)
+	}
+	for domain, count := range want {
+		if got[domain] != count {
+			t.Errorf("each() reported %q = %d, want %d", domain, got[domain], count)
+		}
+	}
+}
+
+func TestCounterBackendsIdentical(t *testing.T) {
+	for _, path := range []string{"./test_data.csv", "./benchmark10k.csv"} {
+		t.Run(path, func(t *testing.T) {
+			want, wantSummary, err := NewCustomerImporter(path).ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, summary, err := NewCustomerImporter(path, withTrieCounter()).ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary != wantSummary {
+				t.Errorf("trie summary = %+v, want %+v", summary, wantSummary)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("trie aggregation differs from the map: got %d domains, want %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+// benchmarkDomains returns n domains drawn from the given number of distinct ones, spread over a
+// few shared parents and TLDs like real customer lists.
+func benchmarkDomains(n, distinct int) []string {
+	tlds := []string{"com", "org", "net", "co.uk", "de"}
+	domains := make([]string, n)
+	for i := range domains {
+		k := i % distinct
+		domains[i] = fmt.Sprintf("host%d.company%d.%s", k%7, k, tlds[k%len(tlds)])
+	}
+	return domains
+}
+
+func BenchmarkCounter(b *testing.B) {
+	const rows = 100000
+	datasets := []struct {
+		name     string
+		distinct int
+	}{
+		{name: "high-cardinality", distinct: rows},
+		{name: "low-cardinality", distinct: 20},
+	}
+	backends := []struct {
+		name string
+		new  func() counter
+	}{
+		{name: "map", new: func() counter { return make(mapCounter) }},
+		{name: "trie", new: func() counter { return &trieCounter{} }},
+	}
+
+	for _, ds := range datasets {
+		domains := benchmarkDomains(rows, ds.distinct)
+		for _, backend := range backends {
+			b.Run(ds.name+"/"+backend.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					c := backend.new()
+					for _, domain := range domains {
+						c.add(domain, 1)
+					}
+					if c.len() != ds.distinct {
+						b.Fatalf("counted %d domains, want %d", c.len(), ds.distinct)
+					}
+				}
+			})
+		}
+	}
+}
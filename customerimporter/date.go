@@ -0,0 +1,133 @@
+package customerimporter
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DatePolicy selects how rows with a blank or unparseable date are handled under
+// WithDateColumn.
+type DatePolicy string
+
+const (
+	// DatePolicyError treats an invalid date as a row error, subject to the ErrorPolicy.
+	// It is the default.
+	DatePolicyError DatePolicy = "error"
+	// DatePolicySkip leaves rows with an invalid date out of the aggregation.
+	DatePolicySkip DatePolicy = "skip"
+	// DatePolicyUnknown counts rows with an invalid date under MonthUnknown.
+	DatePolicyUnknown DatePolicy = "unknown"
+)
+
+// MonthUnknown is the month of rows counted with an invalid date under DatePolicyUnknown.
+const MonthUnknown = "unknown"
+
+// MonthlyData is the number of customers of a domain within one calendar month.
+type MonthlyData struct {
+	// Domain is the email domain (e.g., "example.com").
+	Domain string
+	// Month is the "yyyy-mm" month of the date column, or MonthUnknown.
+	Month string
+	// CustomerQuantity is the number of customers of Domain dated within Month.
+	CustomerQuantity uint64
+}
+
+// monthKey identifies a domain -> month bucket of the monthly aggregation.
+type monthKey struct {
+	domain string
+	month  string
+}
+
+// errInvalidDate is wrapped by the row error of an invalid date under DatePolicyError.
+var errInvalidDate = errors.New("invalid date")
+
+// rowMonth returns the "yyyy-mm" month of the row's date column. ok is false when the row must
+// be left out under DatePolicySkip. Both skipped and unknown dates are counted in
+// Summary.InvalidDates.
+func (r *importRun) rowMonth(line []string) (month string, ok bool, err error) {
+	value := line[*r.ci.config.DateColumn]
+	date, err := time.Parse(r.ci.config.DateLayout, strings.TrimSpace(value))
+	if err == nil {
+		return date.Format("2006-01"), true, nil
+	}
+	switch r.ci.config.DatePolicy {
+	case DatePolicySkip:
+		r.summary.InvalidDates++
+		return "", false, nil
+	case DatePolicyUnknown:
+		r.summary.InvalidDates++
+		return MonthUnknown, true, nil
+	}
+	return "", false, fmt.Errorf("%w %q: want layout %q", errInvalidDate, value, r.ci.config.DateLayout)
+}
+
+// monthly returns the domain -> month aggregation sorted by domain, then month.
+func (r *importRun) monthly() []MonthlyData {
+	data := make([]MonthlyData, 0, len(r.months))
+	for key, count := range r.months {
+		data = append(data, MonthlyData{Domain: key.domain, Month: key.month, CustomerQuantity: count})
+	}
+	slices.SortFunc(data, func(l, r MonthlyData) int {
+		if c := cmp.Compare(l.Domain, r.Domain); c != 0 {
+			return c
+		}
+		return cmp.Compare(l.Month, r.Month)
+	})
+	return data
+}
+
+// ImportMonthly is like ImportWithSummary but returns the customers of each domain broken down
+// by the calendar month of the WithDateColumn column, sorted by domain, then month. Every other
+// option applies as usual, so the counts of a domain sum to its CustomerQuantity. It fails when
+// no date column is configured.
+func (ci CustomerImporter) ImportMonthly(ctx context.Context) ([]MonthlyData, Summary, error) {
+	if ci.config.DateColumn == nil {
+		return nil, Summary{}, fmt.Errorf("monthly import requires a date column")
+	}
+	file, err := os.Open(ci.path)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ci.monthlyWith(func() (*importRun, error) {
+		return ci.aggregateFile(ctx, file)
+	})
+}
+
+// importMonthly is the reader-based core of ImportMonthly.
+func (ci CustomerImporter) importMonthly(ctx context.Context, r io.Reader) ([]MonthlyData, Summary, error) {
+	return ci.monthlyWith(func() (*importRun, error) {
+		return ci.aggregate(ctx, r)
+	})
+}
+
+// monthlyWith turns the run returned by aggregate into the monthly breakdown, recording metrics.
+func (ci CustomerImporter) monthlyWith(aggregate func() (*importRun, error)) (data []MonthlyData, summary Summary, err error) {
+	start := time.Now()
+	defer func() {
+		ci.recordMetrics(start, summary, err)
+	}()
+
+	run, err := aggregate()
+	if run == nil {
+		return nil, Summary{}, err
+	}
+	defer run.release()
+	if err != nil && !isPartial(err) {
+		return nil, run.summary, err
+	}
+	run.summary.finish(run.data)
+	if err == nil {
+		logComplete(run.summary)
+	}
+	return run.monthly(), run.summary, err
+}
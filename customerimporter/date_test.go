@@ -0,0 +1,106 @@
+package customerimporter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportMonthly(t *testing.T) {
+	const header = "first_name,last_name,email,signup_date\n"
+	valid := "John,Doe,john@example.com,2024-01-15\n" +
+		"Jane,Doe,jane@example.com,2024-01-31\n" +
+		"Max,Roe,max@example.com,2024-02-01\n" +
+		"Ann,Poe,ann@other.com,2024-02-29\n"
+	invalid := "Bob,Poe,bob@other.com,\n" +
+		"Eve,Poe,eve@example.com,29/02/2024\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		opts        []Option
+		want        []MonthlyData
+		wantInvalid uint64
+		errorMsg    string
+	}{
+		{
+			name:    "two months",
+			content: valid,
+			want: []MonthlyData{
+				{Domain: "example.com", Month: "2024-01", CustomerQuantity: 2},
+				{Domain: "example.com", Month: "2024-02", CustomerQuantity: 1},
+				{Domain: "other.com", Month: "2024-02", CustomerQuantity: 1},
+			},
+		},
+		{
+			name:     "invalid date is a row error",
+			content:  valid + invalid,
+			errorMsg: `invalid date ""`,
+		},
+		{
+			name:    "invalid date skipped",
+			content: valid + invalid,
+			opts:    []Option{WithDatePolicy(DatePolicySkip)},
+			want: []MonthlyData{
+				{Domain: "example.com", Month: "2024-01", CustomerQuantity: 2},
+				{Domain: "example.com", Month: "2024-02", CustomerQuantity: 1},
+				{Domain: "other.com", Month: "2024-02", CustomerQuantity: 1},
+			},
+			wantInvalid: 2,
+		},
+		{
+			name:    "invalid date counted as unknown",
+			content: valid + invalid,
+			opts:    []Option{WithDatePolicy(DatePolicyUnknown)},
+			want: []MonthlyData{
+				{Domain: "example.com", Month: "2024-01", CustomerQuantity: 2},
+				{Domain: "example.com", Month: "2024-02", CustomerQuantity: 1},
+				{Domain: "example.com", Month: MonthUnknown, CustomerQuantity: 1},
+				{Domain: "other.com", Month: "2024-02", CustomerQuantity: 1},
+				{Domain: "other.com", Month: MonthUnknown, CustomerQuantity: 1},
+			},
+			wantInvalid: 2,
+		},
+		{
+			name:    "weights and custom layout",
+			content: "first_name,last_name,email,signup_date,count\nJohn,Doe,john@example.com,01/03/2024,4\n",
+			opts:    []Option{WithDateColumn(3, "02/01/2006"), WithWeightColumn(4)},
+			want:    []MonthlyData{{Domain: "example.com", Month: "2024-03", CustomerQuantity: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithDateColumn(3, "2006-01-02")}, tt.opts...)
+			ci := NewCustomerImporter("", opts...)
+			content := header + tt.content
+			if strings.HasPrefix(tt.content, "first_name") {
+				content = tt.content
+			}
+
+			got, summary, err := ci.importMonthly(context.Background(), strings.NewReader(content))
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("importMonthly() = %+v, want %+v", got, tt.want)
+			}
+			if summary.InvalidDates != tt.wantInvalid {
+				t.Errorf("InvalidDates = %d, want %d", summary.InvalidDates, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestImportMonthlyWithoutDateColumn(t *testing.T) {
+	if _, _, err := NewCustomerImporter("./test_data.csv").ImportMonthly(context.Background()); err == nil {
+		t.Error("monthly import without date column not rejected")
+	}
+}
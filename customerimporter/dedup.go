@@ -0,0 +1,40 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// dedupKey returns the identity of a validated email for deduplication: the row's customer ID
+// when a CustomerIDColumn is set, otherwise the lowercased local part, with dots removed for
+// dot-insensitive providers, joined with the case-folded domain.
+func (r *importRun) dedupKey(id, email, domain string) string {
+	domain = FoldDomain(domain)
+	if r.ci.config.CustomerIDColumn != nil {
+		return id + "@" + domain
+	}
+	local, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	local = strings.ToLower(strings.TrimSpace(local))
+	if _, ok := r.dotlessDomain[domain]; ok {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// errEmptyCustomerID is wrapped by the row error of a blank CustomerIDColumn value.
+var errEmptyCustomerID = errors.New("empty customer ID")
+
+// rowCustomerID returns the trimmed customer ID of line for deduplication, or "" when no
+// CustomerIDColumn is set. A blank ID is a row error.
+func (r *importRun) rowCustomerID(line []string) (string, error) {
+	column := r.ci.config.CustomerIDColumn
+	if column == nil || r.seen == nil {
+		return "", nil
+	}
+	id := strings.TrimSpace(line[*column])
+	if id == "" {
+		return "", fmt.Errorf("%w in column %d", errEmptyCustomerID, *column)
+	}
+	return id, nil
+}
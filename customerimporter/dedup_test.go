@@ -0,0 +1,148 @@
+package customerimporter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestImportDeduplication(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john.doe@gmail.com,Male,192.168.1.1
+John,Doe,johndoe@gmail.com,Male,192.168.1.1
+John,Doe,J.O.H.N.DOE@gmail.com,Male,192.168.1.1
+Jane,Doe,jane@example.com,Female,192.168.1.2
+Jane,Doe,JANE@example.com,Female,192.168.1.2
+Jim,Beam,jim.beam@example.com,Male,192.168.1.3
+Jim,Beam,jimbeam@example.com,Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		opts           []Option
+		want           []DomainData
+		wantDuplicates uint64
+	}{
+		{
+			name: "no deduplication",
+			want: []DomainData{
+				{Domain: "example.com", CustomerQuantity: 4},
+				{Domain: "gmail.com", CustomerQuantity: 3},
+			},
+		},
+		{
+			name: "deduplication without dot folding",
+			opts: []Option{WithDeduplication()},
+			want: []DomainData{
+				{Domain: "example.com", CustomerQuantity: 3},
+				{Domain: "gmail.com", CustomerQuantity: 3},
+			},
+			wantDuplicates: 1,
+		},
+		{
+			name: "gmail dots collapse to one customer",
+			opts: []Option{WithDeduplication(), WithDotInsensitiveDomains(GmailDomains)},
+			want: []DomainData{
+				{Domain: "example.com", CustomerQuantity: 3},
+				{Domain: "gmail.com", CustomerQuantity: 1},
+			},
+			wantDuplicates: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, summary, err := NewCustomerImporter(csvPath, tt.opts...).ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != len(tt.want) {
+				t.Fatalf("got %d domains, want %d: %v", len(data), len(tt.want), data)
+			}
+			for i := range tt.want {
+				if data[i] != tt.want[i] {
+					t.Errorf("data[%d] = %+v, want %+v", i, data[i], tt.want[i])
+				}
+			}
+			if summary.Duplicates != tt.wantDuplicates {
+				t.Errorf("summary.Duplicates = %d, want %d", summary.Duplicates, tt.wantDuplicates)
+			}
+		})
+	}
+}
+
+func TestImportCustomerIDDeduplication(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `customer_id,name,email
+c1,John Doe,john@example.com
+c1,John Doe,john.doe@example.com
+c1,John Doe,JOHN@gmail.com
+c2,Jane Doe,jane@example.com
+c1,John Doe,john@example.com`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		opts           []Option
+		want           []DomainData
+		wantDuplicates uint64
+	}{
+		{
+			name: "keyed on email",
+			opts: []Option{WithDeduplication()},
+			want: []DomainData{
+				{Domain: "example.com", CustomerQuantity: 3},
+				{Domain: "gmail.com", CustomerQuantity: 1},
+			},
+			wantDuplicates: 1,
+		},
+		{
+			name: "keyed on customer ID",
+			opts: []Option{WithDeduplication(), WithCustomerIDColumn(0)},
+			want: []DomainData{
+				{Domain: "example.com", CustomerQuantity: 2},
+				{Domain: "gmail.com", CustomerQuantity: 1},
+			},
+			wantDuplicates: 2,
+		},
+		{
+			name: "ID column ignored without deduplication",
+			opts: []Option{WithCustomerIDColumn(0)},
+			want: []DomainData{
+				{Domain: "example.com", CustomerQuantity: 4},
+				{Domain: "gmail.com", CustomerQuantity: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, summary, err := NewCustomerImporter(csvPath, tt.opts...).ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != len(tt.want) || data[0] != tt.want[0] || data[1] != tt.want[1] {
+				t.Errorf("got %+v, want %+v", data, tt.want)
+			}
+			if summary.Duplicates != tt.wantDuplicates {
+				t.Errorf("Duplicates = %d, want %d", summary.Duplicates, tt.wantDuplicates)
+			}
+		})
+	}
+}
+
+func TestImportCustomerIDBlank(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	if err := writeTestCSV(csvPath, "customer_id,name,email\n ,John Doe,john@example.com\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	_, err := NewCustomerImporter(csvPath, WithDeduplication(), WithCustomerIDColumn(0)).ImportDomainData()
+	if err == nil {
+		t.Error("expected error for blank customer ID, got nil")
+	}
+}
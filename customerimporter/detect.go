@@ -0,0 +1,80 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// sniffSize is how many leading bytes WithDelimiterDetection inspects.
+const sniffSize = 4096
+
+// delimiterCandidates are the separators WithDelimiterDetection chooses from, in order of
+// preference when counts tie.
+var delimiterCandidates = []byte{',', ';', '\t'}
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// detectDelimiter sniffs the start of r for a byte order mark and the header delimiter. It
+// returns a reader yielding the input without a UTF-8 BOM and the detected delimiter, falling
+// back to a comma with a warning when the header is ambiguous. UTF-16 input is rejected.
+func detectDelimiter(r io.Reader) (io.Reader, rune, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	sample, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		slog.Info("detected encoding", "encoding", "utf-8", "bom", true)
+		if _, err := br.Discard(len(utf8BOM)); err != nil {
+			return nil, 0, err
+		}
+		sample = sample[len(utf8BOM):]
+	case bytes.HasPrefix(sample, utf16LEBOM), bytes.HasPrefix(sample, utf16BEBOM):
+		return nil, 0, fmt.Errorf("unsupported UTF-16 input (byte order mark %x): convert it to UTF-8", sample[:2])
+	}
+
+	header, _, _ := bytes.Cut(sample, []byte("\n"))
+	delimiter, ok := sniffDelimiter(header)
+	if !ok {
+		slog.Warn("could not detect delimiter, falling back to comma", "header", string(header))
+		return br, ',', nil
+	}
+	slog.Info("detected delimiter", "delimiter", string(delimiter))
+	return br, rune(delimiter), nil
+}
+
+// sniffDelimiter picks the candidate occurring most often in header outside quoted fields. It
+// is not confident (ok is false) when no candidate occurs or the top two counts tie.
+func sniffDelimiter(header []byte) (delimiter byte, ok bool) {
+	counts := make(map[byte]int, len(delimiterCandidates))
+	quoted := false
+	for _, b := range header {
+		if b == '"' {
+			quoted = !quoted
+			continue
+		}
+		if !quoted {
+			counts[b]++
+		}
+	}
+
+	best, runnerUp := 0, 0
+	for _, candidate := range delimiterCandidates {
+		switch n := counts[candidate]; {
+		case n > best:
+			delimiter, best, runnerUp = candidate, n, best
+		case n > runnerUp:
+			runnerUp = n
+		}
+	}
+	return delimiter, best > 0 && best > runnerUp
+}
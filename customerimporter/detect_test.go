@@ -0,0 +1,101 @@
+package customerimporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   byte
+		ok     bool
+	}{
+		{name: "comma", header: "first_name,last_name,email", want: ',', ok: true},
+		{name: "semicolon", header: "first_name;last_name;email", want: ';', ok: true},
+		{name: "tab", header: "first_name\tlast_name\temail", want: '\t', ok: true},
+		{name: "quoted commas ignored", header: `"last, first";email;gender`, want: ';', ok: true},
+		{name: "majority wins", header: "a;b;c,d", want: ';', ok: true},
+		{name: "tie", header: "a;b,c", ok: false},
+		{name: "single column", header: "email", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := sniffDelimiter([]byte(tt.header))
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("sniffDelimiter(%q) = %q, %t, want %q, %t", tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestImportDelimiterDetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     map[string]uint64
+		errorMsg string
+	}{
+		{
+			name: "tsv",
+			content: "first_name\tlast_name\temail\tgender\n" +
+				"John\tDoe\tjohn@example.com\tMale\n" +
+				"Jane\tDoe, Jr.\tjane@example.com\tFemale\n",
+			want: map[string]uint64{"example.com": 2},
+		},
+		{
+			name: "semicolon",
+			content: "first_name;last_name;email;gender\n" +
+				"John;Doe;john@example.com;Male\n" +
+				"Max;Roe;max@other.com;Male\n",
+			want: map[string]uint64{"example.com": 1, "other.com": 1},
+		},
+		{
+			name:    "utf-8 bom",
+			content: "\xEF\xBB\xBFfirst_name;last_name;email\nJohn;Doe;john@example.com\n",
+			want:    map[string]uint64{"example.com": 1},
+		},
+		{
+			name:    "comma",
+			content: "first_name,last_name,email\nJohn,Doe,john@example.com\n",
+			want:    map[string]uint64{"example.com": 1},
+		},
+		{
+			name:    "ambiguous falls back to comma",
+			content: "first;name,last;name,email\nJohn,Doe,john@example.com\n",
+			want:    map[string]uint64{"example.com": 1},
+		},
+		{
+			name:     "utf-16",
+			content:  "\xFF\xFEf\x00i\x00",
+			errorMsg: "unsupported UTF-16 input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := NewCustomerImporter("", WithDelimiterDetection(), WithHeaderValidation())
+			data, _, err := ci.ImportReader(context.Background(), strings.NewReader(tt.content))
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != len(tt.want) {
+				t.Fatalf("got %+v, want %v", data, tt.want)
+			}
+			for _, d := range data {
+				if d.CustomerQuantity != tt.want[d.Domain] {
+					t.Errorf("%s = %d, want %d", d.Domain, d.CustomerQuantity, tt.want[d.Domain])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrMaxDurationExceeded is wrapped, along with ErrPartialResult, by the error of an import
+// stopped by WithMaxDuration.
+var ErrMaxDurationExceeded = errors.New("maximum import duration exceeded")
+
+// withMaxDuration returns ctx bounded by the configured MaxDuration, expiring with
+// ErrMaxDurationExceeded as its cause.
+func (ci CustomerImporter) withMaxDuration(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ci.config.MaxDuration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, ci.config.MaxDuration, ErrMaxDurationExceeded)
+}
+
+// contextError returns the error of the done ctx, wrapping its cause as well when one was set.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// endlessCSV generates a header followed by an unbounded stream of valid rows.
+type endlessCSV struct {
+	buf  []byte
+	rows int
+}
+
+func (e *endlessCSV) Read(p []byte) (int, error) {
+	if e.buf == nil {
+		e.buf = []byte("first_name,last_name,email\n")
+	}
+	for len(e.buf) < len(p) {
+		e.buf = fmt.Appendf(e.buf, "John,Doe,john%d@example%d.com\n", e.rows, e.rows%10)
+		e.rows++
+	}
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+	return n, nil
+}
+
+func TestImportMaxDuration(t *testing.T) {
+	ci := NewCustomerImporter("", WithMaxDuration(20*time.Millisecond))
+	done := make(chan struct{})
+	var (
+		data    []DomainData
+		summary Summary
+		err     error
+	)
+	go func() {
+		defer close(done)
+		data, summary, err = ci.ImportReader(context.Background(), &endlessCSV{})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("import of an endless stream did not stop at the maximum duration")
+	}
+
+	if !errors.Is(err, ErrPartialResult) || !errors.Is(err, ErrMaxDurationExceeded) {
+		t.Fatalf("expected ErrPartialResult and ErrMaxDurationExceeded, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	var total uint64
+	for _, d := range data {
+		total += d.CustomerQuantity
+	}
+	if total == 0 || total != summary.Rows {
+		t.Errorf("partial result counted %d customers over %d rows, want a non-empty result counting every row", total, summary.Rows)
+	}
+}
+
+func TestImportMaxDurationNotReached(t *testing.T) {
+	ci := NewCustomerImporter("./test_data.csv", WithMaxDuration(time.Minute))
+	if _, _, err := ci.ImportWithSummary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
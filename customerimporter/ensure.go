@@ -0,0 +1,50 @@
+package customerimporter
+
+import (
+	"slices"
+)
+
+// ensuredDomains returns the sorted, de-duplicated EnsureDomains, plus every group name and
+// UngroupedDomains with DomainGroups.
+func (ci CustomerImporter) ensuredDomains() []string {
+	if len(ci.config.EnsureDomains) == 0 && ci.config.DomainGroups == nil {
+		return nil
+	}
+	domains := slices.Clone(ci.config.EnsureDomains)
+	if ci.config.DomainGroups != nil {
+		for name := range ci.config.DomainGroups {
+			domains = append(domains, name)
+		}
+		domains = append(domains, UngroupedDomains)
+	}
+	slices.Sort(domains)
+	return slices.Compact(domains)
+}
+
+// ensuredMerger interleaves the zero-count EnsureDomains missing from a sorted stream of
+// aggregated domains, for the external aggregation path.
+type ensuredMerger struct {
+	domains []string
+	next    int
+}
+
+// before returns the pending ensured domains sorting before domain and skips domain itself
+// if it is ensured, since it is present in the data.
+func (m *ensuredMerger) before(domain string) []string {
+	start := m.next
+	for m.next < len(m.domains) && m.domains[m.next] < domain {
+		m.next++
+	}
+	missing := m.domains[start:m.next]
+	if m.next < len(m.domains) && m.domains[m.next] == domain {
+		m.next++
+	}
+	return missing
+}
+
+// rest returns the ensured domains sorting after the last aggregated domain.
+func (m *ensuredMerger) rest() []string {
+	missing := m.domains[m.next:]
+	m.next = len(m.domains)
+	return missing
+}
@@ -0,0 +1,75 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestImportEnsureDomains(t *testing.T) {
+	content := `first_name,last_name,email
+John,Doe,john@example.com
+Jane,Doe,jane@example.com
+Joe,Doe,joe@other.com
+Jim,Doe,jim@zeta.com`
+	ensure := WithEnsureDomains([]string{"missing.com", "example.com", "aaa.com", "zzz.com", "missing.com"})
+	want := fmt.Sprint([]DomainData{
+		{Domain: "aaa.com"},
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "missing.com"},
+		{Domain: "other.com", CustomerQuantity: 1},
+		{Domain: "zeta.com", CustomerQuantity: 1},
+		{Domain: "zzz.com"},
+	})
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "map", opts: []Option{ensure}},
+		{name: "trie", opts: []Option{ensure, withTrieCounter()}},
+		{name: "external", opts: []Option{ensure, WithExternalAggregation(1, t.TempDir())}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, summary, err := NewCustomerImporter("", tt.opts...).
+				ImportReader(context.Background(), strings.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(data); got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+			if summary.Domains != 3 || summary.Customers != 4 {
+				t.Errorf("summary counted %d domains and %d customers, want 3 and 4", summary.Domains, summary.Customers)
+			}
+		})
+	}
+}
+
+func TestImportEnsureDomainsSketch(t *testing.T) {
+	// A sketch of 3 counters in 1 row: with ten domains every counter is taken, so the absent
+	// ensured domain has a non-zero estimate without being among the tracked domains
+	var content strings.Builder
+	content.WriteString("first_name,last_name,email\n")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&content, "John,Doe,john@d%d.com\n", i)
+	}
+	ci := NewCustomerImporter("", WithCountMinSketch(0.99, 0.5, 2), WithEnsureDomains([]string{"missing.com"}))
+	data, _, err := ci.ImportReader(context.Background(), strings.NewReader(content.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 {
+		t.Fatalf("got %v, want the 2 tracked domains and missing.com", data)
+	}
+	found := false
+	for _, d := range data {
+		found = found || d.Domain == "missing.com"
+	}
+	if !found {
+		t.Errorf("got %v, want missing.com listed", data)
+	}
+}
@@ -0,0 +1,31 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrErrorRateExceeded is returned when the share of rows skipped as invalid exceeds
+// MaxErrorRate.
+var ErrErrorRateExceeded = errors.New("error rate exceeded")
+
+// errorRateInterval is how many rows are read between two MaxErrorRate checks.
+const errorRateInterval = 100
+
+// checkErrorRate fails the import when the rows skipped so far exceed MaxErrorRate of the rows
+// processed, i.e. read and not left out by WithSampling. It only checks every errorRateInterval
+// rows, plus once more at the end of the input when final is set, so a handful of bad rows at
+// the very start does not abort a large file.
+func (r *importRun) checkErrorRate(final bool) error {
+	limit := r.ci.config.MaxErrorRate
+	if limit <= 0 || r.summary.Rows == 0 || !final && r.summary.Rows%errorRateInterval != 0 {
+		return nil
+	}
+	processed := r.summary.Rows - r.summary.UnsampledRows
+	rate := float64(r.summary.SkippedRows) / float64(processed)
+	if rate <= limit {
+		return nil
+	}
+	return fmt.Errorf("%w: %d of %d rows invalid (%.1f%%, limit %.1f%%)",
+		ErrErrorRateExceeded, r.summary.SkippedRows, processed, rate*100, limit*100)
+}
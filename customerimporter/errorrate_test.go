@@ -0,0 +1,57 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// errorRateContent returns a CSV with rows data rows, every invalidEvery-th of them invalid.
+func errorRateContent(rows, invalidEvery int) string {
+	var sb strings.Builder
+	sb.WriteString("first_name,last_name,email\n")
+	for i := 1; i <= rows; i++ {
+		email := fmt.Sprintf("user%d@example.com", i)
+		if invalidEvery > 0 && i%invalidEvery == 0 {
+			email = "not-an-email"
+		}
+		fmt.Fprintf(&sb, "User,%d,%s\n", i, email)
+	}
+	return sb.String()
+}
+
+func TestImportMaxErrorRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		rate     float64
+		wantErr  bool
+		wantRows uint64
+	}{
+		{name: "below threshold", content: errorRateContent(1000, 50), rate: 0.05, wantRows: 1000},
+		{name: "at threshold", content: errorRateContent(1000, 20), rate: 0.05, wantRows: 1000},
+		{name: "wrong column fails fast", content: errorRateContent(1000, 1), rate: 0.05, wantErr: true, wantRows: errorRateInterval},
+		{name: "above threshold", content: errorRateContent(1000, 10), rate: 0.05, wantErr: true, wantRows: errorRateInterval},
+		{name: "small file checked at end", content: errorRateContent(10, 5), rate: 0.05, wantErr: true, wantRows: 10},
+		{name: "disabled", content: errorRateContent(1000, 1), wantRows: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := NewCustomerImporter("", WithErrorPolicy(ErrorPolicySkip), WithMaxErrorRate(tt.rate))
+
+			_, summary, err := ci.ImportReader(context.Background(), strings.NewReader(tt.content))
+			if tt.wantErr != errors.Is(err, ErrErrorRateExceeded) {
+				t.Fatalf("import error = %v, want ErrErrorRateExceeded: %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+			if summary.Rows != tt.wantRows {
+				t.Errorf("read %d rows, want %d", summary.Rows, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package customerimporter
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+)
+
+// spillCounter is the external aggregation backend selected by WithExternalAggregation. Counts
+// are kept in a map of at most threshold domains; when it grows past that, the map is written
+// to a temporary file as a run sorted by domain and cleared. At the end all runs are merged in
+// domain order, external merge-sort style, summing the counts of domains spread over several
+// runs, so only one entry per run is held in memory while the result is produced.
+//
+// Runs store each entry as the uvarint length of the domain, the domain bytes and the uvarint
+// count, so domains may contain any byte.
+type spillCounter struct {
+	mem       mapCounter
+	threshold int
+	dir       string
+	runs      []string
+	// spilled is the number of entries written to runs, an upper bound of their distinct domains.
+	spilled int
+	// domains is the exact number of distinct domains once a merge completed.
+	domains int
+	merged  bool
+	// err is the first spill error; it is reported by eachSorted.
+	err error
+}
+
+func newSpillCounter(threshold int, dir string) *spillCounter {
+	return &spillCounter{mem: make(mapCounter), threshold: threshold, dir: dir}
+}
+
+func (c *spillCounter) add(domain string, n uint64) {
+	c.mem.add(domain, n)
+	if len(c.mem) > c.threshold && c.err == nil {
+		c.err = c.spill()
+	}
+}
+
+// count returns the in-memory count of domain only, so the overflow check of weighted rows does
+// not cover spilled runs; eachSorted reports an overflow across runs instead.
+func (c *spillCounter) count(domain string) uint64 { return c.mem[domain] }
+
+// len returns the number of distinct domains after a merge, and an upper bound before that.
+func (c *spillCounter) len() int {
+	if c.merged {
+		return c.domains
+	}
+	return len(c.mem) + c.spilled
+}
+
+// each calls fn in domain order. Errors are dropped; callers that need them use eachSorted.
+func (c *spillCounter) each(fn func(domain string, count uint64)) {
+	_ = c.eachSorted(func(domain string, count uint64) error {
+		fn(domain, count)
+		return nil
+	})
+}
+
+// spill writes the in-memory counts to a new run and clears them.
+func (c *spillCounter) spill() error {
+	file, err := os.CreateTemp(c.dir, "customerimporter-spill-*")
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %w", err)
+	}
+	c.runs = append(c.runs, file.Name())
+	err = writeRun(file, c.mem)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	c.spilled += len(c.mem)
+	c.mem = make(mapCounter)
+	return nil
+}
+
+// writeRun writes the entries of m to w sorted by domain.
+func writeRun(w io.Writer, m mapCounter) error {
+	domains := make([]string, 0, len(m))
+	for domain := range m {
+		domains = append(domains, domain)
+	}
+	slices.Sort(domains)
+
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	for _, domain := range domains {
+		buf = binary.AppendUvarint(buf[:0], uint64(len(domain)))
+		buf = append(buf, domain...)
+		buf = binary.AppendUvarint(buf, m[domain])
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// eachSorted merges all runs and calls fn once per distinct domain in alphabetical order with
+// its total count, stopping at the first error. The remaining in-memory counts are spilled
+// first, so the counter must not be added to afterwards. It may be called more than once.
+func (c *spillCounter) eachSorted(fn func(domain string, count uint64) error) error {
+	if c.err == nil && len(c.mem) > 0 {
+		c.err = c.spill()
+	}
+	if c.err != nil {
+		return c.err
+	}
+
+	runs := make(runHeap, 0, len(c.runs))
+	defer func() {
+		for _, run := range runs {
+			_ = run.file.Close()
+		}
+	}()
+	for _, path := range c.runs {
+		run, err := openRun(path)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+	var active runHeap
+	for _, run := range runs {
+		ok, err := run.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			active = append(active, run)
+		}
+	}
+	heap.Init(&active)
+
+	domains := 0
+	for active.Len() > 0 {
+		domain := active[0].domain
+		var total uint64
+		for active.Len() > 0 && active[0].domain == domain {
+			run := active[0]
+			if total > math.MaxUint64-run.count {
+				return fmt.Errorf("count for domain %q overflows uint64", domain)
+			}
+			total += run.count
+			ok, err := run.next()
+			if err != nil {
+				return err
+			}
+			if ok {
+				heap.Fix(&active, 0)
+			} else {
+				heap.Pop(&active)
+			}
+		}
+		domains++
+		if err := fn(domain, total); err != nil {
+			return err
+		}
+	}
+	c.domains, c.merged = domains, true
+	return nil
+}
+
+// remove deletes the spill files. The counter is unusable afterwards.
+func (c *spillCounter) remove() {
+	for _, path := range c.runs {
+		_ = os.Remove(path)
+	}
+	c.runs = nil
+}
+
+// runReader yields the entries of one sorted run.
+type runReader struct {
+	file   *os.File
+	r      *bufio.Reader
+	domain string
+	count  uint64
+}
+
+func openRun(path string) (*runReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+	return &runReader{file: file, r: bufio.NewReader(file)}, nil
+}
+
+// next advances to the following entry, returning false at the end of the run.
+func (rr *runReader) next() (bool, error) {
+	length, err := binary.ReadUvarint(rr.r)
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	domain := make([]byte, length)
+	if _, err := io.ReadFull(rr.r, domain); err != nil {
+		return false, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	count, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return false, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	rr.domain, rr.count = string(domain), count
+	return true, nil
+}
+
+// runHeap orders runs by their current domain for the k-way merge.
+type runHeap []*runReader
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return h[i].domain < h[j].domain }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	run := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return run
+}
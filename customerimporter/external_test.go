@@ -0,0 +1,119 @@
+package customerimporter
+
+import (
+	"context"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSpillCounter(t *testing.T) {
+	dir := t.TempDir()
+	c := newSpillCounter(2, dir)
+	for _, domain := range []string{"d.com", "b.com", "a.com", "d.com", "c.com", "a.com", "e.com", "a.com"} {
+		c.add(domain, 1)
+	}
+	if len(c.runs) < 2 {
+		t.Fatalf("expected several spilled runs with threshold 2, got %d", len(c.runs))
+	}
+
+	for pass := 0; pass < 2; pass++ {
+		var got []string
+		var counts []uint64
+		if err := c.eachSorted(func(domain string, count uint64) error {
+			got = append(got, domain)
+			counts = append(counts, count)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a.com", "b.com", "c.com", "d.com", "e.com"}; !slices.Equal(got, want) {
+			t.Errorf("pass %d merged domains = %v, want %v", pass, got, want)
+		}
+		if want := []uint64{3, 1, 1, 2, 1}; !slices.Equal(counts, want) {
+			t.Errorf("pass %d merged counts = %v, want %v", pass, counts, want)
+		}
+	}
+	if c.len() != 5 {
+		t.Errorf("len() after merge = %d, want 5", c.len())
+	}
+
+	c.remove()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("spill files left behind: %v", entries)
+	}
+}
+
+func TestSpillCounterOverflow(t *testing.T) {
+	c := newSpillCounter(1, t.TempDir())
+	defer c.remove()
+	c.add("a.com", math.MaxUint64)
+	c.add("b.com", 1)
+	c.add("a.com", 1)
+
+	err := c.eachSorted(func(string, uint64) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("expected overflow across runs, got %v", err)
+	}
+}
+
+func TestImportExternalAggregation(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("first_name,last_name,email\n")
+	for _, domain := range benchmarkDomains(5000, 1200) {
+		sb.WriteString("John,Doe,john@" + domain + "\n")
+	}
+	content := sb.String()
+
+	want, wantSummary, err := NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, threshold := range []int{1, 10, 1000, 100000} {
+		dir := t.TempDir()
+		ci := NewCustomerImporter("", WithExternalAggregation(threshold, dir), WithCategory())
+		got, summary, err := ci.ImportReader(context.Background(), strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary != wantSummary {
+			t.Errorf("threshold %d summary = %+v, want %+v", threshold, summary, wantSummary)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("threshold %d returned %d domains, want %d", threshold, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Domain != want[i].Domain || got[i].CustomerQuantity != want[i].CustomerQuantity || got[i].Category == "" {
+				t.Fatalf("threshold %d record %d = %+v, want %+v", threshold, i, got[i], want[i])
+			}
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("threshold %d left %d spill files behind", threshold, len(entries))
+		}
+	}
+}
+
+func TestStreamExternalAggregation(t *testing.T) {
+	content := "first_name,last_name,email\nA,B,a@z.com\nA,B,a@y.com\nA,B,b@z.com\nA,B,a@x.com\n"
+	var got []DomainData
+
+	summary, err := NewCustomerImporter("", WithExternalAggregation(1, t.TempDir())).
+		StreamReader(context.Background(), strings.NewReader(content), func(d DomainData) error {
+			got = append(got, d)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "x.com", CustomerQuantity: 1},
+		{Domain: "y.com", CustomerQuantity: 1},
+		{Domain: "z.com", CustomerQuantity: 2},
+	}
+	if !slices.Equal(got, want) || summary.Domains != 3 || summary.Customers != 4 {
+		t.Errorf("streamed %+v with summary %+v, want %+v", got, summary, want)
+	}
+}
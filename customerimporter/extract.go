@@ -0,0 +1,89 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrEmailPatternMismatch is wrapped by the row error of an email rejected by WithEmailPattern.
+var ErrEmailPatternMismatch = errors.New("does not match required pattern")
+
+// ErrNonASCIIDomain is wrapped by the row error of a domain rejected by WithASCIIOnlyDomains.
+var ErrNonASCIIDomain = errors.New("contains non-ASCII characters")
+
+// appendEmailValues appends the addresses of a raw email column value to dst: the parts between
+// EmailSeparators, trimmed and without empty ones. Without separators, or when every part is
+// empty, value itself is appended so validation reports it as usual.
+func (ci CustomerImporter) appendEmailValues(dst []string, value string) []string {
+	separators := ci.config.EmailSeparators
+	if separators == "" {
+		return append(dst, value)
+	}
+	n := len(dst)
+	for rest := value; rest != ""; {
+		part := rest
+		if i := strings.IndexAny(rest, separators); i >= 0 {
+			_, size := utf8.DecodeRuneInString(rest[i:])
+			part, rest = rest[:i], rest[i+size:]
+		} else {
+			rest = ""
+		}
+		if part = strings.TrimSpace(part); part != "" {
+			dst = append(dst, part)
+		}
+	}
+	if len(dst) == n {
+		dst = append(dst, value)
+	}
+	return dst
+}
+
+// errNoEmailMatch is wrapped by the row error of a value in which WithEmailRegex finds nothing.
+var errNoEmailMatch = errors.New("no email address matching")
+
+// emailField returns the email address held by a raw column value. Without an EmailRegex the
+// value is returned unchanged; otherwise the first capture group (or the whole match for a
+// pattern without groups) is returned.
+func (ci CustomerImporter) emailField(value string) (string, error) {
+	re := ci.config.EmailRegex
+	if re == nil {
+		return value, nil
+	}
+
+	match := re.FindStringSubmatch(value)
+	if match == nil {
+		return "", fmt.Errorf("%w %q found in %q", errNoEmailMatch, re.String(), value)
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+// matchEmailPattern checks the trimmed email against the configured EmailPattern, if any.
+func (ci CustomerImporter) matchEmailPattern(email string) error {
+	re := ci.config.EmailPattern
+	if re == nil {
+		return nil
+	}
+	email = strings.TrimSpace(email)
+	if loc := re.FindStringIndex(email); loc == nil || loc[0] != 0 || loc[1] != len(email) {
+		return fmt.Errorf("invalid email %q: %w %s", email, ErrEmailPatternMismatch, re)
+	}
+	return nil
+}
+
+// checkASCIIDomain rejects a domain containing any non-ASCII byte when ASCIIOnlyDomains is set.
+func (ci CustomerImporter) checkASCIIDomain(domain string) error {
+	if !ci.config.ASCIIOnlyDomains {
+		return nil
+	}
+	for i := 0; i < len(domain); i++ {
+		if domain[i] >= utf8.RuneSelf {
+			return fmt.Errorf("invalid domain %q: %w (first at byte %d)", domain, ErrNonASCIIDomain, i)
+		}
+	}
+	return nil
+}
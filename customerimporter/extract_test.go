@@ -0,0 +1,241 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEmailField(t *testing.T) {
+	tests := []struct {
+		name        string
+		re          *regexp.Regexp
+		value       string
+		want        string
+		expectError bool
+	}{
+		{
+			name:  "no regex uses whole value",
+			value: " john@x.com ",
+			want:  " john@x.com ",
+		},
+		{
+			name:  "first capture group",
+			re:    regexp.MustCompile(`Contact: (\S+@\S+)`),
+			value: "Contact: john@x.com (primary)",
+			want:  "john@x.com",
+		},
+		{
+			name:  "whole match without groups",
+			re:    regexp.MustCompile(`[^\s()]+@[^\s()]+`),
+			value: "Reach me at (jane@y.org) anytime",
+			want:  "jane@y.org",
+		},
+		{
+			name:        "no match",
+			re:          regexp.MustCompile(`Contact: (\S+@\S+)`),
+			value:       "no address here",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := NewCustomerImporter("", WithEmailRegex(tt.re))
+			got, err := ci.emailField(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("emailField(%q) expected error, got %q", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("emailField(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("emailField(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportEmailRegex(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,contact,gender,ip_address
+John,Doe,Contact: john@x.com (primary),Male,192.168.1.1
+Jane,Doe,Contact: jane@x.com,Female,192.168.1.2
+Jim,Beam,Contact: jim@y.org (work),Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	re := regexp.MustCompile(`([^\s()]+@[^\s()]+)`)
+	data, err := NewCustomerImporter(csvPath, WithEmailRegex(re)).ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "x.com", CustomerQuantity: 2},
+		{Domain: "y.org", CustomerQuantity: 1},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+
+	// Without the regex the surrounding text leaks into the domain.
+	data, err = NewCustomerImporter(csvPath).ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == len(want) {
+		t.Errorf("expected free-text suffixes to produce extra domains without WithEmailRegex, got %v", data)
+	}
+}
+
+func TestMatchEmailPattern(t *testing.T) {
+	noPlus := regexp.MustCompile(`[^+@\s]+@[^@\s]+`)
+	tests := []struct {
+		email   string
+		wantErr bool
+	}{
+		{email: "john@example.com"},
+		{email: " john.doe@example.com "},
+		{email: "john+news@example.com", wantErr: true},
+		{email: "+@example.com", wantErr: true},
+		{email: "prefix john@example.com", wantErr: true},
+	}
+
+	ci := NewCustomerImporter("", WithEmailPattern(noPlus))
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := ci.matchEmailPattern(tt.email)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("matchEmailPattern(%q) error = %v, wantErr %t", tt.email, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrEmailPatternMismatch) {
+				t.Errorf("matchEmailPattern(%q) error = %v, want ErrEmailPatternMismatch", tt.email, err)
+			}
+		})
+	}
+}
+
+func TestMatchEmailPatternSemantics(t *testing.T) {
+	// Leftmost-first stops at example.com, leaving ".au" unmatched; POSIX takes the longest match
+	pattern := `[a-z]+@(example\.com|example\.com\.au)`
+	email := "john@example.com.au"
+	if err := NewCustomerImporter("", WithEmailPattern(regexp.MustCompile(pattern))).matchEmailPattern(email); !errors.Is(err, ErrEmailPatternMismatch) {
+		t.Errorf("leftmost-first matchEmailPattern(%q) error = %v, want ErrEmailPatternMismatch", email, err)
+	}
+	if err := NewCustomerImporter("", WithEmailPattern(regexp.MustCompilePOSIX(pattern))).matchEmailPattern(email); err != nil {
+		t.Errorf("POSIX matchEmailPattern(%q) error = %v, want a match", email, err)
+	}
+
+	_, _, err := NewCustomerImporter("", WithEmailPattern(nil)).
+		ImportReader(context.Background(), strings.NewReader("a,b,email\nJohn,Doe,john@example.com\n"))
+	if err == nil || !strings.Contains(err.Error(), "invalid email pattern") {
+		t.Errorf("ImportReader() with a nil pattern error = %v, want an invalid pattern error", err)
+	}
+}
+
+func TestImportEmailPattern(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Jane,Doe,jane+shop@example.com,Female,192.168.1.2`
+	noPlus := regexp.MustCompile(`[^+]+@.+`)
+
+	_, _, err := NewCustomerImporter("", WithEmailPattern(noPlus)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if !errors.Is(err, ErrEmailPatternMismatch) || !strings.Contains(err.Error(), "jane+shop@example.com") {
+		t.Errorf("expected pattern mismatch for the plus address, got %v", err)
+	}
+
+	// The pattern composes with the built-in checks: a matching address must still be valid.
+	_, _, err = NewCustomerImporter("", WithEmailPattern(regexp.MustCompile(`.*`))).
+		ImportReader(context.Background(), strings.NewReader("a,b,email\nJohn,Doe,not-an-email\n"))
+	if err == nil || errors.Is(err, ErrEmailPatternMismatch) {
+		t.Errorf("expected built-in validation error, got %v", err)
+	}
+
+	data, summary, err := NewCustomerImporter("", WithEmailPattern(noPlus), WithErrorPolicy(ErrorPolicySkip)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].CustomerQuantity != 1 || summary.SkippedRows != 1 {
+		t.Errorf("got %+v with %d skipped rows, want example.com once and 1 skipped row", data, summary.SkippedRows)
+	}
+}
+
+func TestImportASCIIOnlyDomains(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Jane,Doe,jane@bücher.de,Female,192.168.1.2
+Joe,Doe,joe@xn--bcher-kva.de,Male,192.168.1.3`
+
+	_, _, err := NewCustomerImporter("", WithASCIIOnlyDomains()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if !errors.Is(err, ErrNonASCIIDomain) || !strings.Contains(err.Error(), "bücher.de") {
+		t.Errorf("expected non-ASCII domain error for bücher.de, got %v", err)
+	}
+
+	data, summary, err := NewCustomerImporter("", WithASCIIOnlyDomains(), WithErrorPolicy(ErrorPolicySkip)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].Domain != "example.com" || data[1].Domain != "xn--bcher-kva.de" || summary.SkippedRows != 1 {
+		t.Errorf("got %+v with %d skipped rows, want the two ASCII domains and 1 skipped row", data, summary.SkippedRows)
+	}
+
+	// Without the option the Unicode domain is aggregated like any other.
+	data, _, err = NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil || len(data) != 3 {
+		t.Errorf("default import got %+v, %v; want all three domains", data, err)
+	}
+}
+
+func TestImportEmailSeparators(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,"john@example.com; j.doe@other.org",Male,192.168.1.1
+Jane,Doe,"jane@example.com,",Female,192.168.1.2
+Joe,Doe,joe@other.org,Male,192.168.1.3`
+
+	data, summary, err := NewCustomerImporter("", WithEmailSeparators([]rune{';', ','})).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprint([]DomainData{
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "other.org", CustomerQuantity: 2},
+	})
+	if got := fmt.Sprint(data); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if summary.Rows != 3 || summary.Customers != 4 || summary.TrimmedValues != 0 {
+		t.Errorf("summary counted %d rows, %d customers and %d trimmed values, want 3, 4 and 0",
+			summary.Rows, summary.Customers, summary.TrimmedValues)
+	}
+
+	// Each address is validated on its own and fails the whole row
+	invalid := "first_name,last_name,email\nJohn,Doe,\"john@example.com; not-an-email\"\n"
+	_, _, err = NewCustomerImporter("", WithEmailSeparators([]rune{';'})).
+		ImportReader(context.Background(), strings.NewReader(invalid))
+	if err == nil || !strings.Contains(err.Error(), "invalid email") {
+		t.Errorf("expected invalid email error, got %v", err)
+	}
+
+	// Without separators the packed value is a single, invalid address
+	_, _, err = NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content))
+	if err == nil {
+		t.Error("expected packed addresses to be rejected without WithEmailSeparators")
+	}
+}
@@ -0,0 +1,19 @@
+package customerimporter
+
+import "strings"
+
+// DomainContains returns a WithDomainFilter predicate matching domains that contain substr,
+// compared case-insensitively (see FoldDomain).
+func DomainContains(substr string) func(domain string) bool {
+	substr = FoldDomain(substr)
+	return func(domain string) bool {
+		return strings.Contains(FoldDomain(domain), substr)
+	}
+}
+
+// matchesRowFilter reports whether line is counted under WithRowFilter. line must be long
+// enough to hold the RowFilterColumn.
+func (ci CustomerImporter) matchesRowFilter(line []string) bool {
+	column := ci.config.RowFilterColumn
+	return column == nil || strings.TrimSpace(line[*column]) == ci.config.RowFilterValue
+}
@@ -0,0 +1,93 @@
+package customerimporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDomainContains(t *testing.T) {
+	tests := []struct {
+		substr string
+		domain string
+		want   bool
+	}{
+		{substr: "mail", domain: "gmail.com", want: true},
+		{substr: "MAIL", domain: "Hotmail.com", want: true},
+		{substr: ".co.uk", domain: "example.co.uk", want: true},
+		{substr: "mail", domain: "example.com", want: false},
+		{substr: "", domain: "example.com", want: true},
+		{substr: "ΟΔΥΣΣΕΥΣ", domain: "οδυσσευς.gr", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.substr+"/"+tt.domain, func(t *testing.T) {
+			if got := DomainContains(tt.substr)(tt.domain); got != tt.want {
+				t.Errorf("DomainContains(%q)(%q) = %t, want %t", tt.substr, tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportDomainFilter(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@gmail.com,Male,192.168.1.1
+Jane,Doe,jane@GMail.com,Female,192.168.1.2
+Max,Roe,max@hotmail.com,Male,192.168.1.3
+Ann,Poe,ann@example.com,Female,192.168.1.4`
+
+	data, summary, err := NewCustomerImporter("", WithDomainFilter(DomainContains("Mail"))).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "GMail.com", CustomerQuantity: 1},
+		{Domain: "gmail.com", CustomerQuantity: 1},
+		{Domain: "hotmail.com", CustomerQuantity: 1},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %+v, want %+v", data, want)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+	if summary.Rows != 4 || summary.Domains != 3 || summary.Customers != 3 {
+		t.Errorf("summary = %+v, want 4 rows and 3 matching domains and customers", summary)
+	}
+}
+
+func TestImportRowFilter(t *testing.T) {
+	content := "first_name,last_name,email,gender\n" +
+		"John,Doe,john@example.com,Male\n" +
+		"Jane,Doe,jane@example.com,Female\n" +
+		"Amy,Roe,amy@other.com, Female \n" +
+		"Max,Roe,invalid,Male\n" +
+		"Eve,Poe,eve@other.com,female\n"
+
+	data, summary, err := NewCustomerImporter("", WithRowFilter(3, "Female")).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]uint64{"example.com": 1, "other.com": 1}
+	if len(data) != len(want) {
+		t.Fatalf("got %v, want %v", data, want)
+	}
+	for _, d := range data {
+		if d.CustomerQuantity != want[d.Domain] {
+			t.Errorf("%s = %d, want %d", d.Domain, d.CustomerQuantity, want[d.Domain])
+		}
+	}
+	if summary.Rows != 5 || summary.FilteredRows != 3 {
+		t.Errorf("summary counted %d rows, %d filtered, want 5 and 3", summary.Rows, summary.FilteredRows)
+	}
+
+	_, _, err = NewCustomerImporter("", WithRowFilter(4, "Female")).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err == nil || !strings.Contains(err.Error(), "expected at least 5 columns") {
+		t.Errorf("expected missing filter column error, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package customerimporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// Fingerprint returns a hex SHA-256 digest of the effective configuration (as rendered by
+// Config.String) followed by the streamed bytes of the input file, for build-cache-style
+// skipping: a run over the same content with the same settings yields the same fingerprint, so
+// an orchestrator can compare it with the one stored from the previous run. extra parts, such
+// as the exporter configuration, are hashed between the two so output settings count as well.
+//
+// The input path itself is not hashed, so a renamed or moved file keeps its fingerprint. Function
+// options (validators, sinks, preprocessors) only contribute whether they are set. The file is
+// read separately from the import, which makes Fingerprint unsuitable for named pipes.
+func (ci CustomerImporter) Fingerprint(extra ...string) (string, error) {
+	file, err := os.Open(ci.path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	h := sha256.New()
+	writePart(h, ci.config.String())
+	for _, part := range extra {
+		writePart(h, part)
+	}
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash input file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writePart hashes s behind its length, so consecutive parts cannot run into each other.
+func writePart(h hash.Hash, s string) {
+	fmt.Fprintf(h, "%d:%s\n", len(s), s)
+}
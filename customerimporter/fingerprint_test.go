@@ -0,0 +1,59 @@
+package customerimporter
+
+import (
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	content := "first_name,last_name,email\nJohn,Doe,john@example.com\n"
+	first, second, changed := dir+"/first.csv", dir+"/second.csv", dir+"/changed.csv"
+	for path, data := range map[string]string{
+		first:   content,
+		second:  content,
+		changed: content + "Jane,Doe,jane@example.com\n",
+	} {
+		if err := writeTestCSV(path, data); err != nil {
+			t.Fatalf("failed to write test CSV: %v", err)
+		}
+	}
+
+	fingerprint := func(ci *CustomerImporter, extra ...string) string {
+		t.Helper()
+		fp, err := ci.Fingerprint(extra...)
+		if err != nil {
+			t.Fatalf("Fingerprint() error: %v", err)
+		}
+		return fp
+	}
+
+	base := fingerprint(NewCustomerImporter(first, WithStripWWWPrefix()))
+	if len(base) != 64 {
+		t.Errorf("expected a 64 character hex digest, got %q", base)
+	}
+	if got := fingerprint(NewCustomerImporter(first, WithStripWWWPrefix())); got != base {
+		t.Errorf("same input and config: fingerprint %s, want %s", got, base)
+	}
+	if got := fingerprint(NewCustomerImporter(second, WithStripWWWPrefix())); got != base {
+		t.Errorf("same content under another path: fingerprint %s, want %s", got, base)
+	}
+
+	for name, got := range map[string]string{
+		"changed config":  fingerprint(NewCustomerImporter(first)),
+		"changed input":   fingerprint(NewCustomerImporter(changed, WithStripWWWPrefix())),
+		"extra part":      fingerprint(NewCustomerImporter(first, WithStripWWWPrefix()), "exporter.sort=count"),
+		"split extra":     fingerprint(NewCustomerImporter(first, WithStripWWWPrefix()), "exporter.", "sort=count"),
+		"different extra": fingerprint(NewCustomerImporter(first, WithStripWWWPrefix()), "exporter.sort=domain"),
+	} {
+		if got == base {
+			t.Errorf("%s: fingerprint unchanged", name)
+		}
+	}
+	if a, b := fingerprint(NewCustomerImporter(first), "ab", "c"), fingerprint(NewCustomerImporter(first), "a", "bc"); a == b {
+		t.Error("differently split extra parts share a fingerprint")
+	}
+
+	if _, err := NewCustomerImporter(dir + "/missing.csv").Fingerprint(); err == nil {
+		t.Error("expected error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,84 @@
+package customerimporter
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+var testFixedWidthLayout = FixedWidthLayout{
+	Fields: []FixedWidthField{
+		{Offset: 0, Length: 10},
+		{Offset: 10, Length: 10},
+		{Offset: 20, Length: 30},
+		{Offset: 50, Length: 6},
+	},
+}
+
+func TestImportFixedWidth(t *testing.T) {
+	data, err := NewCustomerImporter("./test_fixed_width.txt", WithFixedWidth(testFixedWidthLayout)).ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "360.cn", CustomerQuantity: 1},
+		{Domain: "cyberchimps.com", CustomerQuantity: 1},
+		{Domain: "github.io", CustomerQuantity: 2},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+}
+
+func TestFixedWidthReader(t *testing.T) {
+	layout := FixedWidthLayout{
+		Fields:   []FixedWidthField{{Offset: 0, Length: 4}, {Offset: 4, Length: 8}, {Offset: 20, Length: 5}},
+		NoHeader: true,
+	}
+	input := "ab  a@b.com \r\n\n    ab@c.de\n"
+	reader := newFixedWidthReader(strings.NewReader(input), layout)
+
+	want := [][]string{
+		{"field_1", "field_2", "field_3"},
+		{"ab", "a@b.com", ""},
+		{"", "ab@c.de", ""},
+	}
+	for i, w := range want {
+		got, err := reader.Read()
+		if err != nil {
+			t.Fatalf("record %d: unexpected error: %v", i, err)
+		}
+		if strings.Join(got, "|") != strings.Join(w, "|") {
+			t.Errorf("record %d = %q, want %q", i, got, w)
+		}
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("expected io.EOF after last record, got %v", err)
+	}
+}
+
+func TestImportFixedWidthInvalidLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []FixedWidthField
+	}{
+		{name: "negative length", fields: []FixedWidthField{{Offset: 0, Length: 10}, {Offset: 20, Length: -5}}},
+		{name: "zero length", fields: []FixedWidthField{{Offset: 0, Length: 0}}},
+		{name: "negative offset", fields: []FixedWidthField{{Offset: -1, Length: 10}}},
+		{name: "no fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := NewCustomerImporter("./test_fixed_width.txt", WithFixedWidth(FixedWidthLayout{Fields: tt.fields}))
+			_, err := ci.ImportDomainData()
+			if err == nil || !strings.Contains(err.Error(), "invalid fixed-width") {
+				t.Errorf("ImportDomainData() error = %v, want an invalid layout error", err)
+			}
+		})
+	}
+}
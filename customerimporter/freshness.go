@@ -0,0 +1,26 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrStaleInput is returned by CheckFreshness when the input file is older than allowed.
+var ErrStaleInput = errors.New("input file is stale")
+
+// CheckFreshness fails with an error wrapping ErrStaleInput when the configured file was last
+// modified more than maxAge ago, so pipelines can refuse to process stale exports. It only
+// inspects the file's mtime and does not read it.
+func (ci CustomerImporter) CheckFreshness(maxAge time.Duration) error {
+	info, err := os.Stat(ci.path)
+	if err != nil {
+		return err
+	}
+	if age := time.Since(info.ModTime()); age > maxAge {
+		return fmt.Errorf("%w: %s was modified %s ago, more than the allowed %s",
+			ErrStaleInput, ci.path, age.Round(time.Second), maxAge)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package customerimporter
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCheckFreshness(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	if err := writeTestCSV(csvPath, "first_name,last_name,email\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	ci := NewCustomerImporter(csvPath)
+
+	if err := ci.CheckFreshness(time.Hour); err != nil {
+		t.Errorf("fresh file rejected: %v", err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(csvPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := ci.CheckFreshness(24 * time.Hour); !errors.Is(err, ErrStaleInput) {
+		t.Errorf("expected ErrStaleInput for a 48h old file, got %v", err)
+	}
+	if err := ci.CheckFreshness(72 * time.Hour); err != nil {
+		t.Errorf("file within -max-age rejected: %v", err)
+	}
+
+	if err := NewCustomerImporter(t.TempDir() + "/missing.csv").CheckFreshness(time.Hour); err == nil {
+		t.Error("expected error for a missing file, got nil")
+	}
+}
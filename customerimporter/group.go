@@ -0,0 +1,97 @@
+package customerimporter
+
+import (
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// UngroupedDomains is the WithDomainGroups group of the domains no group lists.
+const UngroupedDomains = "ungrouped"
+
+// GroupByCount builds a reverse index from each CustomerQuantity to the domains having exactly
+// that many customers. Every domain list is sorted alphabetically.
+func GroupByCount(data []DomainData) map[uint64][]string {
+	groups := make(map[uint64][]string)
+	for _, d := range data {
+		groups[d.CustomerQuantity] = append(groups[d.CustomerQuantity], d.Domain)
+	}
+	for _, domains := range groups {
+		slices.Sort(domains)
+	}
+	return groups
+}
+
+// DefaultLengthBuckets are the GroupByDomainLength bounds used by the CLI: "<=5", "6-10", "11+".
+var DefaultLengthBuckets = []int{5, 10}
+
+// GroupByDomainLength sums the customer counts of data by the length of the domain in
+// characters. buckets lists the inclusive upper bounds of all but the last bucket, so
+// []int{5, 10} produces the buckets "<=5", "6-10" and "11+". Non-positive and repeated bounds
+// are ignored. Every bucket is present in the result, with zero when no domain falls into it;
+// LengthBucketLabels returns them in order.
+func GroupByDomainLength(data []DomainData, buckets []int) map[string]uint64 {
+	bounds := lengthBounds(buckets)
+	labels := LengthBucketLabels(bounds)
+	totals := make(map[string]uint64, len(labels))
+	for _, label := range labels {
+		totals[label] = 0
+	}
+	for _, d := range data {
+		n := utf8.RuneCountInString(d.Domain)
+		i, _ := slices.BinarySearch(bounds, n)
+		totals[labels[i]] += d.CustomerQuantity
+	}
+	return totals
+}
+
+// LengthBucketLabels returns the GroupByDomainLength bucket names for buckets, shortest first.
+func LengthBucketLabels(buckets []int) []string {
+	bounds := lengthBounds(buckets)
+	labels := make([]string, 0, len(bounds)+1)
+	lower := 1
+	for i, upper := range bounds {
+		switch {
+		case i == 0:
+			labels = append(labels, "<="+strconv.Itoa(upper))
+		case lower == upper:
+			labels = append(labels, strconv.Itoa(upper))
+		default:
+			labels = append(labels, strconv.Itoa(lower)+"-"+strconv.Itoa(upper))
+		}
+		lower = upper + 1
+	}
+	return append(labels, strconv.Itoa(lower)+"+")
+}
+
+// lengthBounds returns the sorted, distinct, positive bounds of buckets.
+func lengthBounds(buckets []int) []int {
+	bounds := slices.DeleteFunc(slices.Clone(buckets), func(n int) bool {
+		return n <= 0
+	})
+	slices.Sort(bounds)
+	return slices.Compact(bounds)
+}
+
+// domainGroupIndex maps every case-folded domain of groups to the name of its group, the
+// alphabetically first one for domains listed in several groups.
+func domainGroupIndex(groups map[string][]string) map[string]string {
+	index := make(map[string]string)
+	for name, domains := range groups {
+		for _, domain := range domains {
+			domain = FoldDomain(domain)
+			if current, ok := index[domain]; !ok || name < current {
+				index[domain] = name
+			}
+		}
+	}
+	return index
+}
+
+// group returns the DomainGroups group counting domain.
+func (r *importRun) group(domain string) string {
+	if name, ok := r.groups[FoldDomain(domain)]; ok {
+		return name
+	}
+	return UngroupedDomains
+}
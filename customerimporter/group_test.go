@@ -0,0 +1,139 @@
+package customerimporter
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupByCount(t *testing.T) {
+	data := []DomainData{
+		{Domain: "zeta.com", CustomerQuantity: 3},
+		{Domain: "alpha.com", CustomerQuantity: 1},
+		{Domain: "beta.com", CustomerQuantity: 3},
+		{Domain: "gamma.com", CustomerQuantity: 3},
+		{Domain: "delta.com", CustomerQuantity: 7},
+	}
+	want := map[uint64][]string{
+		1: {"alpha.com"},
+		3: {"beta.com", "gamma.com", "zeta.com"},
+		7: {"delta.com"},
+	}
+
+	got := GroupByCount(data)
+	if len(got) != len(want) {
+		t.Fatalf("GroupByCount returned %d groups, want %d: %v", len(got), len(want), got)
+	}
+	for count, domains := range want {
+		if !slices.Equal(got[count], domains) {
+			t.Errorf("GroupByCount()[%d] = %v, want %v", count, got[count], domains)
+		}
+	}
+	if len(GroupByCount(nil)) != 0 {
+		t.Error("GroupByCount(nil) expected an empty index")
+	}
+}
+
+func TestGroupByDomainLength(t *testing.T) {
+	data := []DomainData{
+		{Domain: "a.co", CustomerQuantity: 1},         // 4
+		{Domain: "ab.co", CustomerQuantity: 2},        // 5, upper boundary
+		{Domain: "abc.co", CustomerQuantity: 4},       // 6, lower boundary
+		{Domain: "abcdef.com", CustomerQuantity: 8},   // 10, upper boundary
+		{Domain: "abcdefg.com", CustomerQuantity: 16}, // 11
+		{Domain: "übung.de", CustomerQuantity: 32},    // 8 characters, 9 bytes
+	}
+
+	tests := []struct {
+		name    string
+		buckets []int
+		want    map[string]uint64
+		labels  []string
+	}{
+		{
+			name:    "default buckets",
+			buckets: DefaultLengthBuckets,
+			want:    map[string]uint64{"<=5": 3, "6-10": 44, "11+": 16},
+			labels:  []string{"<=5", "6-10", "11+"},
+		},
+		{
+			name:    "unsorted and repeated bounds",
+			buckets: []int{10, 0, 5, 10, 6},
+			want:    map[string]uint64{"<=5": 3, "6": 4, "7-10": 40, "11+": 16},
+			labels:  []string{"<=5", "6", "7-10", "11+"},
+		},
+		{
+			name:    "empty bucket kept",
+			buckets: []int{3, 20},
+			want:    map[string]uint64{"<=3": 0, "4-20": 63, "21+": 0},
+			labels:  []string{"<=3", "4-20", "21+"},
+		},
+		{
+			name:   "no bounds",
+			want:   map[string]uint64{"1+": 63},
+			labels: []string{"1+"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GroupByDomainLength(data, tt.buckets)
+			if len(got) != len(tt.want) {
+				t.Fatalf("GroupByDomainLength() = %v, want %v", got, tt.want)
+			}
+			for label, total := range tt.want {
+				if got[label] != total {
+					t.Errorf("bucket %q = %d, want %d", label, got[label], total)
+				}
+			}
+			if labels := LengthBucketLabels(tt.buckets); !slices.Equal(labels, tt.labels) {
+				t.Errorf("LengthBucketLabels() = %v, want %v", labels, tt.labels)
+			}
+		})
+	}
+}
+
+func TestImportDomainGroups(t *testing.T) {
+	content := `first_name,last_name,email
+John,Doe,john@a.com
+Jane,Doe,jane@B.com
+Joe,Doe,joe@www.c.com
+Ann,Doe,ann@other.com
+Max,Doe,max@a.com
+Eve,Doe,eve@more.org`
+
+	groups := map[string][]string{
+		"competitors": {"a.com", "b.com"},
+		"partners":    {"c.com", "a.com"},
+		"suppliers":   {"d.com"},
+	}
+	ci := NewCustomerImporter("", WithDomainGroups(groups), WithStripWWWPrefix())
+	data, summary, err := ci.ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "competitors", CustomerQuantity: 3},
+		{Domain: "partners", CustomerQuantity: 1},
+		{Domain: "suppliers", CustomerQuantity: 0},
+		{Domain: UngroupedDomains, CustomerQuantity: 2},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("got %+v, want %+v", data, want)
+	}
+	if summary.Customers != 6 || summary.Domains != 3 {
+		t.Errorf("summary counted %d customers in %d groups, want 6 in 3", summary.Customers, summary.Domains)
+	}
+
+	// The option keeps its own copy of the groups
+	groups["suppliers"][0] = "other.com"
+	data, _, err = ci.ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("after changing the caller's map got %+v, want %+v", data, want)
+	}
+}
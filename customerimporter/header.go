@@ -0,0 +1,75 @@
+package customerimporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CheckHeader reads only the header of the configured file and validates it as
+// WithHeaderValidation would, without touching any data row. It lets callers reject obviously
+// wrong files before starting a potentially long import.
+//
+// Tar archives are not checked here; the import validates the header of every member it reads.
+func (ci CustomerImporter) CheckHeader() error {
+	if isTar, _ := tarFormat(ci.path); isTar {
+		return nil
+	}
+	file, err := os.Open(ci.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ci.CheckHeaderReader(file)
+}
+
+// CheckHeaderReader is like CheckHeader but reads the header from r.
+func (ci CustomerImporter) CheckHeaderReader(r io.Reader) error {
+	records, err := ci.newRecordReader(r)
+	if err != nil {
+		return err
+	}
+	header, err := records.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	return ci.validateHeader(header)
+}
+
+// validateHeader checks that header has every configured email column and that each of them is
+// named like one, i.e. contains "email" case-insensitively. Synthesized fixed-width headers are
+// only checked for their width.
+func (ci CustomerImporter) validateHeader(header []string) error {
+	columns, err := ci.emailColumns()
+	if err != nil {
+		return err
+	}
+	if last := slices.Max(columns); len(header) <= last {
+		return fmt.Errorf("invalid CSV header: expected at least %d columns, got %d", last+1, len(header))
+	}
+	if layout := ci.config.FixedWidth; layout != nil && layout.NoHeader {
+		return nil
+	}
+	for _, column := range columns {
+		if !strings.Contains(strings.ToLower(header[column]), "email") {
+			return fmt.Errorf("invalid CSV header: column %d is %q, expected an email column", column, header[column])
+		}
+	}
+	return nil
+}
+
+// emailColumns returns the configured email columns, defaulting to emailColumnIndex.
+func (ci CustomerImporter) emailColumns() ([]int, error) {
+	columns := ci.config.EmailColumns
+	if len(columns) == 0 {
+		columns = []int{emailColumnIndex}
+	}
+	if slices.Min(columns) < 0 {
+		return nil, fmt.Errorf("invalid email column index %d", slices.Min(columns))
+	}
+	return columns, nil
+}
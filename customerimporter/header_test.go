@@ -0,0 +1,133 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// errDataRead is returned by headerOnlyReader once anything past the header is requested.
+var errDataRead = errors.New("data rows were read")
+
+// headerOnlyReader hands out header in a single read and fails every later read, proving that
+// nothing beyond the header was consumed.
+type headerOnlyReader struct {
+	header string
+	done   bool
+}
+
+func (h *headerOnlyReader) Read(p []byte) (int, error) {
+	if h.done {
+		return 0, errDataRead
+	}
+	h.done = true
+	return copy(p, h.header), nil
+}
+
+func TestValidateHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []string
+		opts     []Option
+		errorMsg string
+	}{
+		{name: "default layout", header: []string{"first_name", "last_name", "email", "gender", "ip_address"}},
+		{name: "hyphenated e-mail not recognized", header: []string{"first", "last", "E-Mail Address"}, errorMsg: "expected an email column"},
+		{name: "mixed case email", header: []string{"first", "last", "Primary_EMAIL"}},
+		{name: "too few columns", header: []string{"first_name", "email"}, errorMsg: "expected at least 3 columns, got 2"},
+		{name: "wrong column", header: []string{"email", "first_name", "last_name"}, errorMsg: `column 2 is "last_name"`},
+		{
+			name:   "custom email columns",
+			header: []string{"email", "name", "backup_email"},
+			opts:   []Option{WithEmailColumns([]int{0, 2})},
+		},
+		{
+			name:     "secondary column misnamed",
+			header:   []string{"email", "name", "phone"},
+			opts:     []Option{WithEmailColumns([]int{0, 2})},
+			errorMsg: `column 2 is "phone"`,
+		},
+		{
+			name:   "fixed-width without header",
+			header: []string{"field_1", "field_2", "field_3"},
+			opts:   []Option{WithFixedWidth(FixedWidthLayout{Fields: make([]FixedWidthField, 3), NoHeader: true})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCustomerImporter("", tt.opts...).validateHeader(tt.header)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("validateHeader(%q) unexpected error: %v", tt.header, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("validateHeader(%q) error = %v, want it to contain %q", tt.header, err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestCheckHeaderReadsNoDataRows(t *testing.T) {
+	ci := NewCustomerImporter("", WithHeaderValidation())
+
+	bad := "first_name,last_name,phone,gender\n"
+	if err := ci.CheckHeaderReader(&headerOnlyReader{header: bad}); err == nil || errors.Is(err, errDataRead) {
+		t.Errorf("CheckHeaderReader(bad header) = %v, want a header error before any data row", err)
+	}
+	if _, _, err := ci.ImportReader(context.Background(), &headerOnlyReader{header: bad}); err == nil || errors.Is(err, errDataRead) {
+		t.Errorf("ImportReader(bad header) = %v, want a header error before any data row", err)
+	}
+
+	good := "first_name,last_name,email,gender\n"
+	if err := ci.CheckHeaderReader(&headerOnlyReader{header: good}); err != nil {
+		t.Errorf("CheckHeaderReader(good header) unexpected error: %v", err)
+	}
+}
+
+func TestCheckHeader(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,phone,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	if err := NewCustomerImporter(csvPath).CheckHeader(); err == nil || !strings.Contains(err.Error(), `column 2 is "phone"`) {
+		t.Errorf("CheckHeader() error = %v, want misnamed email column", err)
+	}
+	if err := NewCustomerImporter(t.TempDir() + "/missing.csv").CheckHeader(); err == nil {
+		t.Error("CheckHeader() on a missing file expected error, got nil")
+	}
+	if _, err := NewCustomerImporter(csvPath).ImportDomainData(); err != nil {
+		t.Errorf("import without header validation unexpectedly failed: %v", err)
+	}
+}
+
+func TestImportRepeatedHeader(t *testing.T) {
+	content := "first_name,last_name,email\n" +
+		"John,Doe,john@example.com\n" +
+		"first_name,last_name,email\n" +
+		"Jane,Doe,jane@example.com\n" +
+		"first_name,last_name,email\n" +
+		"Max,Roe,max@other.com\n"
+
+	if _, _, err := NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content)); err == nil {
+		t.Error("expected the repeated header to fail as an invalid email without the option")
+	}
+
+	data, summary, err := NewCustomerImporter("", WithSkipRepeatedHeaders()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].CustomerQuantity != 2 || data[1].CustomerQuantity != 1 {
+		t.Errorf("got %v, want example.com counted twice and other.com once", data)
+	}
+	if summary.Rows != 3 || summary.RepeatedHeaders != 2 || summary.SkippedRows != 0 {
+		t.Errorf("summary = %+v, want 3 rows and 2 repeated headers", summary)
+	}
+}
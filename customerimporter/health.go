@@ -0,0 +1,201 @@
+package customerimporter
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SkipReason classifies why a row was skipped under ErrorPolicySkip.
+type SkipReason int
+
+const (
+	// SkipEmptyEmail is a blank email value.
+	SkipEmptyEmail SkipReason = iota
+	// SkipMissingAt is an email without '@'.
+	SkipMissingAt
+	// SkipEmptyLocalPart is an email with nothing before the '@'.
+	SkipEmptyLocalPart
+	// SkipEmptyDomain is an email with nothing after the '@'.
+	SkipEmptyDomain
+	// SkipMultipleAt is an email with more than one '@'.
+	SkipMultipleAt
+	// SkipEmailTooLong is an email longer than strict mode allows.
+	SkipEmailTooLong
+	// SkipInvalidLocalPart is an error wrapping ErrInvalidLocalPart.
+	SkipInvalidLocalPart
+	// SkipInvalidDomain is an error wrapping ErrInvalidDomain.
+	SkipInvalidDomain
+	// SkipNonASCIIDomain is an error wrapping ErrNonASCIIDomain.
+	SkipNonASCIIDomain
+	// SkipInvalidHostname is an error wrapping ErrInvalidHostname.
+	SkipInvalidHostname
+	// SkipIPAddressDomain is an error wrapping ErrIPAddressDomain.
+	SkipIPAddressDomain
+	// SkipNoEmailMatch is a value in which WithEmailRegex found no address.
+	SkipNoEmailMatch
+	// SkipPatternMismatch is an error wrapping ErrEmailPatternMismatch.
+	SkipPatternMismatch
+	// SkipFieldCount is a row with the wrong number of fields.
+	SkipFieldCount
+	// SkipEmptyCustomerID is a blank WithCustomerIDColumn value.
+	SkipEmptyCustomerID
+	// SkipInvalidWeight is an invalid WithWeightColumn value.
+	SkipInvalidWeight
+	// SkipInvalidDate is an invalid WithDateColumn value.
+	SkipInvalidDate
+	// SkipInvalidIP is an invalid WithIPColumn value.
+	SkipInvalidIP
+	// SkipRejected is an error wrapping ErrRowRejected.
+	SkipRejected
+	// SkipOther covers row errors without a more specific reason.
+	SkipOther
+
+	skipReasonCount
+)
+
+// skipReasons maps each SkipReason to its name and, except for SkipOther, the error it is
+// matched by with errors.Is. Both field count errors share SkipFieldCount.
+var skipReasons = [...]struct {
+	name string
+	errs []error
+}{
+	SkipEmptyEmail:       {"empty_email", []error{errEmptyEmail}},
+	SkipMissingAt:        {"missing_at", []error{errMissingAt}},
+	SkipEmptyLocalPart:   {"empty_local_part", []error{errEmptyLocalPart}},
+	SkipEmptyDomain:      {"empty_domain", []error{errEmptyDomain}},
+	SkipMultipleAt:       {"multiple_at", []error{errMultipleAt}},
+	SkipEmailTooLong:     {"email_too_long", []error{ErrEmailTooLong}},
+	SkipInvalidLocalPart: {"invalid_local_part", []error{ErrInvalidLocalPart}},
+	SkipInvalidDomain:    {"invalid_domain", []error{ErrInvalidDomain}},
+	SkipNonASCIIDomain:   {"non_ascii_domain", []error{ErrNonASCIIDomain}},
+	SkipInvalidHostname:  {"invalid_hostname", []error{ErrInvalidHostname}},
+	SkipIPAddressDomain:  {"ip_address_domain", []error{ErrIPAddressDomain}},
+	SkipNoEmailMatch:     {"no_email_match", []error{errNoEmailMatch}},
+	SkipPatternMismatch:  {"pattern_mismatch", []error{ErrEmailPatternMismatch}},
+	SkipFieldCount:       {"field_count", []error{csv.ErrFieldCount, errTooFewColumns}},
+	SkipEmptyCustomerID:  {"empty_customer_id", []error{errEmptyCustomerID}},
+	SkipInvalidWeight:    {"invalid_weight", []error{errInvalidWeight}},
+	SkipInvalidDate:      {"invalid_date", []error{errInvalidDate}},
+	SkipInvalidIP:        {"invalid_ip", []error{errInvalidIP}},
+	SkipRejected:         {"rejected", []error{ErrRowRejected}},
+	SkipOther:            {"other", nil},
+}
+
+// String returns the snake_case name of the reason, e.g. "missing_at".
+func (r SkipReason) String() string {
+	if r < 0 || r >= skipReasonCount {
+		return fmt.Sprintf("SkipReason(%d)", int(r))
+	}
+	return skipReasons[r].name
+}
+
+// MarshalText encodes the reason as its name.
+func (r SkipReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// skipReason classifies a row error. A row validator error is SkipRejected even when it wraps
+// one of the other errors.
+func skipReason(err error) SkipReason {
+	if errors.Is(err, ErrRowRejected) {
+		return SkipRejected
+	}
+	for reason, kind := range skipReasons {
+		for _, target := range kind.errs {
+			if errors.Is(err, target) {
+				return SkipReason(reason)
+			}
+		}
+	}
+	return SkipOther
+}
+
+// SkipReasonCounts counts skipped rows by SkipReason, indexed by the reason.
+type SkipReasonCounts [skipReasonCount]uint64
+
+// ReasonCount is the number of rows skipped for one reason.
+type ReasonCount struct {
+	Reason SkipReason `json:"reason"`
+	Rows   uint64     `json:"rows"`
+}
+
+// Top returns up to n reasons with skipped rows, most frequent first, ties in SkipReason order.
+func (c SkipReasonCounts) Top(n int) []ReasonCount {
+	top := []ReasonCount{}
+	for reason, rows := range c {
+		if rows > 0 {
+			top = append(top, ReasonCount{Reason: SkipReason(reason), Rows: rows})
+		}
+	}
+	slices.SortStableFunc(top, func(l, r ReasonCount) int {
+		return cmp.Compare(r.Rows, l.Rows)
+	})
+	return top[:min(n, len(top))]
+}
+
+// healthTopReasons is the number of skip reasons listed in a HealthReport.
+const healthTopReasons = 5
+
+// HealthReport summarizes the data quality of an import for hand-offs to data teams.
+type HealthReport struct {
+	// Rows is the number of data rows read.
+	Rows uint64 `json:"rows"`
+	// ValidRows is the number of rows validated and counted, i.e. neither skipped as invalid nor
+	// left out by WithSampling or WithRowFilter.
+	ValidRows uint64 `json:"valid_rows"`
+	// InvalidRows is the number of rows skipped as invalid under ErrorPolicySkip.
+	InvalidRows uint64 `json:"invalid_rows"`
+	// InvalidPercent is InvalidRows as a percentage of ValidRows plus InvalidRows.
+	InvalidPercent float64 `json:"invalid_percent"`
+	// TopInvalidReasons lists the most frequent reasons for invalid rows.
+	TopInvalidReasons []ReasonCount `json:"top_invalid_reasons"`
+	// UniqueDomains is the number of aggregated domains.
+	UniqueDomains int `json:"unique_domains"`
+	// TopDomain is the domain with the most customers, the alphabetically first on ties. It is
+	// empty without domains or customers.
+	TopDomain string `json:"top_domain"`
+	// TopDomainCustomers is the number of customers of TopDomain.
+	TopDomainCustomers uint64 `json:"top_domain_customers"`
+}
+
+// NewHealthReport builds the health report of an import from its result and summary.
+func NewHealthReport(data []DomainData, summary Summary) HealthReport {
+	checked := summary.Rows - summary.UnsampledRows - summary.FilteredRows
+	h := HealthReport{
+		Rows:              summary.Rows,
+		ValidRows:         checked - summary.SkippedRows,
+		InvalidRows:       summary.SkippedRows,
+		TopInvalidReasons: summary.SkipReasons.Top(healthTopReasons),
+		UniqueDomains:     len(data),
+	}
+	if checked > 0 {
+		h.InvalidPercent = float64(summary.SkippedRows) / float64(checked) * 100
+	}
+	if top, ok := MaxDomain(data); ok && top.CustomerQuantity > 0 {
+		h.TopDomain, h.TopDomainCustomers = top.Domain, top.CustomerQuantity
+	}
+	return h
+}
+
+// String renders the report as a readable block of "label: value" lines.
+func (h HealthReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "rows: %d\n", h.Rows)
+	fmt.Fprintf(&sb, "valid rows: %d\n", h.ValidRows)
+	fmt.Fprintf(&sb, "invalid rows: %d (%.1f%%)\n", h.InvalidRows, h.InvalidPercent)
+	if len(h.TopInvalidReasons) > 0 {
+		sb.WriteString("top invalid reasons:\n")
+		for _, r := range h.TopInvalidReasons {
+			fmt.Fprintf(&sb, "  %s: %d\n", r.Reason, r.Rows)
+		}
+	}
+	fmt.Fprintf(&sb, "unique domains: %d\n", h.UniqueDomains)
+	if h.TopDomain != "" {
+		fmt.Fprintf(&sb, "most common domain: %s (%d customers)\n", h.TopDomain, h.TopDomainCustomers)
+	}
+	return sb.String()
+}
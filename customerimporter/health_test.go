@@ -0,0 +1,81 @@
+package customerimporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHealthReport(t *testing.T) {
+	content := `first_name,last_name,email
+John,Doe,john@example.com
+Jane,Doe,jane@example.com
+Joe,Doe,joe@other.com
+Ann,Doe,ann.example.com
+Max,Doe,max.other.com
+Eve,Doe,
+Bob,Doe,bob@@example.com
+Too,Short
+Amy,Doe,amy@example.com`
+
+	ci := NewCustomerImporter("", WithErrorPolicy(ErrorPolicySkip))
+	data, summary, err := ci.ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewHealthReport(data, summary)
+	want := HealthReport{
+		Rows:           9,
+		ValidRows:      4,
+		InvalidRows:    5,
+		InvalidPercent: 5.0 / 9 * 100,
+		TopInvalidReasons: []ReasonCount{
+			{Reason: SkipMissingAt, Rows: 2},
+			{Reason: SkipEmptyEmail, Rows: 1},
+			{Reason: SkipMultipleAt, Rows: 1},
+			{Reason: SkipFieldCount, Rows: 1},
+		},
+		UniqueDomains:      2,
+		TopDomain:          "example.com",
+		TopDomainCustomers: 3,
+	}
+	if fmt.Sprintf("%+v", report) != fmt.Sprintf("%+v", want) {
+		t.Errorf("report = %+v\nwant %+v", report, want)
+	}
+
+	text := report.String()
+	for _, line := range []string{"invalid rows: 5 (55.6%)\n", "  missing_at: 2\n", "most common domain: example.com (3 customers)\n"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("text report lacks %q:\n%s", line, text)
+		}
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"top_invalid_reasons":[{"reason":"missing_at","rows":2},`) {
+		t.Errorf("JSON report lacks the named top reason: %s", encoded)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want SkipReason
+	}{
+		{fmt.Errorf("invalid email in CSV: %w", errEmptyDomain), SkipEmptyDomain},
+		{fmt.Errorf("%w %q: label %q starts with a hyphen", ErrInvalidDomain, "-a.com", "-a"), SkipInvalidDomain},
+		{fmt.Errorf("%w: %w", ErrRowRejected, ErrInvalidDomain), SkipRejected},
+		{errors.New("count for domain overflows uint64"), SkipOther},
+	}
+	for _, tt := range tests {
+		if got := skipReason(tt.err); got != tt.want {
+			t.Errorf("skipReason(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
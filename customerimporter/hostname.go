@@ -0,0 +1,63 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ErrInvalidHostname is wrapped by the row error of a domain that WithHostnameValidation finds
+// to be no syntactically valid hostname.
+var ErrInvalidHostname = errors.New("not a valid hostname")
+
+// ErrIPAddressDomain is wrapped by the row error of a domain that WithHostnameValidation finds
+// to be an IP address literal, such as "192.168.1.1" or "[::1]".
+var ErrIPAddressDomain = errors.New("is an IP address")
+
+// checkHostname rejects a domain that is an IP address or not a hostname in the RFC 1123 sense
+// when ValidateHostnames is set. No lookup is made: only the syntax is checked.
+func (ci CustomerImporter) checkHostname(domain string) error {
+	if !ci.config.ValidateHostnames {
+		return nil
+	}
+	if _, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(domain, "["), "]")); err == nil {
+		return fmt.Errorf("invalid domain %q: %w", domain, ErrIPAddressDomain)
+	}
+	return validateHostname(domain)
+}
+
+// validateHostname checks domain against hostname syntax: at most 253 characters of
+// dot-separated labels of 1 to 63 ASCII letters, digits and hyphens, neither starting nor
+// ending with a hyphen, and a top-level label that is not all digits.
+func validateHostname(domain string) error {
+	if len(domain) > maxDomainLength {
+		return fmt.Errorf("invalid domain %q: %w: length %d exceeds %d characters", domain, ErrInvalidHostname, len(domain), maxDomainLength)
+	}
+	numeric := false
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" {
+			return fmt.Errorf("invalid domain %q: %w: empty label", domain, ErrInvalidHostname)
+		}
+		if len(label) > maxLabelLength {
+			return fmt.Errorf("invalid domain %q: %w: label %q exceeds %d characters", domain, ErrInvalidHostname, label, maxLabelLength)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid domain %q: %w: label %q starts or ends with a hyphen", domain, ErrInvalidHostname, label)
+		}
+		numeric = true
+		for i := 0; i < len(label); i++ {
+			switch c := label[i]; {
+			case '0' <= c && c <= '9':
+			case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return fmt.Errorf("invalid domain %q: %w: label %q contains %q", domain, ErrInvalidHostname, label, c)
+			}
+		}
+	}
+	if numeric {
+		return fmt.Errorf("invalid domain %q: %w: top-level label is numeric", domain, ErrInvalidHostname)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckHostname(t *testing.T) {
+	ci := NewCustomerImporter("", WithHostnameValidation())
+	tests := []struct {
+		domain string
+		want   error
+	}{
+		{domain: "example.com"},
+		{domain: "mail-1.example.co.uk"},
+		{domain: "xn--bcher-kva.de"},
+		{domain: "exam_ple.com", want: ErrInvalidHostname},
+		{domain: "_dmarc.example.com", want: ErrInvalidHostname},
+		{domain: "bücher.de", want: ErrInvalidHostname},
+		{domain: "-foo.com", want: ErrInvalidHostname},
+		{domain: "foo..com", want: ErrInvalidHostname},
+		{domain: "example.123", want: ErrInvalidHostname},
+		{domain: "999.1.1.1", want: ErrInvalidHostname},
+		{domain: "192.168.1.1", want: ErrIPAddressDomain},
+		{domain: "[10.0.0.1]", want: ErrIPAddressDomain},
+		{domain: "[::1]", want: ErrIPAddressDomain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			err := ci.checkHostname(tt.domain)
+			if tt.want == nil && err != nil {
+				t.Fatalf("checkHostname(%q) unexpected error: %v", tt.domain, err)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("checkHostname(%q) = %v, want an error wrapping %v", tt.domain, err, tt.want)
+			}
+		})
+	}
+
+	if err := NewCustomerImporter("").checkHostname("exam_ple.com"); err != nil {
+		t.Errorf("checkHostname without the option = %v, want nil", err)
+	}
+}
+
+func TestImportHostnameValidation(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Jane,Doe,jane@exam_ple.com,Female,192.168.1.2
+Joe,Doe,joe@192.168.1.3,Male,192.168.1.3
+Jim,Doe,jim@[::1],Male,192.168.1.4`
+
+	_, _, err := NewCustomerImporter("", WithHostnameValidation()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if !errors.Is(err, ErrInvalidHostname) || !strings.Contains(err.Error(), "exam_ple.com") {
+		t.Errorf("expected invalid hostname error for exam_ple.com, got %v", err)
+	}
+
+	data, summary, err := NewCustomerImporter("", WithHostnameValidation(), WithErrorPolicy(ErrorPolicySkip)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].Domain != "example.com" || summary.SkippedRows != 3 {
+		t.Errorf("got %+v with %d skipped rows, want only example.com and 3 skipped rows", data, summary.SkippedRows)
+	}
+	if got := summary.SkipReasons[SkipInvalidHostname]; got != 1 {
+		t.Errorf("skipped %d rows as %v, want 1", got, SkipInvalidHostname)
+	}
+	if got := summary.SkipReasons[SkipIPAddressDomain]; got != 2 {
+		t.Errorf("skipped %d rows as %v, want 2", got, SkipIPAddressDomain)
+	}
+}
@@ -0,0 +1,262 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// incrementalState is the content of a WithIncrementalState file.
+type incrementalState struct {
+	// Key is the hex SHA-256 hash of the Config.String and CacheKey parts of the runs that
+	// produced the state.
+	Key string
+	// Path is the absolute path of the input.
+	Path string
+	// Header is the first line of the input, including its line break.
+	Header []byte
+	// Offset is the byte offset up to which the input was read, always just after a line break.
+	Offset int64
+	// Prefix is the SHA-256 hash of the first Offset bytes of the input.
+	Prefix  []byte
+	Data    []DomainData
+	Summary Summary
+}
+
+// importIncremental is ImportWithSummary under WithIncrementalState: it reads the input from
+// the offset of the stored state, or from the start without a usable one, up to its last line
+// break, and merges the result with the stored counts. Without CacheKey parts describing a
+// function option that changes the result, the whole input is imported without any state.
+func (ci CustomerImporter) importIncremental(ctx context.Context) ([]DomainData, Summary, error) {
+	switch {
+	case ci.config.Deduplicate:
+		return nil, Summary{}, errors.New("incremental state cannot be combined with deduplication")
+	case ci.config.SketchTopK != 0:
+		return nil, Summary{}, errors.New("incremental state cannot be combined with a count-min sketch")
+	case ci.config.CacheFile != "":
+		return nil, Summary{}, errors.New("incremental state cannot be combined with an aggregation cache")
+	}
+	if isTar, _ := tarFormat(ci.path); isTar {
+		return nil, Summary{}, errors.New("incremental state cannot be used with a tar archive input")
+	}
+	if ci.config.unkeyedFuncs() {
+		slog.Warn("function options are set without a cache key, reading input without incremental state",
+			"state", ci.config.StateFile)
+		stateless := ci
+		stateless.config.StateFile = ""
+		return stateless.ImportWithSummary(ctx)
+	}
+
+	file, err := os.Open(ci.path)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	end, err := lastLineEnd(file, info.Size())
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	header, err := bufio.NewReader(io.NewSectionReader(file, 0, end)).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, Summary{}, err
+	}
+
+	path, err := filepath.Abs(ci.path)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+
+	var input io.Reader = io.NewSectionReader(file, 0, end)
+	prior, prefix, resumed := ci.readState(file, path, header, end)
+	if resumed {
+		slog.Info("resuming import from state", "state", ci.config.StateFile, "offset", prior.Offset,
+			"new_bytes", end-prior.Offset)
+		// The header is read again so the tail is parsed with the same columns
+		input = io.MultiReader(bytes.NewReader(header), io.NewSectionReader(file, prior.Offset, end-prior.Offset))
+	}
+	data, summary, err := ci.importWith(func() (*importRun, error) {
+		return ci.aggregate(ctx, input)
+	})
+	if err != nil && !isPartial(err) {
+		return data, summary, err
+	}
+	if resumed {
+		var mergeErr error
+		if data, summary, mergeErr = mergeIncremental(prior, data, summary, ci.config.PreferLastSeen); mergeErr != nil {
+			return nil, summary, mergeErr
+		}
+	}
+	if err != nil {
+		// Partial results are never recorded, so the next run reads the same rows again
+		return data, summary, err
+	}
+
+	// The hash of the rows read before is extended by those read in this run
+	if _, err := io.Copy(prefix, io.NewSectionReader(file, prior.Offset, end-prior.Offset)); err != nil {
+		slog.Warn("failed to hash input for incremental state", "error", err, "state", ci.config.StateFile)
+		return data, summary, nil
+	}
+	state := incrementalState{
+		Key:     ci.stateKey(),
+		Path:    path,
+		Header:  header,
+		Offset:  end,
+		Prefix:  prefix.Sum(nil),
+		Data:    data,
+		Summary: summary,
+	}
+	if err := ci.writeState(state); err != nil {
+		slog.Warn("failed to write incremental state", "error", err, "state", ci.config.StateFile)
+	}
+	return data, summary, nil
+}
+
+// lastLineEnd returns the offset just after the last line break in the first size bytes of r,
+// or 0 when there is none.
+func lastLineEnd(r io.ReaderAt, size int64) (int64, error) {
+	buf := make([]byte, 32*1024)
+	for end := size; end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		chunk := buf[:end-start]
+		if _, err := r.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to find the last line break: %w", err)
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// readState returns the stored state when it can be resumed on input, the file at path starting
+// with header and read up to end, together with the hash of input up to the state's offset. Any
+// other state is treated as absent, returned with an empty hash, and replaced after the import.
+func (ci CustomerImporter) readState(input io.ReaderAt, path string, header []byte, end int64) (incrementalState, hash.Hash, bool) {
+	prefix := sha256.New()
+	file, err := os.Open(ci.config.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return incrementalState{}, prefix, false
+	}
+	if err != nil {
+		slog.Warn("failed to open incremental state", "error", err, "state", ci.config.StateFile)
+		return incrementalState{}, prefix, false
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var state incrementalState
+	if err := gob.NewDecoder(file).Decode(&state); err != nil {
+		slog.Warn("ignoring unreadable incremental state", "error", err, "state", ci.config.StateFile)
+		return incrementalState{}, prefix, false
+	}
+	switch {
+	case state.Key != ci.stateKey():
+		slog.Info("configuration changed since the incremental state was written, re-reading input", "state", ci.config.StateFile)
+		return incrementalState{}, prefix, false
+	case state.Path != path:
+		slog.Info("incremental state was written for another input, re-reading input", "state", ci.config.StateFile,
+			"state_input", state.Path)
+		return incrementalState{}, prefix, false
+	case !bytes.Equal(state.Header, header) || state.Offset > end:
+		slog.Info("input was replaced since the incremental state was written, re-reading input", "state", ci.config.StateFile)
+		return incrementalState{}, prefix, false
+	}
+	if _, err := io.Copy(prefix, io.NewSectionReader(input, 0, state.Offset)); err != nil {
+		slog.Warn("failed to hash input for incremental state", "error", err, "state", ci.config.StateFile)
+		return incrementalState{}, sha256.New(), false
+	}
+	if !bytes.Equal(prefix.Sum(nil), state.Prefix) {
+		slog.Info("rows read before changed since the incremental state was written, re-reading input", "state", ci.config.StateFile)
+		return incrementalState{}, sha256.New(), false
+	}
+	return state, prefix, true
+}
+
+// stateKey returns the Key of a state written under the configuration of ci.
+func (ci CustomerImporter) stateKey() string {
+	h := sha256.New()
+	writePart(h, ci.config.String())
+	for _, part := range ci.config.CacheKey {
+		writePart(h, part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeState replaces the state file with state. It is written to a temporary file renamed over
+// the previous state, so an interrupted write keeps the previous state intact.
+func (ci CustomerImporter) writeState(state incrementalState) error {
+	path := ci.config.StateFile
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(file).Encode(state); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("failed to encode incremental state: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// mergeIncremental adds the domains and summary of the rows read in this run to those of the
+// previous runs. A domain keeps its earlier sample email unless preferLast is set. It fails when
+// a count overflows.
+func mergeIncremental(prior incrementalState, data []DomainData, summary Summary, preferLast bool) ([]DomainData, Summary, error) {
+	merged := make(map[string]DomainData, len(prior.Data)+len(data))
+	for _, d := range prior.Data {
+		merged[d.Domain] = d
+	}
+	for _, d := range data {
+		m, ok := merged[d.Domain]
+		if !ok {
+			merged[d.Domain] = d
+			continue
+		}
+		if m.CustomerQuantity > math.MaxUint64-d.CustomerQuantity {
+			return nil, summary, fmt.Errorf("count for domain %q overflows uint64", d.Domain)
+		}
+		m.CustomerQuantity += d.CustomerQuantity
+		m.RoleAccounts += d.RoleAccounts
+		if m.SampleEmail == "" || (preferLast && d.SampleEmail != "") {
+			m.SampleEmail = d.SampleEmail
+		}
+		merged[d.Domain] = m
+	}
+
+	result := make([]DomainData, 0, len(merged))
+	total := prior.Summary
+	total.add(summary)
+	total.Customers = 0
+	for _, d := range merged {
+		result = append(result, d)
+		total.Customers += d.CustomerQuantity
+	}
+	total.Domains = len(result)
+	slices.SortFunc(result, compareDomain)
+	return result, total, nil
+}
@@ -0,0 +1,204 @@
+package customerimporter
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithIncrementalState(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "customers.csv")
+	content := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,john@gmail.com,Male,192.168.1.1\n" +
+		"Jane,Doe,jane@acme.com,Female,192.168.1.2\n"
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	sink := &fakeSink{}
+	ci := NewCustomerImporter(csvPath, WithIncrementalState(filepath.Join(dir, "state.gob")), WithSampleEmail(), WithMetrics(sink))
+
+	if _, _, err := ci.ImportWithSummary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The appended tail ends in a row still being written, left for a later run
+	content += "Max,Roe,max@gmail.com,Male,192.168.1.3\n" +
+		"Ann,Poe,ann@delta.io,Female,192.168.1.4\n" +
+		"Kim,Lee,kim@acme"
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	got, summary, err := ci.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "acme.com", CustomerQuantity: 1, SampleEmail: "jane@acme.com"},
+		{Domain: "delta.io", CustomerQuantity: 1, SampleEmail: "ann@delta.io"},
+		{Domain: "gmail.com", CustomerQuantity: 2, SampleEmail: "john@gmail.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("second run = %+v, want %+v", got, want)
+	}
+	if summary.Rows != 4 || summary.Domains != 3 || summary.Customers != 4 {
+		t.Errorf("second run summary = %+v, want 4 rows of 3 domains", summary)
+	}
+	if rows := sink.runs[len(sink.runs)-1].Rows; rows != 2 {
+		t.Errorf("second run read %d rows, want only the 2 appended", rows)
+	}
+
+	// Completing the last row makes it count on the next run
+	if err := writeTestCSV(csvPath, content+".com,Female,192.168.1.5\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	got, _, err = ci.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].CustomerQuantity != 2 {
+		t.Errorf("third run = %+v, want acme.com counted twice", got)
+	}
+}
+
+func TestWithIncrementalStateRestarts(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "customers.csv")
+	statePath := filepath.Join(dir, "state.gob")
+	header := "first_name,last_name,email,gender,ip_address\n"
+	if err := writeTestCSV(csvPath, header+"John,Doe,john@gmail.com,Male,192.168.1.1\nJane,Doe,jane@acme.com,Female,192.168.1.2\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	if _, _, err := NewCustomerImporter(csvPath, WithIncrementalState(statePath)).ImportWithSummary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		opts    []Option
+		want    []DomainData
+	}{
+		{
+			name:    "rotated file",
+			content: header + "Max,Roe,max@beta.org,Male,192.168.1.3\n",
+			want:    []DomainData{{Domain: "beta.org", CustomerQuantity: 1}},
+		},
+		{
+			// Same length and header, so only the hash of the rows read before tells
+			name:    "rewritten row",
+			content: header + "John,Doe,john@gmail.com,Male,192.168.1.1\nJane,Doe,jane@beta.org,Female,192.168.1.2\n",
+			want: []DomainData{
+				{Domain: "beta.org", CustomerQuantity: 1},
+				{Domain: "gmail.com", CustomerQuantity: 1},
+			},
+		},
+		{
+			name:    "changed configuration",
+			content: header + "John,Doe,john@gmail.com,Male,192.168.1.1\nJane,Doe,jane@acme.com,Female,192.168.1.2\n",
+			opts:    []Option{WithCategory()},
+			want: []DomainData{
+				{Domain: "acme.com", CustomerQuantity: 1, Category: CategoryCorporate},
+				{Domain: "gmail.com", CustomerQuantity: 1, Category: CategoryFreemail},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := writeTestCSV(csvPath, tt.content); err != nil {
+				t.Fatalf("failed to write test CSV: %v", err)
+			}
+			ci := NewCustomerImporter(csvPath, append(tt.opts, WithIncrementalState(statePath))...)
+			got, _, err := ci.ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImportWithSummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	_, _, err := NewCustomerImporter(csvPath, WithIncrementalState(statePath), WithDeduplication()).ImportWithSummary(context.Background())
+	if err == nil {
+		t.Error("ImportWithSummary() with deduplication succeeded, want an error")
+	}
+	if _, err := os.Stat(statePath); err != nil {
+		t.Errorf("state file missing: %v", err)
+	}
+}
+
+func TestWithIncrementalStateOtherInput(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.gob")
+	header := "first_name,last_name,email,gender,ip_address\n"
+	today := filepath.Join(dir, "today.csv")
+	if err := writeTestCSV(today, header+"John,Doe,john@t.com,Male,192.168.1.1\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	if _, _, err := NewCustomerImporter(today, WithIncrementalState(statePath)).ImportWithSummary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another file with the same header and a longer body must not resume at today's offset
+	yesterday := filepath.Join(dir, "yesterday.csv")
+	if err := writeTestCSV(yesterday, header+"Jane,Roe,jane@y.com,Female,192.168.1.2\nMax,Roe,max@y.com,Male,192.168.1.3\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	got, summary, err := NewCustomerImporter(yesterday, WithIncrementalState(statePath)).ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{{Domain: "y.com", CustomerQuantity: 2}}
+	if !reflect.DeepEqual(got, want) || summary.Rows != 2 {
+		t.Errorf("ImportWithSummary() = %+v with %d rows, want %+v with 2 rows", got, summary.Rows, want)
+	}
+}
+
+func TestWithIncrementalStateFilter(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "customers.csv")
+	statePath := filepath.Join(dir, "state.gob")
+	content := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,john@github.io,Male,192.168.1.1\n" +
+		"Jane,Doe,jane@hubpages.com,Female,192.168.1.2\n" +
+		"Max,Roe,max@cnet.com,Male,192.168.1.3\n"
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	importFiltered := func(substr string, opts ...Option) []DomainData {
+		t.Helper()
+		opts = append(opts, WithIncrementalState(statePath), WithDomainFilter(DomainContains(substr)))
+		data, err := NewCustomerImporter(csvPath, opts...).ImportDomainData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	// Without a cache key no state is written
+	importFiltered("hub")
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Fatalf("state written without a cache key: %v", err)
+	}
+
+	importFiltered("hub", WithCacheKey("grep=hub"))
+	if err := writeTestCSV(csvPath, content+"Zoe,Poe,z@cnet.com,Female,192.168.1.4\n"); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	got := importFiltered("cnet", WithCacheKey("grep=cnet"))
+	if want := []DomainData{{Domain: "cnet.com", CustomerQuantity: 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("run with another filter = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeIncrementalOverflow(t *testing.T) {
+	prior := incrementalState{Data: []DomainData{{Domain: "a.com", CustomerQuantity: math.MaxUint64}}}
+	_, _, err := mergeIncremental(prior, []DomainData{{Domain: "a.com", CustomerQuantity: 1}}, Summary{}, false)
+	if err == nil {
+		t.Error("mergeIncremental() error = nil, want an overflow error")
+	}
+}
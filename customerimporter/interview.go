@@ -17,14 +17,20 @@
 package customerimporter
 
 import (
-	"cmp"
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -32,6 +38,28 @@ const (
 	emailColumnIndex = 2
 )
 
+// ErrPartialResult is wrapped by import errors that are returned together with the domains
+// aggregated so far, e.g. when the import context is cancelled.
+var ErrPartialResult = errors.New("partial result")
+
+// ErrCountOverflow is returned when a count does not fit the signed 64-bit range required by
+// consumers that cannot represent uint64 values.
+var ErrCountOverflow = errors.New("count overflows int64")
+
+// isPartial reports whether err accompanies a usable partial result.
+func isPartial(err error) bool {
+	return errors.Is(err, ErrPartialResult)
+}
+
+// Errors of validateEmail, classified by skipReason.
+var (
+	errEmptyEmail     = errors.New("email address is empty")
+	errMissingAt      = errors.New("invalid email format: missing '@' separator")
+	errEmptyLocalPart = errors.New("invalid email format: empty local part")
+	errEmptyDomain    = errors.New("invalid email format: empty domain")
+	errMultipleAt     = errors.New("invalid email format: multiple '@' symbols")
+)
+
 // validateEmail validates email format and extracts the domain.
 // Returns the domain and an error if the email is invalid.
 // Valid email format: local-part@domain
@@ -41,60 +69,136 @@ func validateEmail(email string) (domain string, err error) {
 	email = strings.TrimSpace(email)
 
 	if email == "" {
-		return "", fmt.Errorf("email address is empty")
+		return "", errEmptyEmail
 	}
 
-	// Split email into local and domain parts
-	local, dom, found := strings.Cut(email, "@")
-	if !found {
-		return "", fmt.Errorf("invalid email format: missing '@' separator")
+	// Find the first '@' in one scan. As email is trimmed, the local part before it is only blank
+	// when it is empty, and the domain after it only needs trimming on the left.
+	at := strings.IndexByte(email, '@')
+	if at < 0 {
+		return "", errMissingAt
 	}
-
-	// Validate local part is not empty
-	if strings.TrimSpace(local) == "" {
-		return "", fmt.Errorf("invalid email format: empty local part")
+	if at == 0 {
+		return "", errEmptyLocalPart
 	}
 
-	// Validate domain is not empty
-	dom = strings.TrimSpace(dom)
+	dom := trimLeftSpace(email[at+1:])
 	if dom == "" {
-		return "", fmt.Errorf("invalid email format: empty domain")
+		return "", errEmptyDomain
 	}
 
-	// Check for multiple @ symbols (strings.Cut only finds the first one)
-	if strings.Contains(dom, "@") {
-		return "", fmt.Errorf("invalid email format: multiple '@' symbols")
+	// Check for multiple @ symbols
+	if strings.IndexByte(dom, '@') >= 0 {
+		return "", errMultipleAt
 	}
 
 	return dom, nil
 }
 
+// trimLeftSpace is strings.TrimLeftFunc(s, unicode.IsSpace) without decoding runes while s starts
+// with ASCII.
+func trimLeftSpace(s string) string {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= utf8.RuneSelf:
+			return strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+		case c != ' ' && (c < '\t' || c > '\r'):
+			return s[i:]
+		}
+	}
+	return ""
+}
+
 // DomainData represents aggregated customer statistics for a single email domain.
 type DomainData struct {
 	// Domain is the email domain (e.g., "example.com")
 	Domain string
 	// CustomerQuantity is the number of customers with email addresses at this domain
 	CustomerQuantity uint64
+	// SampleEmail is the first email address seen for this domain, or the last one with
+	// WithPreferLastSeen, normalized like the domain. It is only populated with WithSampleEmail.
+	SampleEmail string
+	// Category classifies the domain as freemail, corporate or unknown.
+	// It is only populated with WithCategory.
+	Category Category
+	// RoleAccounts is how many of the customers use a role account such as "info@" (see
+	// RoleLocalParts). It is only populated with WithRoleAccounts.
+	RoleAccounts uint64
+}
+
+// String renders d as "domain=example.com customers=42" for logs and debugging output, followed
+// by "sample_email=...", "category=..." and "role_accounts=..." when those are set.
+func (d DomainData) String() string {
+	s := "domain=" + d.Domain + " customers=" + strconv.FormatUint(d.CustomerQuantity, 10)
+	if d.SampleEmail != "" {
+		s += " sample_email=" + d.SampleEmail
+	}
+	if d.Category != "" {
+		s += " category=" + string(d.Category)
+	}
+	if d.RoleAccounts != 0 {
+		s += " role_accounts=" + strconv.FormatUint(d.RoleAccounts, 10)
+	}
+	return s
+}
+
+// LogValue implements slog.LogValuer so that d is logged as a group of its fields, e.g.
+// "data.domain=example.com data.customers=42" with a text handler, rather than as a struct dump.
+func (d DomainData) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("domain", d.Domain),
+		slog.Uint64("customers", d.CustomerQuantity),
+	}
+	if d.SampleEmail != "" {
+		attrs = append(attrs, slog.String("sample_email", d.SampleEmail))
+	}
+	if d.Category != "" {
+		attrs = append(attrs, slog.String("category", string(d.Category)))
+	}
+	if d.RoleAccounts != 0 {
+		attrs = append(attrs, slog.Uint64("role_accounts", d.RoleAccounts))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// SignedQuantity returns CustomerQuantity as an int64 for consumers that expect signed integers,
+// or an error wrapping ErrCountOverflow when it exceeds math.MaxInt64.
+func (d DomainData) SignedQuantity() (int64, error) {
+	if d.CustomerQuantity > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: domain %q has %d customers", ErrCountOverflow, d.Domain, d.CustomerQuantity)
+	}
+	return int64(d.CustomerQuantity), nil
 }
 
 // CustomerImporter processes customer CSV files and aggregates domain statistics.
 type CustomerImporter struct {
-	path string
+	path   string
+	config Config
 }
 
 // NewCustomerImporter creates a new CustomerImporter that will read from the specified CSV file path.
 //
 // The filePath should point to a valid CSV file with customer data. The file is not opened or validated
 // until ImportDomainData is called.
-func NewCustomerImporter(filePath string) *CustomerImporter {
-	return &CustomerImporter{
+func NewCustomerImporter(filePath string, opts ...Option) *CustomerImporter {
+	ci := &CustomerImporter{
 		path: filePath,
 	}
+	for _, opt := range opts {
+		opt(&ci.config)
+	}
+	return ci
+}
+
+// Config returns the effective configuration after all options were applied.
+func (ci CustomerImporter) Config() Config {
+	return ci.config
 }
 
 // ImportDomainData reads customer data from the CSV file and returns aggregated domain statistics.
 //
-// The CSV file must have a header row and at least 3 columns, with the email address in the 3rd column (index 2).
+// The CSV file must have a header row and at least 3 columns, with the email address in the 3rd column (index 2)
+// unless other columns are configured with WithEmailColumns.
 // Expected CSV format:
 //
 //	first_name,last_name,email,gender,ip_address
@@ -111,52 +215,308 @@ func NewCustomerImporter(filePath string) *CustomerImporter {
 //
 // When verbose logging is enabled (via slog), progress is logged every 10,000 rows.
 func (ci CustomerImporter) ImportDomainData() ([]DomainData, error) {
+	return ci.ImportDomainDataContext(context.Background())
+}
+
+// ImportDomainDataContext is like ImportDomainData but stops reading as soon as ctx is done.
+//
+// On cancellation it returns the domains aggregated so far, sorted as usual, together with an
+// error wrapping both ErrPartialResult and ctx.Err(), so callers can still flush what was read.
+func (ci CustomerImporter) ImportDomainDataContext(ctx context.Context) ([]DomainData, error) {
+	data, _, err := ci.ImportWithSummary(ctx)
+	return data, err
+}
+
+// ImportWithSummary is like ImportDomainDataContext and additionally returns statistics about
+// the processed input. The summary is also populated for partial results.
+//
+// Under WithAggregationCache a result cached for the same input and configuration is returned
+// without reading the CSV, and under WithIncrementalState only the rows appended since the
+// previous run are read.
+func (ci CustomerImporter) ImportWithSummary(ctx context.Context) ([]DomainData, Summary, error) {
+	if ci.config.StateFile != "" {
+		return ci.importIncremental(ctx)
+	}
+	if ci.config.CacheFile != "" {
+		uncached := ci
+		uncached.config.CacheFile = ""
+		return ci.importCached(func() ([]DomainData, Summary, error) {
+			return uncached.ImportWithSummary(ctx)
+		})
+	}
 	file, err := os.Open(ci.path)
 	if err != nil {
-		return nil, err
+		return nil, Summary{}, err
 	}
 	defer func() {
 		_ = file.Close()
 	}()
-	csvReader := csv.NewReader(file)
-	data := make(map[string]uint64)
+	return ci.importWith(func() (*importRun, error) {
+		return ci.aggregateFile(ctx, file)
+	})
+}
+
+// ImportReader is like ImportWithSummary but reads the CSV from r instead of the configured path.
+//
+// r may be a streaming source such as a pipe or FIFO: reads block until the producer writes more
+// data, and the import completes when the producer closes its end (EOF). Use WithReadTimeout to
+// bound how long a slow or stalled producer may block the import.
+func (ci CustomerImporter) ImportReader(ctx context.Context, r io.Reader) ([]DomainData, Summary, error) {
+	return ci.importFrom(ctx, r)
+}
+
+// importFrom is the reader-based core shared by all import entry points returning a slice.
+func (ci CustomerImporter) importFrom(ctx context.Context, r io.Reader) ([]DomainData, Summary, error) {
+	return ci.importWith(func() (*importRun, error) {
+		return ci.aggregate(ctx, r)
+	})
+}
+
+// importWith turns the run returned by aggregate into the sorted result, recording metrics.
+func (ci CustomerImporter) importWith(aggregate func() (*importRun, error)) (data []DomainData, summary Summary, err error) {
+	start := time.Now()
+	defer func() {
+		ci.recordMetrics(start, summary, err)
+	}()
+
+	run, err := aggregate()
+	if run == nil {
+		return nil, Summary{}, err
+	}
+	defer run.release()
+	if err != nil && !isPartial(err) {
+		return nil, run.summary, err
+	}
+	data, summary, resultErr := run.result()
+	if resultErr != nil {
+		return nil, summary, resultErr
+	}
+	if err == nil {
+		logComplete(summary)
+	}
+	return data, summary, err
+}
+
+// aggregateFile is aggregate for file, opened from the configured path, in two passes under
+// WithTwoPassAggregation.
+func (ci CustomerImporter) aggregateFile(ctx context.Context, file io.ReadSeeker) (*importRun, error) {
+	if ci.config.TwoPass {
+		return ci.aggregateTwoPass(ctx, file)
+	}
+	return ci.aggregatePath(ctx, file)
+}
+
+// aggregatePath reads file once. A configured path ending in .tar, .tar.gz or .tgz is read as
+// an archive: its .csv members, each with its own header row, are aggregated into a single
+// result and other members are skipped.
+func (ci CustomerImporter) aggregatePath(ctx context.Context, file io.Reader) (*importRun, error) {
+	if isTar, gzipped := tarFormat(ci.path); isTar {
+		return ci.aggregateTar(ctx, file, gzipped)
+	}
+	return ci.aggregate(ctx, file)
+}
+
+// aggregate reads r into a new importRun. On cancellation it returns the run counted so far
+// with an error wrapping ErrPartialResult; on other errors the run, if any, is only good for
+// its summary. The returned run is always closed.
+func (ci CustomerImporter) aggregate(ctx context.Context, r io.Reader) (*importRun, error) {
+	ctx, cancel := ci.withMaxDuration(ctx)
+	defer cancel()
+
+	progress := newProgress()
+	records, header, err := ci.openRecords(progress.count(r))
+	if err != nil {
+		return nil, err
+	}
+	run, err := ci.newImportRun(header)
+	if err != nil {
+		return nil, err
+	}
+	run.progress = progress
+	defer func() {
+		_ = run.close()
+	}()
+
+	if err := run.consume(ctx, records); err != nil {
+		return run, err
+	}
+	return run, run.finish()
+}
+
+// openRecords returns the record reader for r after reading, and optionally validating, its
+// header row.
+func (ci CustomerImporter) openRecords(r io.Reader) (recordReader, []string, error) {
+	if ci.config.ReadTimeout > 0 {
+		r = newTimeoutReader(r, ci.config.ReadTimeout)
+	}
+	records, err := ci.newRecordReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// skip first line with headers
-	_, readErr := csvReader.Read()
+	header, readErr := records.Read()
 	if readErr != nil {
 		slog.Error("failed to read CSV header", "error", readErr)
-		return nil, readErr
+		return nil, nil, readErr
 	}
+	if ci.config.ValidateHeader {
+		if err := ci.validateHeader(header); err != nil {
+			return nil, nil, err
+		}
+	}
+	return records, header, nil
+}
 
-	rowCount := uint64(0)
-	const progressInterval = 10000
+// consume counts the data rows of records into the run until EOF or ctx is done.
+func (r *importRun) consume(ctx context.Context, records recordReader) error {
+	done := ctx.Done()
 
-	for line, readErr := csvReader.Read(); readErr != io.EOF; line, readErr = csvReader.Read() {
-		if readErr != nil {
-			return nil, readErr
+	for line, readErr := records.Read(); readErr != io.EOF; line, readErr = records.Read() {
+		select {
+		case <-done:
+			slog.Info("import interrupted", "rows", r.summary.Rows, "unique_domains", r.data.len())
+			return fmt.Errorf("%w after %d rows: %w", ErrPartialResult, r.summary.Rows, contextError(ctx))
+		default:
+		}
+		// Rows with a wrong number of fields are still returned and may be skipped by policy
+		if readErr != nil && !errors.Is(readErr, csv.ErrFieldCount) {
+			return readErr
+		}
+		if r.isRepeatedHeader(line) {
+			r.summary.RepeatedHeaders++
+			slog.Debug("skipping repeated header", "after_row", r.summary.Rows)
+			continue
+		}
+		r.summary.Rows++
+
+		// Log progress every 10k rows, with the throughput since the previous line
+		if r.summary.Rows%progressInterval == 0 {
+			rowsPerSec, mbPerSec := r.progress.rate(r.summary.Rows, time.Now())
+			slog.Info("processing", "rows", r.summary.Rows, "unique_domains", r.data.len(),
+				"rows_per_sec", rowsPerSec, "mb_per_sec", mbPerSec)
 		}
-		rowCount++
 
-		// Log progress every 10k rows
-		if rowCount%progressInterval == 0 {
-			slog.Info("processing", "rows", rowCount, "unique_domains", len(data))
+		if !r.sampled() {
+			r.summary.UnsampledRows++
+			continue
 		}
 
-		// Validate CSV has enough columns
-		if len(line) <= emailColumnIndex {
-			return nil, fmt.Errorf("invalid CSV format: expected at least %d columns, got %d", emailColumnIndex+1, len(line))
+		rowErr := readErr
+		if rowErr == nil {
+			rowErr = r.processRow(line)
 		}
+		if rowErr != nil {
+			if err := r.handleRowError(line, rowErr); err != nil {
+				return err
+			}
+		}
+		if err := r.checkErrorRate(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish applies the final error rate check and closes the run once all input is consumed.
+func (r *importRun) finish() error {
+	if err := r.checkErrorRate(true); err != nil {
+		return err
+	}
+	if r.casings != nil {
+		r.ci.config.CasingReport(r.casings.report())
+	}
+	return r.close()
+}
 
-		// Validate email and extract domain
-		domain, err := validateEmail(line[emailColumnIndex])
-		if err != nil {
-			return nil, fmt.Errorf("invalid email in CSV: %w", err)
+// logComplete logs the outcome of a finished import.
+func logComplete(summary Summary) {
+	slog.Info("aggregation complete", "total_rows", summary.Rows, "unique_domains", summary.Domains,
+		"skipped_rows", summary.SkippedRows)
+	if summary.RepeatedHeaders > 0 {
+		slog.Warn("skipped header rows repeated inside the data; the input looks concatenated",
+			"repeated_headers", summary.RepeatedHeaders)
+	}
+	if summary.TrimmedValues > 0 {
+		slog.Warn("email values required whitespace trimming; consider cleaning the upstream export",
+			"trimmed_values", summary.TrimmedValues)
+	}
+}
+
+// recordReader yields one record per call and io.EOF at the end of the input.
+// *csv.Reader is the default implementation.
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// newRecordReader returns the parsing front-end for r: fixed-width when a layout is configured,
+// CSV otherwise, with the delimiter sniffed from r under WithDelimiterDetection. Raw lines pass
+// through the LinePreprocessor first.
+func (ci CustomerImporter) newRecordReader(r io.Reader) (recordReader, error) {
+	if fn := ci.config.LinePreprocessor; fn != nil {
+		r = newLineReader(r, fn)
+	}
+	if layout := ci.config.FixedWidth; layout != nil {
+		if err := layout.validate(); err != nil {
+			return nil, err
 		}
+		return newFixedWidthReader(r, *layout), nil
+	}
+	delimiter := ','
+	if ci.config.DetectDelimiter {
+		var err error
+		if r, delimiter, err = detectDelimiter(r); err != nil {
+			return nil, err
+		}
+	}
+	csvReader := csv.NewReader(r)
+	csvReader.Comma = delimiter
+	if ci.config.PadShortRows {
+		// Row widths are checked against the header by the padding instead
+		csvReader.FieldsPerRecord = -1
+	}
+	return csvReader, nil
+}
 
-		data[domain]++
+// extractDomain validates email, applies the configured strict checks and returns the
+// normalized domain used as the aggregation key.
+func (ci CustomerImporter) extractDomain(email string) (string, error) {
+	if err := ci.matchEmailPattern(email); err != nil {
+		return "", err
+	}
+	domain, err := validateEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if err := ci.checkASCIIDomain(domain); err != nil {
+		return "", err
+	}
+	if err := ci.checkHostname(domain); err != nil {
+		return "", err
+	}
+	if ci.config.StrictMode {
+		if err := validateStrict(email, domain); err != nil {
+			return "", err
+		}
+		if err := ci.checkLocalPartChars(email); err != nil {
+			return "", err
+		}
+	} else if ci.config.ValidateDomainLabels {
+		if err := validateDomainLabels(domain); err != nil {
+			return "", err
+		}
 	}
+	return ci.normalizeDomain(domain), nil
+}
 
-	slog.Info("aggregation complete", "total_rows", rowCount, "unique_domains", len(data))
+// DomainDataFromCounts converts a domain -> count map into a slice sorted alphabetically by
+// domain, giving a deterministic order regardless of map iteration order.
+func DomainDataFromCounts(counts map[string]uint64) []DomainData {
+	return sortDomainData(counts)
+}
+
+// sortDomainData converts the aggregation map into a slice sorted alphabetically by domain.
+func sortDomainData(data map[string]uint64) []DomainData {
 	domainData := make([]DomainData, 0, len(data))
 	for k, v := range data {
 		domainData = append(domainData, DomainData{
@@ -164,8 +524,6 @@ func (ci CustomerImporter) ImportDomainData() ([]DomainData, error) {
 			CustomerQuantity: v,
 		})
 	}
-	slices.SortFunc(domainData, func(l, r DomainData) int {
-		return cmp.Compare(l.Domain, r.Domain)
-	})
-	return domainData, nil
+	slices.SortFunc(domainData, compareDomain)
+	return domainData
 }
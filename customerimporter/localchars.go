@@ -0,0 +1,60 @@
+package customerimporter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// runeSet is a set of characters given as a list of single characters and ranges, such as
+// "a-z0-9._-".
+type runeSet struct {
+	// ranges holds inclusive [low, high] pairs; a single character is a range of itself.
+	ranges [][2]rune
+}
+
+// parseRuneSet parses spec, where "x-y" denotes the inclusive range from x to y and a '-' at the
+// start or end of spec stands for itself, as in regular expression bracket expressions.
+func parseRuneSet(spec string) (runeSet, error) {
+	if !utf8.ValidString(spec) {
+		return runeSet{}, fmt.Errorf("invalid character set %q: not valid UTF-8", spec)
+	}
+	chars := []rune(spec)
+	var set runeSet
+	for i := 0; i < len(chars); i++ {
+		low, high := chars[i], chars[i]
+		if i+2 < len(chars) && chars[i+1] == '-' {
+			high = chars[i+2]
+			if high < low {
+				return runeSet{}, fmt.Errorf("invalid character set %q: range %c-%c is reversed", spec, low, high)
+			}
+			i += 2
+		}
+		set.ranges = append(set.ranges, [2]rune{low, high})
+	}
+	return set, nil
+}
+
+func (s runeSet) contains(r rune) bool {
+	for _, rng := range s.ranges {
+		if rng[0] <= r && r <= rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLocalPartChars rejects an email whose local part contains a character outside
+// LocalPartAllowedChars. It applies in strict mode only.
+func (ci CustomerImporter) checkLocalPartChars(email string) error {
+	if !ci.config.StrictMode || ci.config.LocalPartAllowedChars == "" {
+		return nil
+	}
+	local, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	for i, r := range local {
+		if !ci.config.localPartChars.contains(r) {
+			return fmt.Errorf("%w %q: character %q at byte %d is not allowed", ErrInvalidLocalPart, local, r, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckLocalPartChars(t *testing.T) {
+	ci := NewCustomerImporter("", WithStrictMode(), WithLocalPartAllowedChars("a-z0-9.-"))
+	tests := []struct {
+		email   string
+		wantErr string
+	}{
+		{email: "john.doe@example.com"},
+		{email: "jane-99@example.com"},
+		{email: "  max@example.com "},
+		{email: "john+news@example.com", wantErr: `character '+' at byte 4`},
+		{email: "John@example.com", wantErr: `character 'J' at byte 0`},
+		{email: "jo_hn@example.com", wantErr: `character '_' at byte 2`},
+		{email: "zoë@example.com", wantErr: `character 'ë' at byte 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := ci.checkLocalPartChars(tt.email)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkLocalPartChars(%q) = %v, want nil", tt.email, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidLocalPart) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkLocalPartChars(%q) = %v, want %v containing %q", tt.email, err, ErrInvalidLocalPart, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewCustomerImporter("", WithLocalPartAllowedChars("a-z")).checkLocalPartChars("john+news@example.com"); err != nil {
+		t.Errorf("checkLocalPartChars without strict mode = %v, want nil", err)
+	}
+}
+
+func TestImportLocalPartAllowedChars(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Jane,Doe,jane+shop@example.com,Female,192.168.1.2
+Joe,Doe,joe_b@other.org,Male,192.168.1.3`
+
+	data, summary, err := NewCustomerImporter("", WithStrictMode(), WithLocalPartAllowedChars("a-z_"), WithErrorPolicy(ErrorPolicySkip)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].CustomerQuantity != 1 || summary.SkipReasons[SkipInvalidLocalPart] != 1 {
+		t.Errorf("got %+v with skip reasons %v, want one customer per domain and jane+shop skipped", data, summary.SkipReasons)
+	}
+
+	_, _, err = NewCustomerImporter("", WithStrictMode(), WithLocalPartAllowedChars("z-a")).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err == nil || !strings.Contains(err.Error(), "range z-a is reversed") {
+		t.Errorf("import with a reversed range = %v, want a reversed range error", err)
+	}
+}
+
+func TestParseRuneSet(t *testing.T) {
+	set, err := parseRuneSet("-a-c.x-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range "-abc.x" {
+		if !set.contains(r) {
+			t.Errorf("set does not contain %q", r)
+		}
+	}
+	for _, r := range "dwyA+" {
+		if set.contains(r) {
+			t.Errorf("set contains %q", r)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package customerimporter
+
+import (
+	"errors"
+	"expvar"
+	"time"
+)
+
+// ImportMetrics are the final counters of one import run, reported to a MetricsSink.
+type ImportMetrics struct {
+	// Rows is the number of data rows read.
+	Rows uint64
+	// Domains is the number of unique domains aggregated.
+	Domains int
+	// Errors is the number of invalid rows: those skipped by policy plus, when an invalid row
+	// aborted the import, that row. Failures of the run itself, such as an unreadable input
+	// or WithMaxErrorRate, only set Failed.
+	Errors uint64
+	// Duration is the wall-clock time spent importing.
+	Duration time.Duration
+	// Failed reports whether the import returned an error, including partial results.
+	Failed bool
+}
+
+// MetricsSink receives the metrics of every import run, e.g. to publish them from a
+// long-running service. RecordImport is called once per run, after it finished or failed.
+type MetricsSink interface {
+	RecordImport(m ImportMetrics)
+}
+
+// ExpvarMetrics is a MetricsSink that publishes cumulative counters into an expvar.Map:
+// "runs", "failed_runs", "rows", "errors" and "duration_ms", plus "last_domains" for the most
+// recent run.
+type ExpvarMetrics struct {
+	m *expvar.Map
+}
+
+// NewExpvarMetrics returns a sink writing into m, typically created once with expvar.NewMap.
+func NewExpvarMetrics(m *expvar.Map) *ExpvarMetrics {
+	return &ExpvarMetrics{m: m}
+}
+
+// RecordImport adds the run's counters to the expvar map.
+func (e *ExpvarMetrics) RecordImport(metrics ImportMetrics) {
+	e.m.Add("runs", 1)
+	if metrics.Failed {
+		e.m.Add("failed_runs", 1)
+	}
+	e.m.Add("rows", int64(metrics.Rows))
+	e.m.Add("errors", int64(metrics.Errors))
+	e.m.Add("duration_ms", metrics.Duration.Milliseconds())
+
+	domains := new(expvar.Int)
+	domains.Set(int64(metrics.Domains))
+	e.m.Set("last_domains", domains)
+}
+
+// recordMetrics reports a finished run to the configured sink, if any.
+func (ci CustomerImporter) recordMetrics(start time.Time, summary Summary, err error) {
+	if ci.config.Metrics == nil {
+		return
+	}
+	metrics := ImportMetrics{
+		Rows:     summary.Rows,
+		Domains:  summary.Domains,
+		Errors:   summary.SkippedRows,
+		Duration: time.Since(start),
+		Failed:   err != nil,
+	}
+	if errors.As(err, new(rowFailure)) {
+		metrics.Errors++
+	}
+	ci.config.Metrics.RecordImport(metrics)
+}
@@ -0,0 +1,90 @@
+package customerimporter
+
+import (
+	"expvar"
+	"testing"
+)
+
+// fakeSink captures every reported metrics value.
+type fakeSink struct {
+	runs []ImportMetrics
+}
+
+func (f *fakeSink) RecordImport(m ImportMetrics) {
+	f.runs = append(f.runs, m)
+}
+
+func TestImportMetrics(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Bad,Row,not-an-email,Male,192.168.1.2
+Jane,Doe,jane@other.org,Female,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	sink := &fakeSink{}
+	if _, err := NewCustomerImporter(csvPath, WithMetrics(sink), WithErrorPolicy(ErrorPolicySkip)).ImportDomainData(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCustomerImporter(csvPath, WithMetrics(sink)).ImportDomainData(); err == nil {
+		t.Fatal("expected abort on invalid row")
+	}
+
+	if len(sink.runs) != 2 {
+		t.Fatalf("sink received %d runs, want 2", len(sink.runs))
+	}
+	ok, failed := sink.runs[0], sink.runs[1]
+	if ok.Rows != 3 || ok.Domains != 2 || ok.Errors != 1 || ok.Failed {
+		t.Errorf("successful run metrics = %+v, want rows=3 domains=2 errors=1 failed=false", ok)
+	}
+	if ok.Duration <= 0 {
+		t.Errorf("successful run duration not recorded: %v", ok.Duration)
+	}
+	if failed.Rows != 2 || failed.Errors != 1 || !failed.Failed {
+		t.Errorf("failed run metrics = %+v, want rows=2 errors=1 failed=true", failed)
+	}
+}
+
+func TestImportMetricsRunFailure(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Bad,Row,not-an-email,Male,192.168.1.2
+Bad,Row,also-not-an-email,Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	// The error rate fails the run, not another row: only the skipped rows are errors
+	sink := &fakeSink{}
+	ci := NewCustomerImporter(csvPath, WithMetrics(sink), WithErrorPolicy(ErrorPolicySkip), WithMaxErrorRate(0.5))
+	if _, err := ci.ImportDomainData(); err == nil {
+		t.Fatal("expected the error rate to fail the import")
+	}
+	if got := sink.runs[0]; got.Errors != 2 || !got.Failed {
+		t.Errorf("run metrics = %+v, want errors=2 failed=true", got)
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	m := new(expvar.Map).Init()
+	sink := NewExpvarMetrics(m)
+	sink.RecordImport(ImportMetrics{Rows: 10, Domains: 3, Errors: 2})
+	sink.RecordImport(ImportMetrics{Rows: 5, Domains: 4, Failed: true})
+
+	want := map[string]string{
+		"runs":         "2",
+		"failed_runs":  "1",
+		"rows":         "15",
+		"errors":       "2",
+		"last_domains": "4",
+	}
+	for key, value := range want {
+		got := m.Get(key)
+		if got == nil || got.String() != value {
+			t.Errorf("expvar %q = %v, want %s", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// ImportFiles is like ImportWithSummary but aggregates the CSV files at paths, in order, into a
+// single result instead of reading the configured path. Every file must start with its own
+// header row.
+//
+// Each file gets its own parsing front-end, so with WithDelimiterDetection the delimiter and
+// byte order mark are detected per file and exports from different systems, e.g. a comma file
+// and a semicolon file with a BOM, aggregate correctly in one run. Errors other than a partial
+// result are prefixed with the path of the failing file. WithFileConcurrency reads several
+// files at the same time.
+func (ci CustomerImporter) ImportFiles(ctx context.Context, paths ...string) ([]DomainData, Summary, error) {
+	return ci.importWith(func() (*importRun, error) {
+		return ci.aggregateFiles(ctx, paths)
+	})
+}
+
+// aggregateFiles is aggregate for several files, read in order.
+func (ci CustomerImporter) aggregateFiles(ctx context.Context, paths []string) (*importRun, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no input files")
+	}
+	if ci.config.FileConcurrency > 1 && len(paths) > 1 {
+		return ci.aggregateConcurrent(ctx, paths)
+	}
+	return ci.aggregateInputs(ctx, func(consume func(io.Reader) error) error {
+		for _, path := range paths {
+			if err := readFile(path, consume); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// aggregateInputs is aggregate for several inputs: each passes every input to consume, which
+// reads it into a run created from the first header, before the final checks run once.
+func (ci CustomerImporter) aggregateInputs(ctx context.Context, each func(consume func(io.Reader) error) error) (*importRun, error) {
+	ctx, cancel := ci.withMaxDuration(ctx)
+	defer cancel()
+
+	progress := newProgress()
+	var run *importRun
+	defer func() {
+		if run != nil {
+			_ = run.close()
+		}
+	}()
+	err := each(func(r io.Reader) error {
+		records, header, err := ci.openRecords(progress.count(r))
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			if run, err = ci.newImportRun(header); err != nil {
+				return err
+			}
+			run.progress = progress
+		}
+		// Short rows are padded to and headers compared with their own input's header
+		run.setHeader(header)
+		return run.consume(ctx, records)
+	})
+	if err != nil {
+		return run, err
+	}
+	if run == nil {
+		return nil, errors.New("no CSV input found")
+	}
+	return run, run.finish()
+}
+
+// readFile opens path and passes it to fn.
+func readFile(path string, fn func(io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	slog.Info("reading input file", "path", path)
+	return prefixError(path, fn(file))
+}
+
+// prefixError prefixes err with the name of the input it occurred in, unless it is a partial
+// result, which is about the whole run rather than that input.
+func prefixError(name string, err error) error {
+	if err != nil && !isPartial(err) {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return err
+}
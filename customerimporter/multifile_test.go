@@ -0,0 +1,68 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportFilesMixedDialects(t *testing.T) {
+	dir := t.TempDir()
+	comma := filepath.Join(dir, "comma.csv")
+	semicolon := filepath.Join(dir, "semicolon.csv")
+	if err := writeTestCSV(comma, "first_name,last_name,email\nJohn,Doe,john@example.com\nJoe,Doe,joe@other.com\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTestCSV(semicolon, "\xEF\xBB\xBFfirst_name;last_name;email\nJane;Doe, Jr.;jane@example.com\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	ci := NewCustomerImporter("", WithDelimiterDetection(), WithHeaderValidation())
+	data, summary, err := ci.ImportFiles(context.Background(), comma, semicolon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprint([]DomainData{
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "other.com", CustomerQuantity: 1},
+	})
+	if got := fmt.Sprint(data); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if summary.Rows != 3 || summary.Customers != 3 {
+		t.Errorf("summary counted %d rows and %d customers, want 3 and 3", summary.Rows, summary.Customers)
+	}
+}
+
+func TestImportFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.csv")
+	invalid := filepath.Join(dir, "invalid.csv")
+	if err := writeTestCSV(valid, "first_name,last_name,email\nJohn,Doe,john@example.com\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTestCSV(invalid, "first_name,last_name,email\nJohn,Doe,not-an-email\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		errorMsg string
+	}{
+		{name: "no files", errorMsg: "no input files"},
+		{name: "missing file", paths: []string{valid, filepath.Join(dir, "missing.csv")}, errorMsg: "missing.csv"},
+		{name: "invalid row names file", paths: []string{valid, invalid}, errorMsg: invalid + ": "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewCustomerImporter("").ImportFiles(context.Background(), tt.paths...)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package customerimporter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wwwPrefix is the leading label removed by WithStripWWWPrefix.
+const wwwPrefix = "www."
+
+// MultiLevelSuffixes is the built-in list of common public suffixes spanning more than one
+// label, used unless it is overridden with WithPublicSuffixes. A domain equal to one of these is
+// a registry suffix rather than a registrable domain, so normalization must not reduce a domain
+// down to it.
+var MultiLevelSuffixes = []string{
+	"ac.uk", "co.uk", "gov.uk", "me.uk", "org.uk",
+	"com.au", "net.au", "org.au",
+	"co.nz", "co.jp", "co.kr", "co.za", "co.in",
+	"com.br", "com.cn", "com.mx", "com.tr",
+}
+
+// defaultSuffixes is the suffixSet of MultiLevelSuffixes.
+var defaultSuffixes = newSuffixSet(MultiLevelSuffixes)
+
+// suffixSet holds the case-folded multi-level public suffixes.
+type suffixSet map[string]struct{}
+
+func newSuffixSet(suffixes []string) suffixSet {
+	s := make(suffixSet, len(suffixes))
+	for _, suffix := range suffixes {
+		s[FoldDomain(suffix)] = struct{}{}
+	}
+	return s
+}
+
+// isPublicSuffix reports whether domain is a bare public suffix: a single label such as "com"
+// or one of the multi-level suffixes such as "co.uk".
+func (s suffixSet) isPublicSuffix(domain string) bool {
+	if !strings.Contains(domain, ".") {
+		return true
+	}
+	_, ok := s[FoldDomain(domain)]
+	return ok
+}
+
+// publicSuffixes returns the suffixSet set with WithPublicSuffixes, or defaultSuffixes.
+func (c Config) publicSuffixes() suffixSet {
+	if c.suffixes != nil {
+		return c.suffixes
+	}
+	return defaultSuffixes
+}
+
+// FoldDomain returns the case-folded form of domain under which this module compares domains
+// case-insensitively, from grouping and deduplication to the exporter's company names and
+// domain IDs. Pure ASCII domains, by far the most common, are just lowercased. Others map every
+// rune to the lowercase form of the smallest rune it folds to (see unicode.SimpleFold), so
+// internationalized domains differing only in case collapse where strings.ToLower keeps them
+// apart, e.g. "ΟΔΥΣΣΕΥΣ.gr" and "οδυσσευς.gr": lowercasing never produces the final sigma "ς".
+//
+// This is Unicode simple case folding, rune for rune and available in the standard library, not
+// the full case folding of golang.org/x/text/cases: "ß" does not match "ss", nor "ﬁ" "fi".
+// IDNA registries map such characters before registration, so they rarely differ in domains.
+func FoldDomain(domain string) string {
+	for i := 0; i < len(domain); i++ {
+		if domain[i] >= utf8.RuneSelf {
+			return strings.Map(foldRune, domain)
+		}
+	}
+	return strings.ToLower(domain)
+}
+
+// foldRune returns the lowercase form of the smallest rune in the case folding orbit of r.
+func foldRune(r rune) rune {
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		smallest = min(smallest, f)
+	}
+	return unicode.ToLower(smallest)
+}
+
+// normalizeDomain applies the configured domain normalizations to a validated domain.
+func (ci CustomerImporter) normalizeDomain(domain string) string {
+	if ci.config.StripWWWPrefix {
+		domain = ci.config.publicSuffixes().stripWWWPrefix(domain)
+	}
+	return domain
+}
+
+// stripWWWPrefix removes a leading "www." (case-insensitive) unless the remainder is a bare
+// public suffix, e.g. "www.example.com" becomes "example.com" while "www.co.uk" is kept.
+func (s suffixSet) stripWWWPrefix(domain string) string {
+	if len(domain) <= len(wwwPrefix) || !strings.EqualFold(domain[:len(wwwPrefix)], wwwPrefix) {
+		return domain
+	}
+	rest := domain[len(wwwPrefix):]
+	if s.isPublicSuffix(rest) {
+		return domain
+	}
+	return rest
+}
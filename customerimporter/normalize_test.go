@@ -0,0 +1,109 @@
+package customerimporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStripWWWPrefix(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{domain: "www.example.com", want: "example.com"},
+		{domain: "WWW.example.com", want: "example.com"},
+		{domain: "www.mail.example.com", want: "mail.example.com"},
+		{domain: "www.example.co.uk", want: "example.co.uk"},
+		{domain: "example.com", want: "example.com"},
+		{domain: "wwwx.com", want: "wwwx.com"},
+		{domain: "www.com", want: "www.com"},
+		{domain: "www.co.uk", want: "www.co.uk"},
+		{domain: "www.", want: "www."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := defaultSuffixes.stripWWWPrefix(tt.domain); got != tt.want {
+				t.Errorf("stripWWWPrefix(%q) = %q, want %q", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportStripWWWPrefix(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@www.example.com,Male,192.168.1.1
+Jane,Doe,jane@example.com,Female,192.168.1.2
+Jim,Beam,jim@wwwx.com,Male,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	data, err := NewCustomerImporter(csvPath, WithStripWWWPrefix()).ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "wwwx.com", CustomerQuantity: 1},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+
+	// Without the option the www variant stays separate.
+	data, err = NewCustomerImporter(csvPath).ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 {
+		t.Errorf("got %d domains without StripWWWPrefix, want 3: %v", len(data), data)
+	}
+}
+
+func TestFoldDomain(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{a: "Example.COM", b: "example.com"},
+		{a: "BÜCHER.de", b: "bücher.de"},
+		// Lowercasing turns the final capital sigma into σ, never into the final form ς
+		{a: "ΟΔΥΣΣΕΥΣ.gr", b: "οδυσσευς.gr"},
+		// The Kelvin sign folds to the letter k
+		{a: "\u212Aiosk.com", b: "kiosk.com"},
+	}
+	for _, tt := range tests {
+		if got, want := FoldDomain(tt.a), FoldDomain(tt.b); got != want {
+			t.Errorf("FoldDomain(%q) = %q, FoldDomain(%q) = %q, want them equal", tt.a, got, tt.b, want)
+		}
+	}
+	if got := FoldDomain("Example.com"); got != "example.com" {
+		t.Errorf("FoldDomain(%q) = %q, want %q", "Example.com", got, "example.com")
+	}
+	if got := FoldDomain("straße.de"); got == FoldDomain("strasse.de") {
+		t.Errorf("FoldDomain(%q) = %q, want ß kept apart from ss", "straße.de", got)
+	}
+}
+
+func TestImportDeduplicationFoldsUnicodeDomains(t *testing.T) {
+	content := `first_name,last_name,email
+Ana,Poe,ana@οδυσσευς.gr
+Ana,Poe,ANA@ΟΔΥΣΣΕΥΣ.gr
+Max,Roe,max@ΟΔΥΣΣΕΥΣ.gr`
+
+	_, summary, err := NewCustomerImporter("", WithDeduplication()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Duplicates != 1 || summary.Customers != 2 {
+		t.Errorf("got %d duplicates and %d customers, want 1 and 2", summary.Duplicates, summary.Customers)
+	}
+}
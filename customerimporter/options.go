@@ -0,0 +1,787 @@
+package customerimporter
+
+import (
+	"regexp"
+	"slices"
+	"time"
+
+	"importer/internal/describe"
+)
+
+// GmailDomains are the Gmail-family providers that ignore dots in the local part, for use with
+// WithDotInsensitiveDomains.
+var GmailDomains = []string{"gmail.com", "googlemail.com"}
+
+// ErrorPolicy selects how an import handles rows that fail validation.
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyAbort fails the import on the first invalid row. It is the default.
+	ErrorPolicyAbort ErrorPolicy = "abort"
+	// ErrorPolicySkip leaves invalid rows out of the aggregation and counts them in
+	// Summary.SkippedRows.
+	ErrorPolicySkip ErrorPolicy = "skip"
+)
+
+// Config holds the optional settings of a CustomerImporter.
+// The zero value aggregates domains exactly as they appear after validation.
+type Config struct {
+	// StripWWWPrefix removes a leading "www." label from extracted domains before aggregation
+	// (see WithStripWWWPrefix).
+	StripWWWPrefix bool
+	// ValidateDomainLabels rejects domains whose labels violate DNS label syntax
+	// (see WithDomainLabelValidation).
+	ValidateDomainLabels bool
+	// PublicSuffixes overrides MultiLevelSuffixes for www stripping and categorization
+	// (see WithPublicSuffixes).
+	PublicSuffixes []string
+	// StrictMode enables the full recommended set of validation checks (see WithStrictMode).
+	StrictMode bool
+	// LocalPartAllowedChars lists the characters permitted in local parts in strict mode (see
+	// WithLocalPartAllowedChars). Empty permits every character strict mode accepts.
+	LocalPartAllowedChars string
+	// EmailColumns lists the zero-based columns holding email addresses (see WithEmailColumns).
+	// Empty means the single default column (index 2).
+	EmailColumns []int
+	// EmailRegex extracts the email address from each email column value before validation
+	// (see WithEmailRegex). Nil uses the whole value.
+	EmailRegex *regexp.Regexp
+	// EmailSeparators holds the runes splitting an email column value into several addresses
+	// (see WithEmailSeparators). Empty reads one address per column.
+	EmailSeparators string
+	// ErrorPolicy decides what happens to rows that fail validation (see WithErrorPolicy).
+	ErrorPolicy ErrorPolicy
+	// RejectsOutput is the path of a CSV file receiving every skipped row together with its
+	// validation error (see WithRejectsOutput). Empty disables it.
+	RejectsOutput string
+	// MaxErrorRate aborts an import skipping more than this share of rows (see
+	// WithMaxErrorRate). Zero skips without limit.
+	MaxErrorRate float64
+	// ReadTimeout fails the import when the source is not read to its end within this
+	// long (see WithReadTimeout). Zero waits forever.
+	ReadTimeout time.Duration
+	// Deduplicate counts each distinct email address once per domain (see WithDeduplication).
+	Deduplicate bool
+	// DotInsensitiveDomains lists providers whose local parts ignore dots when deduplicating
+	// (see WithDotInsensitiveDomains).
+	DotInsensitiveDomains []string
+	// CustomerIDColumn is the zero-based column identifying customers for deduplication
+	// (see WithCustomerIDColumn). Nil deduplicates by email address.
+	CustomerIDColumn *int
+	// PadShortRows right-pads rows shorter than the header with empty fields instead of
+	// rejecting them (see WithShortRowPadding).
+	PadShortRows bool
+	// Metrics receives the final counters of every import run (see WithMetrics).
+	Metrics MetricsSink
+	// CollectSampleEmail stores the first email seen per domain in DomainData.SampleEmail
+	// (see WithSampleEmail).
+	CollectSampleEmail bool
+	// CasingReport receives the domains seen with more than one casing at the end of every
+	// import (see WithCasingReport). Nil disables the check.
+	CasingReport func(variants []CasingVariants)
+	// PreferLastSeen makes first-seen values such as the sample email keep the last value seen
+	// instead (see WithPreferLastSeen).
+	PreferLastSeen bool
+	// FixedWidth parses fixed-width records instead of CSV (see WithFixedWidth).
+	FixedWidth *FixedWidthLayout
+	// ValidateHeader checks the header before any data row is processed
+	// (see WithHeaderValidation).
+	ValidateHeader bool
+	// Categorize sets DomainData.Category on every aggregated domain (see WithCategory).
+	Categorize bool
+	// CategoryProviders overrides FreemailProviders for categorization
+	// (see WithFreemailProviders). Nil uses the built-in list.
+	CategoryProviders []string
+	// WeightColumn is the zero-based column holding how many customers each row represents
+	// (see WithWeightColumn). Nil counts every row once.
+	WeightColumn *int
+	// WeightPolicy decides how blank or invalid weights are handled (see WithWeightPolicy).
+	WeightPolicy WeightPolicy
+	// DomainFilter reports whether a domain is aggregated (see WithDomainFilter). Nil keeps all.
+	DomainFilter func(domain string) bool
+	// DomainGroups maps group names to their member domains; counts are aggregated per group
+	// (see WithDomainGroups). Nil aggregates per domain.
+	DomainGroups map[string][]string
+	// EnsureDomains are always part of the result, with a zero count when absent from the
+	// input (see WithEnsureDomains).
+	EnsureDomains []string
+	// DetectDelimiter sniffs the CSV delimiter and byte order mark from the input
+	// (see WithDelimiterDetection).
+	DetectDelimiter bool
+	// EmailPattern must match every whole email address before domain extraction
+	// (see WithEmailPattern). Nil disables the check.
+	EmailPattern *regexp.Regexp
+	// ASCIIOnlyDomains rejects domains containing non-ASCII bytes (see WithASCIIOnlyDomains).
+	ASCIIOnlyDomains bool
+	// ValidateHostnames rejects domains that are IP addresses or not valid hostnames
+	// (see WithHostnameValidation).
+	ValidateHostnames bool
+	// LinePreprocessor rewrites every raw input line before parsing (see WithLinePreprocessor).
+	// Nil passes lines through unchanged.
+	LinePreprocessor func(line string) string
+	// RowValidator applies custom business rules to every parsed row (see WithRowValidator).
+	// Nil accepts all rows.
+	RowValidator func(record []string) error
+	// CountRoleAccounts sets DomainData.RoleAccounts (see WithRoleAccounts).
+	CountRoleAccounts bool
+	// SpillThreshold is the number of domains kept in memory before counts are spilled to
+	// temporary files (see WithExternalAggregation). Zero aggregates in memory only.
+	SpillThreshold int
+	// SpillDir is the directory of the spill files. Empty uses os.TempDir.
+	SpillDir string
+	// SketchTopK is the number of domains reported by a count-min sketch aggregation (see
+	// WithCountMinSketch). Zero counts exactly.
+	SketchTopK int
+	// SketchEpsilon bounds the overcount of every estimate relative to the total customers.
+	SketchEpsilon float64
+	// SketchDelta is the probability of an estimate exceeding the SketchEpsilon bound.
+	SketchDelta float64
+	// DateColumn is the zero-based column holding each row's date for ImportMonthly
+	// (see WithDateColumn). Nil disables the monthly breakdown.
+	DateColumn *int
+	// DateLayout is the time.Parse layout of the DateColumn values.
+	DateLayout string
+	// DatePolicy decides how blank or unparseable dates are handled (see WithDatePolicy).
+	DatePolicy DatePolicy
+	// IPColumn is the zero-based column holding each row's IP address for ImportSubnets
+	// (see WithIPColumn). Nil disables the subnet aggregation.
+	IPColumn *int
+	// IPPrefixLen is the prefix length IPv4 addresses are grouped by.
+	IPPrefixLen int
+	// SampleEvery processes only the first of every SampleEvery data rows (see WithSampling).
+	// Zero and one process every row.
+	SampleEvery int
+	// SampleScale counts each sampled row SampleEvery times.
+	SampleScale bool
+	// RowFilterColumn is the zero-based column compared with RowFilterValue (see WithRowFilter).
+	// Nil counts all rows.
+	RowFilterColumn *int
+	// RowFilterValue is the value RowFilterColumn must hold for a row to be counted.
+	RowFilterValue string
+	// MaxDuration stops the import with a partial result once it has run this long
+	// (see WithMaxDuration). Zero imposes no limit.
+	MaxDuration time.Duration
+	// SkipRepeatedHeaders skips data rows identical to the header (see WithSkipRepeatedHeaders).
+	SkipRepeatedHeaders bool
+	// TwoPass reads the input twice, first for the domains, then for their counts (see
+	// WithTwoPassAggregation).
+	TwoPass bool
+	// CacheFile stores the result of ImportWithSummary for reuse by runs over the same input
+	// (see WithAggregationCache). Empty disables the cache.
+	CacheFile string
+	// CacheKey describes the function options to the aggregation cache and incremental state
+	// (see WithCacheKey).
+	CacheKey []string
+	// StateFile records how far the input was read and the counts so far, so the next run only
+	// reads the rows appended since (see WithIncrementalState). Empty reads the whole input.
+	StateFile string
+	// FileConcurrency is the number of files ImportFiles reads at the same time (see
+	// WithFileConcurrency). Zero and one read them one after another.
+	FileConcurrency int
+
+	// trieCounter selects the trie aggregation backend (see withTrieCounter).
+	trieCounter bool
+	// collectDomains selects the domainSet backend of a first pass (see aggregateTwoPass).
+	collectDomains bool
+	// fixedDomains selects a fixedCounter over these sorted domains for a second pass.
+	fixedDomains []string
+	// localPartChars is the parsed LocalPartAllowedChars.
+	localPartChars runeSet
+	// suffixes is the suffixSet of PublicSuffixes, built once by WithPublicSuffixes.
+	suffixes suffixSet
+	// nilEmailPattern records a WithEmailPattern call with a nil regexp, reported by
+	// newImportRun.
+	nilEmailPattern bool
+}
+
+// String renders the config as "importer.<option>=<value>" lines, one per option, for
+// diagnostics such as the CLI -explain flag.
+func (c Config) String() string {
+	return describe.String("importer", c)
+}
+
+// Option configures a CustomerImporter.
+type Option func(*Config)
+
+// WithStripWWWPrefix maps domains such as "www.example.com" to "example.com" before aggregation.
+// Only a complete leading "www" label is removed ("wwwx.com" is kept), and never when the
+// remainder would be a bare public suffix ("www.co.uk" is kept).
+func WithStripWWWPrefix() Option {
+	return func(c *Config) {
+		c.StripWWWPrefix = true
+	}
+}
+
+// WithPublicSuffixes replaces the built-in MultiLevelSuffixes list deciding where the registrable
+// part of a domain starts, for WithStripWWWPrefix and WithCategory. Single labels such as "com"
+// are always suffixes; suffixes may span any number of labels. Add entries such as "ox.ac.uk"
+// to keep subdomains of a suffix apart, or leave out built-in ones to disable them:
+//
+//	customerimporter.WithPublicSuffixes(slices.DeleteFunc(slices.Clone(customerimporter.MultiLevelSuffixes),
+//		func(suffix string) bool { return suffix == "ac.uk" }))
+func WithPublicSuffixes(suffixes []string) Option {
+	return func(c *Config) {
+		c.PublicSuffixes = slices.Clone(suffixes)
+		c.suffixes = newSuffixSet(suffixes)
+	}
+}
+
+// WithDomainLabelValidation enables strict DNS label checks on every domain: labels must be
+// non-empty ("foo..com" is rejected), at most 63 characters long, and must not start or end
+// with a hyphen ("-foo.com", "foo-.com").
+func WithDomainLabelValidation() Option {
+	return func(c *Config) {
+		c.ValidateDomainLabels = true
+	}
+}
+
+// WithStrictMode enables every recommended validation check at once, rejecting emails that the
+// lenient default accepts when they:
+//   - contain whitespace anywhere inside the address (e.g. "john doe@example.com")
+//   - have control characters or a quoted string in the local part, which is unsupported
+//   - exceed 254 characters, or have a local part over 64 or a domain over 253 characters
+//   - have a domain without a dot (e.g. "john@localhost")
+//   - have a domain violating DNS label syntax (see WithDomainLabelValidation)
+//
+// Row errors wrap ErrInvalidLocalPart or ErrInvalidDomain to tell the two parts apart, or
+// ErrEmailTooLong when only the address as a whole exceeds its limit.
+func WithStrictMode() Option {
+	return func(c *Config) {
+		c.StrictMode = true
+	}
+}
+
+// WithLocalPartAllowedChars restricts the characters strict mode accepts in the local part to
+// set, for systems with their own notion of a valid address, rejecting any local part with a
+// character outside it. set lists single characters and inclusive ranges such as "a-z"; a '-'
+// at its start or end stands for itself. Characters are compared as they appear in the input,
+// so mixed-case addresses need both ranges:
+//
+//	customerimporter.WithLocalPartAllowedChars("a-zA-Z0-9.+_-")
+//
+// It only applies together with WithStrictMode; row errors wrap ErrInvalidLocalPart, and a
+// malformed set, such as the reversed range "z-a", fails the import. An empty set lifts the
+// restriction.
+func WithLocalPartAllowedChars(set string) Option {
+	return func(c *Config) {
+		c.LocalPartAllowedChars = set
+		// A malformed set is reported by newImportRun
+		c.localPartChars, _ = parseRuneSet(set)
+	}
+}
+
+// WithEmailColumns reads email addresses from every listed column instead of only the third one,
+// so a row with e.g. primary and secondary addresses contributes to both domains. The first
+// listed column is required; blank values in the remaining columns are skipped.
+func WithEmailColumns(columns []int) Option {
+	return func(c *Config) {
+		c.EmailColumns = slices.Clone(columns)
+	}
+}
+
+// WithEmailRegex extracts the email address embedded in a free-text column, such as
+// "Contact: john@x.com (primary)", using the first capture group of re (or the whole match
+// when re has no groups). Values that do not match are rejected as invalid emails.
+func WithEmailRegex(re *regexp.Regexp) Option {
+	return func(c *Config) {
+		c.EmailRegex = re
+	}
+}
+
+// WithEmailSeparators reads email columns packing several addresses, such as
+// "a@x.com; b@y.com", by splitting each value on any of separators. Every address is validated
+// on its own and counted for its domain, so one row can contribute to several domains; an invalid
+// address fails the whole row as usual. Whitespace around addresses and empty parts, as in a
+// trailing separator, are ignored. With WithEmailRegex the pattern is applied to each address.
+func WithEmailSeparators(separators []rune) Option {
+	return func(c *Config) {
+		c.EmailSeparators = string(separators)
+	}
+}
+
+// WithErrorPolicy sets how rows failing validation are handled. The default, ErrorPolicyAbort,
+// fails the whole import on the first invalid row.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(c *Config) {
+		c.ErrorPolicy = policy
+	}
+}
+
+// WithRejectsOutput streams every row skipped under ErrorPolicySkip verbatim to the CSV file at
+// path, with an extra "error" column holding the validation failure, so the rows can be handed
+// back for cleanup. The file is created (or truncated) when the import starts.
+func WithRejectsOutput(path string) Option {
+	return func(c *Config) {
+		c.RejectsOutput = path
+	}
+}
+
+// WithMaxErrorRate bounds ErrorPolicySkip: the import fails with ErrErrorRateExceeded once more
+// than rate (e.g. 0.05 for 5%) of the rows read so far were skipped as invalid, which usually
+// means the wrong file or column. The rate is checked every 100 rows and at the end of the
+// input, so a badly wrong file fails fast instead of being mostly skipped.
+func WithMaxErrorRate(rate float64) Option {
+	return func(c *Config) {
+		c.MaxErrorRate = rate
+	}
+}
+
+// WithReadTimeout fails the import with ErrReadTimeout when the source has not been read to its
+// end d after reading started, e.g. because the producer behind a FIFO stalled or trickles data
+// too slowly. The deadline is fixed when reading starts and also interrupts a read blocked at
+// that time, which finishes in the background once the source delivers data or is closed. Unlike
+// WithMaxDuration it fails the import instead of returning a partial result.
+func WithReadTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.ReadTimeout = d
+	}
+}
+
+// WithDeduplication makes CustomerQuantity count distinct customers instead of rows: every email
+// address (compared case-insensitively) is counted once for its domain, and repeats are reported
+// in Summary.Duplicates. The set of seen addresses is kept in memory for the whole import.
+func WithDeduplication() Option {
+	return func(c *Config) {
+		c.Deduplicate = true
+	}
+}
+
+// WithDotInsensitiveDomains treats dots in the local part as insignificant for the listed
+// providers while deduplicating, so "john.doe@gmail.com" and "johndoe@gmail.com" are one customer
+// (see GmailDomains). The domain an address is counted under is unaffected. It has no effect
+// without WithDeduplication.
+func WithDotInsensitiveDomains(domains []string) Option {
+	return func(c *Config) {
+		c.DotInsensitiveDomains = slices.Clone(domains)
+	}
+}
+
+// WithCustomerIDColumn makes WithDeduplication key uniqueness on the customer ID in column
+// instead of the email address: a customer ID is counted once per domain, however many rows or
+// addresses at that domain it appears with. Domains are still taken from the email columns. IDs
+// are compared exactly after trimming whitespace, and a blank ID fails the row. It has no effect
+// without WithDeduplication.
+func WithCustomerIDColumn(column int) Option {
+	return func(c *Config) {
+		c.CustomerIDColumn = &column
+	}
+}
+
+// WithShortRowPadding accepts rows with fewer fields than the header, as produced by sources that
+// drop trailing empty columns, by right-padding them with empty strings up to the header width.
+// The email column must still be present after padding to be valid. Padded rows are counted in
+// Summary.PaddedRows.
+func WithShortRowPadding() Option {
+	return func(c *Config) {
+		c.PadShortRows = true
+	}
+}
+
+// WithMetrics reports rows, domains, errors and duration of every import run to sink, e.g. an
+// ExpvarMetrics or an adapter for the embedding service's metrics system. By default no metrics
+// are reported.
+func WithMetrics(sink MetricsSink) Option {
+	return func(c *Config) {
+		c.Metrics = sink
+	}
+}
+
+// WithSampleEmail keeps one representative address per domain for spot-checking: the first email
+// counted for it, or the last one under WithPreferLastSeen, stored in DomainData.SampleEmail.
+// The sample is trimmed and carries the normalized domain, so it reflects options such as
+// WithStripWWWPrefix.
+func WithSampleEmail() Option {
+	return func(c *Config) {
+		c.CollectSampleEmail = true
+	}
+}
+
+// WithCasingReport tracks the spellings every domain arrives with, as a data hygiene signal:
+// a domain seen as both "Example.com" and "example.com" often comes from several upstream
+// sources that normalize differently. Domains are still aggregated as spelled. Once the input is
+// read, report is called with the domains seen with two or more spellings, after
+// normalizations such as WithStripWWWPrefix, sorted by domain and empty when every domain was
+// cased consistently. Pass LogCasingVariants to log them as warnings. It is not called for
+// imports that fail, stop early or are answered from WithAggregationCache.
+func WithCasingReport(report func(variants []CasingVariants)) Option {
+	return func(c *Config) {
+		c.CasingReport = report
+	}
+}
+
+// WithPreferLastSeen lets the most recent record win wherever the aggregation otherwise keeps
+// the first value seen for a domain, for inputs appended to over time: DomainData.SampleEmail
+// holds the last address counted for the domain. Counts are unaffected, and WithDeduplication
+// still counts the first row of every customer, so its weight, date and IP address are the ones
+// aggregated.
+func WithPreferLastSeen() Option {
+	return func(c *Config) {
+		c.PreferLastSeen = true
+	}
+}
+
+// WithFixedWidth parses the input as fixed-width records laid out by layout instead of CSV.
+// Each layout field becomes one column, so EmailColumns and the default email column (index 2)
+// refer to positions in layout.Fields. Validation and aggregation are unchanged. Imports fail
+// before reading any record when a field has a negative offset or a length below one.
+func WithFixedWidth(layout FixedWidthLayout) Option {
+	return func(c *Config) {
+		layout.Fields = slices.Clone(layout.Fields)
+		c.FixedWidth = &layout
+	}
+}
+
+// WithHeaderValidation fails the import before reading any data row when the header lacks one
+// of the email columns or names it without "email" (case-insensitive), catching files with a
+// different column layout early. CheckHeader runs the same check on its own.
+func WithHeaderValidation() Option {
+	return func(c *Config) {
+		c.ValidateHeader = true
+	}
+}
+
+// WithCategory tags every aggregated domain in DomainData.Category as CategoryFreemail when it
+// belongs to a free email provider (FreemailProviders, or the list set with WithFreemailProviders),
+// CategoryUnknown when it is a bare public suffix or numeric, and CategoryCorporate otherwise.
+func WithCategory() Option {
+	return func(c *Config) {
+		c.Categorize = true
+	}
+}
+
+// WithFreemailProviders replaces the built-in FreemailProviders list used by WithCategory, e.g.
+// to add regional providers. It has no effect without WithCategory.
+func WithFreemailProviders(domains []string) Option {
+	return func(c *Config) {
+		c.CategoryProviders = slices.Clone(domains)
+	}
+}
+
+// WithWeightColumn adds the positive integer in column to the row's domains instead of 1, for
+// inputs where a row stands for several customers. Blank or invalid weights fail the row unless
+// relaxed with WithWeightPolicy. With WithDeduplication a repeated address adds nothing.
+func WithWeightColumn(column int) Option {
+	return func(c *Config) {
+		c.WeightColumn = &column
+	}
+}
+
+// WithWeightPolicy sets how rows with a blank or invalid weight are handled under
+// WithWeightColumn. The default, WeightPolicyError, makes them row errors subject to the
+// ErrorPolicy.
+func WithWeightPolicy(policy WeightPolicy) Option {
+	return func(c *Config) {
+		c.WeightPolicy = policy
+	}
+}
+
+// WithDomainFilter aggregates only the domains for which keep returns true, e.g. one built with
+// DomainContains. Rows of other domains are still read and validated, but do not appear in the
+// result, so Summary.Domains and Summary.Customers describe the matching subset.
+func WithDomainFilter(keep func(domain string) bool) Option {
+	return func(c *Config) {
+		c.DomainFilter = keep
+	}
+}
+
+// WithDomainGroups aggregates per named group instead of per domain, e.g. "competitors" for
+// []string{"a.com", "b.com"}: each domain's customers are counted under the name of the group
+// listing it, matched case-insensitively after normalizations such as WithStripWWWPrefix, and
+// under UngroupedDomains when no group lists it. Every group and UngroupedDomains appear in the
+// result, with a zero count when empty. A domain listed in several groups belongs to the
+// alphabetically first of them. DomainFilter still sees the real domains.
+func WithDomainGroups(groups map[string][]string) Option {
+	return func(c *Config) {
+		c.DomainGroups = make(map[string][]string, len(groups))
+		for name, domains := range groups {
+			c.DomainGroups[name] = slices.Clone(domains)
+		}
+	}
+}
+
+// WithEnsureDomains adds a zero-count DomainData entry, in sorted position, for every listed
+// domain that no row aggregated, so fixed-schema reports always contain the same row set. Domains
+// are matched exactly as aggregated, after options such as WithStripWWWPrefix. The zero-count
+// entries do not count towards Summary.Domains, and domains present in the data keep their
+// counts. Under WithCountMinSketch, a listed domain outside the tracked ones carries its
+// estimate, which collisions may lift above zero.
+func WithEnsureDomains(domains []string) Option {
+	return func(c *Config) {
+		c.EnsureDomains = slices.Clone(domains)
+	}
+}
+
+// WithDelimiterDetection sniffs the first 4 KiB of the input instead of assuming commas: the
+// header decides between comma, semicolon and tab by counting each outside quotes, falling back
+// to comma with a logged warning when none occurs or two tie. A UTF-8 byte order mark is
+// stripped; UTF-16 input is rejected. Detection results are logged. It does not apply to
+// WithFixedWidth.
+func WithDelimiterDetection() Option {
+	return func(c *Config) {
+		c.DetectDelimiter = true
+	}
+}
+
+// WithEmailPattern enforces a house style for addresses: every email, after WithEmailRegex
+// extraction and trimming, must match re in full before the built-in validation runs, or the row
+// fails with an error wrapping ErrEmailPatternMismatch. re is used as given, keeping the
+// semantics of regexp.CompilePOSIX or Longest, and an address passes only when the match re
+// finds spans all of it, so `[^+]+@[^@]+` rejects plus-addressing such as
+// "john+news@example.com". Under the default leftmost-first semantics the first matching
+// alternative is taken, so `a|ab` does not match "ab" in full; list longer alternatives first or
+// call Longest. A nil re fails the import.
+func WithEmailPattern(re *regexp.Regexp) Option {
+	return func(c *Config) {
+		c.EmailPattern = re
+		c.nilEmailPattern = re == nil
+	}
+}
+
+// WithASCIIOnlyDomains rejects every domain containing a non-ASCII byte, such as "bücher.de",
+// for consumers that require plain ASCII domains. Such rows fail with an error wrapping
+// ErrNonASCIIDomain and are handled by the ErrorPolicy like any invalid email. Domains are
+// rejected rather than converted, so an ASCII-encoded (Punycode) form such as
+// "xn--bcher-kva.de" is accepted as is.
+func WithASCIIOnlyDomains() Option {
+	return func(c *Config) {
+		c.ASCIIOnlyDomains = true
+	}
+}
+
+// WithHostnameValidation rejects every domain that is not a syntactically valid hostname, as a
+// cheap sanity check without DNS lookups. Domains that are IP addresses, such as "10.0.0.1" or
+// "[::1]", fail with an error wrapping ErrIPAddressDomain; domains with characters other than
+// ASCII letters, digits, hyphens and dots, such as "exam_ple.com", or with malformed labels
+// fail with an error wrapping ErrInvalidHostname. Both are handled by the ErrorPolicy like any
+// invalid email and counted under their own SkipReason.
+func WithHostnameValidation() Option {
+	return func(c *Config) {
+		c.ValidateHostnames = true
+	}
+}
+
+// WithDateColumn parses column of every row with the time.Parse layout, such as "2006-01-02",
+// and additionally counts the row's domains per calendar month, returned by ImportMonthly.
+// Blank or unparseable dates fail the row unless relaxed with WithDatePolicy.
+func WithDateColumn(column int, layout string) Option {
+	return func(c *Config) {
+		c.DateColumn = &column
+		c.DateLayout = layout
+	}
+}
+
+// WithDatePolicy sets how rows with a blank or unparseable date are handled under
+// WithDateColumn. The default, DatePolicyError, makes them row errors subject to the
+// ErrorPolicy.
+func WithDatePolicy(policy DatePolicy) Option {
+	return func(c *Config) {
+		c.DatePolicy = policy
+	}
+}
+
+// WithIPColumn parses column of every row as an IPv4 or IPv6 address and additionally counts the
+// row's customers per subnet, returned by ImportSubnets: IPv4 addresses are grouped into networks
+// of prefixLen bits, e.g. 24 for "192.168.1.0/24", IPv6 addresses into /64 networks
+// (IPv6SubnetBits). A row is one customer of its subnet however many emails it holds. An invalid
+// address is a row error subject to the ErrorPolicy, and a prefix length outside 0 to 32 fails
+// the import.
+func WithIPColumn(column, prefixLen int) Option {
+	return func(c *Config) {
+		c.IPColumn = &column
+		c.IPPrefixLen = prefixLen
+	}
+}
+
+// WithLinePreprocessor passes every raw input line, header included, through fn before it is
+// parsed, e.g. to strip a fixed prefix or repair known-bad escapes. fn receives the line without
+// its line ending, which is restored afterwards, so it sees a quoted field spanning several lines
+// as separate lines. It runs before WithDelimiterDetection and also applies to WithFixedWidth.
+func WithLinePreprocessor(fn func(line string) string) Option {
+	return func(c *Config) {
+		c.LinePreprocessor = fn
+	}
+}
+
+// WithRowValidator calls validate with every parsed data row before the email domains are
+// extracted, for bespoke rules such as rejecting Gmail addresses in B2B imports or requiring a
+// region column value. A non-nil error, wrapped with ErrRowRejected, makes the row invalid like a
+// malformed email, so it aborts the import or is skipped according to the ErrorPolicy. The record
+// is only valid during the call (after any WithShortRowPadding) and must not be modified.
+func WithRowValidator(validate func(record []string) error) Option {
+	return func(c *Config) {
+		c.RowValidator = validate
+	}
+}
+
+// WithRoleAccounts counts, per domain, the customers whose address is a role account such as
+// "info@" or "support@" (see RoleLocalParts) in DomainData.RoleAccounts. Combine it with
+// WithCategory for the complete Breakdown report.
+func WithRoleAccounts() Option {
+	return func(c *Config) {
+		c.CountRoleAccounts = true
+	}
+}
+
+// WithSampling processes only every Nth data row, starting with the first, for a fast
+// approximate distribution of a huge file; the other rows are read but neither validated nor
+// counted (see Summary.UnsampledRows). With scale each sampled row contributes everyN (times
+// its weight under WithWeightColumn) to its domains, estimating the full counts. An everyN of
+// one or less processes every row.
+func WithSampling(everyN int, scale bool) Option {
+	return func(c *Config) {
+		c.SampleEvery = everyN
+		c.SampleScale = scale
+	}
+}
+
+// WithRowFilter restricts the statistics to a subset of the rows, e.g. WithRowFilter(3, "Female")
+// for a gender column: only rows whose column value, with surrounding whitespace trimmed, equals
+// equals exactly are validated and counted. The other rows are left out without being validated
+// and counted in Summary.FilteredRows. Use WithRowValidator to reject rows as invalid instead.
+func WithRowFilter(column int, equals string) Option {
+	return func(c *Config) {
+		c.RowFilterColumn = &column
+		c.RowFilterValue = equals
+	}
+}
+
+// WithMaxDuration bounds the wall-clock time of an import for SLA-bound jobs: once d has
+// elapsed, reading stops and the domains aggregated so far are returned like on context
+// cancellation, with an error wrapping both ErrPartialResult and ErrMaxDurationExceeded. The
+// deadline is checked between rows, so combine it with a longer WithReadTimeout for sources
+// that may stall.
+func WithMaxDuration(d time.Duration) Option {
+	return func(c *Config) {
+		c.MaxDuration = d
+	}
+}
+
+// WithSkipRepeatedHeaders skips data rows identical to the header row, as found in naively
+// concatenated exports, instead of failing them as invalid emails. Skipped headers are not data
+// rows: they are counted in Summary.RepeatedHeaders only, and their total is logged at the end.
+// With ImportFiles each file is compared with its own header.
+func WithSkipRepeatedHeaders() Option {
+	return func(c *Config) {
+		c.SkipRepeatedHeaders = true
+	}
+}
+
+// WithTwoPassAggregation trades a second read of the input for exact counts in bounded memory:
+// a first pass only collects the distinct domains, without counts, then a second pass counts
+// them into a fixed sorted table instead of a growing hash map. It suits inputs whose domain set
+// fits in memory but whose map would not. With WithExternalAggregation the first pass spills the
+// domains to disk and the table is built from their merge. Every row is validated in both passes
+// and the summary and rejects are those of the second pass; WithMaxDuration applies to each
+// pass. It applies to the configured path only, as ImportReader and ImportFiles read their
+// input once.
+func WithTwoPassAggregation() Option {
+	return func(c *Config) {
+		c.TwoPass = true
+	}
+}
+
+// WithAggregationCache makes ImportWithSummary, and with it ImportDomainData, keep its result in
+// a gob-encoded cache file at path for repeated analysis of an unchanged file. The cache is
+// keyed by the Fingerprint of the input content and configuration: a run with a matching key
+// returns the cached domains and summary without parsing the CSV, any other run imports the file
+// and replaces the cache. The input is still read once to hash it. Partial results are not
+// cached, and a missing or unreadable cache file only costs a regular import. The other import
+// methods do not use the cache.
+//
+// Function options such as WithDomainFilter are only known to be set, not what they do, so
+// while one that changes the result is set the cache is neither read nor written unless
+// WithCacheKey describes them.
+func WithAggregationCache(path string) Option {
+	return func(c *Config) {
+		c.CacheFile = path
+	}
+}
+
+// WithCacheKey adds parts describing the WithDomainFilter, WithLinePreprocessor and
+// WithRowValidator functions to the key of WithAggregationCache and WithIncrementalState, e.g.
+// "grep=hub" for a filter built with DomainContains("hub"). The configuration itself only
+// records whether a function is set, so the parts must change whenever the functions do; a
+// cache entry or state stored under other parts is a miss.
+func WithCacheKey(parts ...string) Option {
+	return func(c *Config) {
+		c.CacheKey = parts
+	}
+}
+
+// WithIncrementalState makes ImportWithSummary, and with it ImportDomainData, process an
+// append-only file incrementally. After each successful run a gob-encoded state file at path
+// records the byte offset the input was read to together with the domains and summary counted
+// so far; the next run seeks to that offset, reads only the rows appended since and returns them
+// merged with the stored counts, so the summary covers all runs. Rows are read up to the last
+// line break only, leaving a row still being written for the next run; a file whose last row
+// lacks a line break gets it counted once another row follows.
+//
+// The input is read from the start again when the state is missing, unreadable, was written
+// under a different configuration or for another input path, or when the bytes before the offset
+// changed, as after a rotation; they are compared by a SHA-256 hash stored in the state, so each
+// run still reads, but does not parse, the whole file. While a function option that changes the
+// result, such as WithDomainFilter, is set without WithCacheKey describing it, every run reads
+// the whole input and no state is written. Partial results are returned but not recorded, as
+// are counts overflowing when merged. It cannot be combined with WithDeduplication, whose seen addresses are not stored,
+// WithCountMinSketch, WithAggregationCache or a tar archive input.
+func WithIncrementalState(path string) Option {
+	return func(c *Config) {
+		c.StateFile = path
+	}
+}
+
+// WithFileConcurrency makes ImportFiles read up to n files at the same time, each into counts
+// of its own that are merged once all files are read, for inputs split into many files. The
+// result equals that of reading the files in order, including sample emails and casing
+// reports. The first file failing cancels the others and its error is returned; WithMaxErrorRate
+// is checked per file while reading and over all files at the end.
+//
+// Callbacks such as WithDomainFilter, WithRowValidator or WithLinePreprocessor must be safe for
+// concurrent use. It cannot be combined with WithDeduplication, WithSampling, WithRejectsOutput,
+// WithExternalAggregation or WithCountMinSketch, which depend on reading rows in order or share
+// one output. Single files and the other import methods are unaffected.
+func WithFileConcurrency(n int) Option {
+	return func(c *Config) {
+		c.FileConcurrency = n
+	}
+}
+
+// WithCountMinSketch replaces the exact per-domain counts with a count-min sketch for inputs
+// whose distinct domains are too many to count exactly, neither in memory nor on disk with
+// WithExternalAggregation. The sketch holds ceil(ln(1/delta)) rows of ceil(e/epsilon) 8-byte
+// counters, independent of the number of domains: epsilon 0.001 and delta 0.01 take 5 rows of
+// 2719 counters, about 106 KiB. Halving epsilon doubles the memory; lowering delta tenfold adds
+// about 2.3 rows.
+//
+// Counts become estimates that are never too low and, with probability 1-delta each, exceed
+// the true count by at most epsilon times the total customers: with 1,000,000 customers and
+// epsilon 0.001, by at most 1,000. Small domains are thus dominated by the error, so the sketch
+// suits finding and sizing the large ones. As a sketch cannot list its domains, only the topK
+// domains with the highest estimates are tracked and returned, alphabetically like exact
+// results; Summary.Domains counts those, while Summary.Customers stays exact. Pipeline stages
+// and exports see the estimates as regular counts. It cannot be combined with
+// WithExternalAggregation or WithTwoPassAggregation.
+func WithCountMinSketch(epsilon, delta float64, topK int) Option {
+	return func(c *Config) {
+		c.SketchEpsilon = epsilon
+		c.SketchDelta = delta
+		c.SketchTopK = topK
+	}
+}
+
+// WithExternalAggregation bounds the memory of the aggregation for inputs with more unique
+// domains than fit in RAM: once more than maxDomains domains are counted in memory, the counts
+// are written to a sorted temporary file in dir (os.TempDir when empty) and cleared, and the
+// files are merged in domain order at the end, external merge-sort style. The files are deleted
+// when the import returns. The result is identical to the in-memory aggregation, at the cost of
+// the file I/O. All files are open at once during the merge, so pick maxDomains such that the
+// unique domains divided by it stay well below the open file limit. Options that keep
+// per-domain or per-address state, such as WithDeduplication, WithSampleEmail and
+// WithRoleAccounts, still hold it in memory. Progress logs report an upper bound of the unique
+// domains.
+func WithExternalAggregation(maxDomains int, dir string) Option {
+	return func(c *Config) {
+		c.SpillThreshold = maxDomains
+		c.SpillDir = dir
+	}
+}
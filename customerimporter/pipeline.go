@@ -0,0 +1,138 @@
+package customerimporter
+
+import (
+	"slices"
+	"strings"
+)
+
+// Stage is a post-aggregation transform of the imported domain data, such as a filter or a
+// ranking. Stages return a new slice and leave their input unmodified.
+type Stage func(data []DomainData) []DomainData
+
+// Pipeline chains stages that are applied in order, each receiving the output of the previous
+// one. The order matters: TopN followed by Exclude can return fewer than N domains, Exclude
+// followed by TopN cannot. The zero value returns the data unchanged.
+type Pipeline []Stage
+
+// Then returns a copy of the pipeline with stage appended.
+func (p Pipeline) Then(stage Stage) Pipeline {
+	return append(slices.Clip(p), stage)
+}
+
+// Apply runs data through every stage in order.
+func (p Pipeline) Apply(data []DomainData) []DomainData {
+	for _, stage := range p {
+		data = stage(data)
+	}
+	return data
+}
+
+// Filter returns a stage keeping the domain data for which keep reports true, in input order.
+func Filter(keep func(d DomainData) bool) Stage {
+	return func(data []DomainData) []DomainData {
+		kept := make([]DomainData, 0, len(data))
+		for _, d := range data {
+			if keep(d) {
+				kept = append(kept, d)
+			}
+		}
+		return kept
+	}
+}
+
+// MinCount returns a stage dropping domains with fewer than n customers.
+func MinCount(n uint64) Stage {
+	return Filter(func(d DomainData) bool {
+		return d.CustomerQuantity >= n
+	})
+}
+
+// Exclude returns a stage dropping the listed domains. Domains are compared exactly, so pass
+// them normalized like the import, e.g. case-folded with FoldDomain.
+func Exclude(domains []string) Stage {
+	blocked := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		blocked[domain] = struct{}{}
+	}
+	return Filter(func(d DomainData) bool {
+		_, found := blocked[d.Domain]
+		return !found
+	})
+}
+
+// MatchDomain returns a stage keeping the domains matching match, such as DomainContains.
+func MatchDomain(match func(domain string) bool) Stage {
+	return Filter(func(d DomainData) bool {
+		return match(d.Domain)
+	})
+}
+
+// Sort returns a stage ordering the domain data by order (see SortDomainData), e.g. to restore
+// the alphabetical import order after a stage renaming domains. It panics right away on an
+// unsupported order, like regexp.MustCompile on an invalid pattern.
+func Sort(order SortOrder) Stage {
+	if err := SortDomainData(nil, order); err != nil {
+		panic(err)
+	}
+	return func(data []DomainData) []DomainData {
+		sorted := slices.Clone(data)
+		_ = SortDomainData(sorted, order)
+		return sorted
+	}
+}
+
+// TopN returns a stage keeping the n domains with the most customers, ordered by SortByCount.
+func TopN(n int) Stage {
+	return func(data []DomainData) []DomainData {
+		ranked := slices.Clone(data)
+		_ = SortDomainData(ranked, SortByCount)
+		return ranked[:max(0, min(n, len(ranked)))]
+	}
+}
+
+// MergeEquivalentTLDs returns a stage merging domains that differ only in top-level domains of
+// the same equivalence class, e.g. "brand.com" and "brand.co" for the class {"com", "co"}. Each
+// class lists its canonical top-level domain first, and merged domains are renamed to it: their
+// customers and role accounts are summed, the sample email and category are those of the first
+// record. A top-level domain may span labels, such as "co.uk"; the longest listed one applies.
+// They are compared case-insensitively. Records keep the position of the first record merged
+// into them, so sorted input may need sorting again, e.g. with Sort.
+func MergeEquivalentTLDs(classes [][]string) Stage {
+	canonical := make(map[string]string)
+	for _, class := range classes {
+		for _, tld := range class {
+			canonical[FoldDomain(tld)] = FoldDomain(class[0])
+		}
+	}
+	return func(data []DomainData) []DomainData {
+		merged := make([]DomainData, 0, len(data))
+		index := make(map[string]int, len(data))
+		for _, d := range data {
+			d.Domain = canonicalTLD(d.Domain, canonical)
+			if i, found := index[d.Domain]; found {
+				merged[i].CustomerQuantity += d.CustomerQuantity
+				merged[i].RoleAccounts += d.RoleAccounts
+				continue
+			}
+			index[d.Domain] = len(merged)
+			merged = append(merged, d)
+		}
+		return merged
+	}
+}
+
+// canonicalTLD replaces the longest top-level domain of domain found in canonical with its
+// canonical representative. Domains consisting of a listed top-level domain only are kept.
+func canonicalTLD(domain string, canonical map[string]string) string {
+	for i := strings.IndexByte(domain, '.'); i > 0; {
+		if tld, found := canonical[FoldDomain(domain[i+1:])]; found {
+			return domain[:i+1] + tld
+		}
+		next := strings.IndexByte(domain[i+1:], '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return domain
+}
@@ -0,0 +1,116 @@
+package customerimporter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	data := []DomainData{
+		{Domain: "alpha.com", CustomerQuantity: 1},
+		{Domain: "beta.com", CustomerQuantity: 7},
+		{Domain: "gamma.com", CustomerQuantity: 3},
+		{Domain: "delta.com", CustomerQuantity: 5},
+		{Domain: "epsilon.com", CustomerQuantity: 2},
+	}
+	original := append([]DomainData(nil), data...)
+
+	tests := []struct {
+		name     string
+		pipeline Pipeline
+		want     []string
+	}{
+		{name: "empty", pipeline: nil, want: []string{"alpha.com", "beta.com", "gamma.com", "delta.com", "epsilon.com"}},
+		{name: "min-count", pipeline: Pipeline{MinCount(3)}, want: []string{"beta.com", "gamma.com", "delta.com"}},
+		{name: "min-count then top-n", pipeline: Pipeline{MinCount(2), TopN(3)}, want: []string{"beta.com", "delta.com", "gamma.com"}},
+		{name: "top-n larger than data", pipeline: Pipeline{MinCount(5), TopN(10)}, want: []string{"beta.com", "delta.com"}},
+		{name: "exclude then top-n", pipeline: Pipeline{Exclude([]string{"beta.com"}), TopN(2)}, want: []string{"delta.com", "gamma.com"}},
+		{name: "top-n then exclude", pipeline: Pipeline{TopN(2), Exclude([]string{"beta.com"})}, want: []string{"delta.com"}},
+		{name: "match domain", pipeline: Pipeline{MatchDomain(DomainContains("TA"))}, want: []string{"beta.com", "delta.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range tt.pipeline.Apply(data) {
+				got = append(got, d.Domain)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !reflect.DeepEqual(data, original) {
+		t.Errorf("stages modified their input: %+v", data)
+	}
+}
+
+func TestPipelineThen(t *testing.T) {
+	base := Pipeline{MinCount(1)}
+	withTop := base.Then(TopN(1))
+	withExclude := base.Then(Exclude([]string{"a.com"}))
+
+	data := []DomainData{{Domain: "a.com", CustomerQuantity: 2}, {Domain: "b.com", CustomerQuantity: 1}}
+	if got := withTop.Apply(data); len(got) != 1 || got[0].Domain != "a.com" {
+		t.Errorf("min-count then top-n = %+v, want a.com", got)
+	}
+	if got := withExclude.Apply(data); len(got) != 1 || got[0].Domain != "b.com" {
+		t.Errorf("min-count then exclude = %+v, want b.com", got)
+	}
+}
+
+func TestMergeEquivalentTLDs(t *testing.T) {
+	data := []DomainData{
+		{Domain: "brand.co", CustomerQuantity: 2, SampleEmail: "a@brand.co", RoleAccounts: 1},
+		{Domain: "brand.co.uk", CustomerQuantity: 4},
+		{Domain: "brand.com", CustomerQuantity: 3, SampleEmail: "b@brand.com", RoleAccounts: 1},
+		{Domain: "co", CustomerQuantity: 1},
+		{Domain: "other.com", CustomerQuantity: 5},
+		{Domain: "shop.BRAND.CO", CustomerQuantity: 6},
+	}
+	original := append([]DomainData(nil), data...)
+
+	got := MergeEquivalentTLDs([][]string{{"com", "CO"}, {"de", "at"}})(data)
+	want := []DomainData{
+		{Domain: "brand.com", CustomerQuantity: 5, SampleEmail: "a@brand.co", RoleAccounts: 2},
+		{Domain: "brand.co.uk", CustomerQuantity: 4},
+		{Domain: "co", CustomerQuantity: 1},
+		{Domain: "other.com", CustomerQuantity: 5},
+		{Domain: "shop.BRAND.com", CustomerQuantity: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeEquivalentTLDs() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(data, original) {
+		t.Errorf("stage modified its input: %+v", data)
+	}
+
+	got = MergeEquivalentTLDs([][]string{{"uk", "co.uk"}})(data)
+	if got[1].Domain != "brand.uk" {
+		t.Errorf("multi-label TLD: %q, want brand.uk", got[1].Domain)
+	}
+}
+
+func TestSort(t *testing.T) {
+	data := MergeEquivalentTLDs([][]string{{"de", "com"}})([]DomainData{
+		{Domain: "brand.com", CustomerQuantity: 3},
+		{Domain: "brand.cz", CustomerQuantity: 1},
+	})
+	original := append([]DomainData(nil), data...)
+
+	got := Sort(SortByDomain)(data)
+	want := []DomainData{{Domain: "brand.cz", CustomerQuantity: 1}, {Domain: "brand.de", CustomerQuantity: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sort(SortByDomain) = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(data, original) {
+		t.Errorf("stage modified its input: %+v", data)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Sort() with an unsupported order did not panic")
+		}
+	}()
+	Sort("size")
+}
@@ -0,0 +1,49 @@
+package customerimporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// lineReader applies a line preprocessor to every raw line of r before the result is parsed.
+// Line endings ("\n" or "\r\n") are passed to fn stripped and restored afterwards.
+type lineReader struct {
+	r   *bufio.Reader
+	fn  func(string) string
+	buf []byte
+	err error
+}
+
+func newLineReader(r io.Reader, fn func(string) string) *lineReader {
+	return &lineReader{r: bufio.NewReader(r), fn: fn}
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	for len(l.buf) == 0 {
+		if l.err != nil {
+			return 0, l.err
+		}
+		var line string
+		line, l.err = l.r.ReadString('\n')
+		if line == "" {
+			continue
+		}
+		body, ending := splitLineEnding(line)
+		l.buf = append(append(l.buf[:0], l.fn(body)...), ending...)
+	}
+	n := copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+// splitLineEnding separates a trailing "\n" or "\r\n" from line.
+func splitLineEnding(line string) (body, ending string) {
+	if body, found := strings.CutSuffix(line, "\r\n"); found {
+		return body, "\r\n"
+	}
+	if body, found := strings.CutSuffix(line, "\n"); found {
+		return body, "\n"
+	}
+	return line, ""
+}
@@ -0,0 +1,71 @@
+package customerimporter
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLineReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unix endings", input: "a\nbb\n", want: "[a]\n[bb]\n"},
+		{name: "windows endings", input: "a\r\nbb\r\n", want: "[a]\r\n[bb]\r\n"},
+		{name: "no final newline", input: "a\nbb", want: "[a]\n[bb]"},
+		{name: "empty lines", input: "\n\n", want: "[]\n[]\n"},
+		{name: "empty input", input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One byte at a time exercises the buffering across short reads
+			r := newLineReader(iotest.OneByteReader(strings.NewReader(tt.input)), func(line string) string {
+				return "[" + line + "]"
+			})
+			got, err := io.ReadAll(iotest.OneByteReader(r))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("preprocessed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportLinePreprocessor(t *testing.T) {
+	// A log shipper prefixed some lines with a record id, shifting their columns.
+	content := "[export] 1,first_name,last_name,email,gender,ip_address\n" +
+		"[export] 2,John,Doe,john@example.com,Male,192.168.1.1\n" +
+		"[export] 3,Jane,Doe,jane@example.com,Female,192.168.1.2\n" +
+		"Max,Roe,max@other.com,Male,192.168.1.3\n"
+	prefix := regexp.MustCompile(`^\[export\] \d+,`)
+	stripPrefix := func(line string) string {
+		return prefix.ReplaceAllString(line, "")
+	}
+
+	data, _, err := NewCustomerImporter("", WithLinePreprocessor(stripPrefix), WithHeaderValidation()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "other.com", CustomerQuantity: 1},
+	}
+	if len(data) != len(want) || data[0] != want[0] || data[1] != want[1] {
+		t.Errorf("got %+v, want %+v", data, want)
+	}
+
+	// Without the preprocessor the prefixed lines do not parse as the expected layout.
+	if _, _, err := NewCustomerImporter("", WithHeaderValidation()).
+		ImportReader(context.Background(), strings.NewReader(content)); err == nil {
+		t.Error("expected an error without the preprocessor")
+	}
+}
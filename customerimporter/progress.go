@@ -0,0 +1,55 @@
+package customerimporter
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is the number of rows between two progress log lines.
+const progressInterval = 10000
+
+// progress tracks the input read so far and the checkpoint of the last progress log line, so
+// each line can report the throughput over its interval.
+type progress struct {
+	// read counts the input bytes; atomic because a timeout reader reads in a goroutine.
+	read atomic.Int64
+
+	lastTime time.Time
+	lastRows uint64
+	lastRead int64
+}
+
+func newProgress() *progress {
+	return &progress{lastTime: time.Now()}
+}
+
+// count returns r with every byte read from it counted by p.
+func (p *progress) count(r io.Reader) io.Reader {
+	return &countingReader{r: r, read: &p.read}
+}
+
+// rate returns the rows and megabytes (10^6 bytes) per second since the previous checkpoint,
+// given the rows read so far, and moves the checkpoint to now.
+func (p *progress) rate(rows uint64, now time.Time) (rowsPerSec, mbPerSec float64) {
+	read := p.read.Load()
+	if elapsed := now.Sub(p.lastTime).Seconds(); elapsed > 0 {
+		rowsPerSec = math.Round(float64(rows-p.lastRows) / elapsed)
+		mbPerSec = math.Round(float64(read-p.lastRead)/1e6/elapsed*100) / 100
+	}
+	p.lastTime, p.lastRows, p.lastRead = now, rows, read
+	return rowsPerSec, mbPerSec
+}
+
+// countingReader adds the number of bytes read from r to read.
+type countingReader struct {
+	r    io.Reader
+	read *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
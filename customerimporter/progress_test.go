@@ -0,0 +1,78 @@
+package customerimporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressRate(t *testing.T) {
+	p := newProgress()
+	start := p.lastTime
+	p.read.Add(3_000_000)
+
+	rowsPerSec, mbPerSec := p.rate(10000, start.Add(2*time.Second))
+	if rowsPerSec != 5000 || mbPerSec != 1.5 {
+		t.Errorf("first interval rate = %v rows/s, %v MB/s, want 5000 rows/s, 1.5 MB/s", rowsPerSec, mbPerSec)
+	}
+
+	// The second interval only covers what was read since the first checkpoint
+	p.read.Add(500_000)
+	rowsPerSec, mbPerSec = p.rate(20000, start.Add(4*time.Second))
+	if rowsPerSec != 5000 || mbPerSec != 0.25 {
+		t.Errorf("second interval rate = %v rows/s, %v MB/s, want 5000 rows/s, 0.25 MB/s", rowsPerSec, mbPerSec)
+	}
+}
+
+func TestImportProgressLogsRate(t *testing.T) {
+	var logs strings.Builder
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var input strings.Builder
+	input.WriteString("first_name,last_name,email\n")
+	for i := 0; i < 2*progressInterval+500; i++ {
+		fmt.Fprintf(&input, "John,Doe,john%d@example%d.com\n", i, i%10)
+	}
+
+	ci := NewCustomerImporter("")
+	if _, _, err := ci.ImportReader(context.Background(), strings.NewReader(input.String())); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines int
+	scanner := bufio.NewScanner(strings.NewReader(logs.String()))
+	for scanner.Scan() {
+		var entry struct {
+			Msg        string  `json:"msg"`
+			RowsPerSec float64 `json:"rows_per_sec"`
+			MBPerSec   float64 `json:"mb_per_sec"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("unparsable log line %q: %v", scanner.Text(), err)
+		}
+		if entry.Msg != "processing" {
+			continue
+		}
+		lines++
+		// Each interval is 10k rows of about 35 bytes; a rate of zero means the fields are missing
+		if entry.RowsPerSec <= 0 || entry.MBPerSec < 0 {
+			t.Errorf("progress line %d has rate %v rows/s, %v MB/s, want positive rates", lines, entry.RowsPerSec, entry.MBPerSec)
+		}
+		if entry.MBPerSec > 0 {
+			bytesPerRow := entry.MBPerSec * 1e6 / entry.RowsPerSec
+			if bytesPerRow < 10 || bytesPerRow > 100 {
+				t.Errorf("progress line %d implies %.0f bytes per row, want about 35", lines, bytesPerRow)
+			}
+		}
+	}
+	if lines != 2 {
+		t.Errorf("got %d progress lines, want 2", lines)
+	}
+}
@@ -0,0 +1,62 @@
+package customerimporter
+
+import "strings"
+
+// RoleLocalParts lists the local parts of role accounts counted by WithRoleAccounts: shared
+// mailboxes of a function rather than a person, such as "info@" or "support@".
+var RoleLocalParts = []string{
+	"admin", "administrator", "billing", "contact", "enquiries", "hello", "help", "hr",
+	"info", "jobs", "marketing", "no-reply", "noreply", "office", "postmaster", "press",
+	"sales", "security", "support", "team", "webmaster",
+}
+
+// roleMatcher recognizes role accounts by their local part.
+type roleMatcher map[string]struct{}
+
+func newRoleMatcher(locals []string) roleMatcher {
+	m := make(roleMatcher, len(locals))
+	for _, local := range locals {
+		m[strings.ToLower(local)] = struct{}{}
+	}
+	return m
+}
+
+// isRole reports whether the local part of email, ignoring case and a "+tag" suffix, is a role
+// account.
+func (m roleMatcher) isRole(email string) bool {
+	local, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	local, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(local)), "+")
+	_, ok := m[local]
+	return ok
+}
+
+// DomainBreakdown is the analytics view of a domain combining its classifiers: the customer
+// total split into role and personal accounts, and the domain category.
+type DomainBreakdown struct {
+	// Domain is the email domain (e.g., "example.com").
+	Domain string
+	// CustomerQuantity is the number of customers at Domain.
+	CustomerQuantity uint64
+	// RoleAccounts is the number of those customers using a role account such as "info@".
+	RoleAccounts uint64
+	// PersonalAccounts is the number of remaining customers.
+	PersonalAccounts uint64
+	// Category classifies Domain as freemail, corporate or unknown.
+	Category Category
+}
+
+// Breakdown returns the breakdown of every domain of data, in the same order. The role split and
+// category are only meaningful for data imported with WithRoleAccounts and WithCategory.
+func Breakdown(data []DomainData) []DomainBreakdown {
+	breakdown := make([]DomainBreakdown, len(data))
+	for i, d := range data {
+		breakdown[i] = DomainBreakdown{
+			Domain:           d.Domain,
+			CustomerQuantity: d.CustomerQuantity,
+			RoleAccounts:     d.RoleAccounts,
+			PersonalAccounts: d.CustomerQuantity - min(d.RoleAccounts, d.CustomerQuantity),
+			Category:         d.Category,
+		}
+	}
+	return breakdown
+}
@@ -0,0 +1,63 @@
+package customerimporter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoleMatcher(t *testing.T) {
+	m := newRoleMatcher(RoleLocalParts)
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "info@example.com", want: true},
+		{email: " Support@example.com", want: true},
+		{email: "sales+eu@example.com", want: true},
+		{email: "no-reply@example.com", want: true},
+		{email: "john@example.com", want: false},
+		{email: "information@example.com", want: false},
+		{email: "john.info@example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := m.isRole(tt.email); got != tt.want {
+			t.Errorf("isRole(%q) = %t, want %t", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestImportBreakdown(t *testing.T) {
+	content := `first_name,last_name,email
+Info,Desk,info@acme.com
+John,Doe,john@acme.com
+Sales,Team,sales+emea@acme.com
+Jane,Doe,jane@gmail.com
+Help,Desk,support@gmail.com
+Max,Roe,max@localhost`
+
+	data, _, err := NewCustomerImporter("", WithRoleAccounts(), WithCategory()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainBreakdown{
+		{Domain: "acme.com", CustomerQuantity: 3, RoleAccounts: 2, PersonalAccounts: 1, Category: CategoryCorporate},
+		{Domain: "gmail.com", CustomerQuantity: 2, RoleAccounts: 1, PersonalAccounts: 1, Category: CategoryFreemail},
+		{Domain: "localhost", CustomerQuantity: 1, RoleAccounts: 0, PersonalAccounts: 1, Category: CategoryUnknown},
+	}
+	if got := Breakdown(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Breakdown() = %+v, want %+v", got, want)
+	}
+
+	// Without the option no role accounts are counted.
+	data, _, err = NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0].RoleAccounts != 0 {
+		t.Errorf("RoleAccounts = %d without WithRoleAccounts, want 0", data[0].RoleAccounts)
+	}
+}
@@ -0,0 +1,496 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// importRun holds the mutable state of a single import: the aggregation map, the summary and
+// the optional rejects writer.
+type importRun struct {
+	ci      CustomerImporter
+	data    counter
+	summary Summary
+
+	headerWidth  int
+	emailColumns []int
+	lastColumn   int
+
+	// header is the header row of the current input; nil unless SkipRepeatedHeaders is set.
+	header []string
+
+	// rowValues holds the email values of the current row, several per column with
+	// EmailSeparators.
+	rowValues []string
+	// rowDomains collects the domains of the current row so a row is counted all or nothing.
+	rowDomains []string
+	// rowKeys holds the deduplication key of each entry in rowDomains.
+	rowKeys []string
+	// rowEmails holds the validated email of each entry in rowDomains.
+	rowEmails []string
+
+	// months counts each domain per month; nil unless DateColumn is set.
+	months map[monthKey]uint64
+
+	// subnetCounts counts the customers per subnet, once per row; nil unless IPColumn is set.
+	subnetCounts map[netip.Prefix]uint64
+
+	// roles counts the role accounts of each domain; nil unless CountRoleAccounts is set.
+	roles     map[string]uint64
+	roleNames roleMatcher
+
+	// groups maps each case-folded grouped domain to its group; nil unless DomainGroups is set.
+	groups map[string]string
+
+	// casings records the spellings of every domain; nil unless CasingReport is set.
+	casings *casingTracker
+
+	// samples maps each domain to its first counted email, or its last under PreferLastSeen; nil
+	// unless CollectSampleEmail is set.
+	samples map[string]string
+
+	// seen holds the deduplication keys counted so far; nil when deduplication is off.
+	seen          map[string]struct{}
+	dotlessDomain map[string]struct{}
+
+	rejectsFile *os.File
+	rejects     *csv.Writer
+
+	// progress measures the throughput reported by progress log lines.
+	progress *progress
+}
+
+// newImportRun resolves the email columns and opens the rejects output, if configured,
+// writing the input header plus an "error" column to it.
+func (ci CustomerImporter) newImportRun(header []string) (*importRun, error) {
+	emailColumns, err := ci.emailColumns()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseRuneSet(ci.config.LocalPartAllowedChars); err != nil {
+		return nil, err
+	}
+	if ci.config.nilEmailPattern {
+		return nil, fmt.Errorf("invalid email pattern: nil regexp")
+	}
+	if c := ci.config; c.SketchTopK != 0 {
+		if err := validateSketch(c.SketchEpsilon, c.SketchDelta, c.SketchTopK); err != nil {
+			return nil, err
+		}
+		if c.SpillThreshold > 0 || c.TwoPass {
+			return nil, fmt.Errorf("count-min sketch aggregation cannot be combined with external or two-pass aggregation")
+		}
+	}
+
+	run := ci.newRun(ci.newCounter())
+	run.setHeader(header)
+	run.emailColumns = emailColumns
+	run.lastColumn = slices.Max(emailColumns)
+	if column := ci.config.WeightColumn; column != nil {
+		if *column < 0 {
+			return nil, fmt.Errorf("invalid weight column index %d", *column)
+		}
+		run.lastColumn = max(run.lastColumn, *column)
+	}
+	if column := ci.config.CustomerIDColumn; column != nil && ci.config.Deduplicate {
+		if *column < 0 {
+			return nil, fmt.Errorf("invalid customer ID column index %d", *column)
+		}
+		run.lastColumn = max(run.lastColumn, *column)
+	}
+	if column := ci.config.RowFilterColumn; column != nil {
+		if *column < 0 {
+			return nil, fmt.Errorf("invalid row filter column index %d", *column)
+		}
+		run.lastColumn = max(run.lastColumn, *column)
+	}
+	if column := ci.config.DateColumn; column != nil {
+		if *column < 0 {
+			return nil, fmt.Errorf("invalid date column index %d", *column)
+		}
+		run.lastColumn = max(run.lastColumn, *column)
+		run.months = make(map[monthKey]uint64)
+	}
+	if column := ci.config.IPColumn; column != nil {
+		if *column < 0 {
+			return nil, fmt.Errorf("invalid IP column index %d", *column)
+		}
+		if ci.config.IPPrefixLen < 0 || ci.config.IPPrefixLen > 32 {
+			return nil, fmt.Errorf("invalid IPv4 prefix length %d: want 0 to 32", ci.config.IPPrefixLen)
+		}
+		run.lastColumn = max(run.lastColumn, *column)
+		run.subnetCounts = make(map[netip.Prefix]uint64)
+	}
+
+	if ci.config.RejectsOutput != "" {
+		file, err := os.Create(ci.config.RejectsOutput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rejects file: %w", err)
+		}
+		run.rejectsFile = file
+		run.rejects = csv.NewWriter(file)
+		if err := run.rejects.Write(append(slices.Clip(header), "error")); err != nil {
+			_ = run.close()
+			return nil, fmt.Errorf("failed to write rejects file: %w", err)
+		}
+	}
+	return run, nil
+}
+
+// newRun returns a run aggregating into data with the per-domain state enabled in the config,
+// but nothing tied to the input rows.
+func (ci CustomerImporter) newRun(data counter) *importRun {
+	run := &importRun{ci: ci, data: data, progress: newProgress()}
+	if ci.config.CountRoleAccounts {
+		run.roles = make(map[string]uint64)
+		run.roleNames = newRoleMatcher(RoleLocalParts)
+	}
+	if ci.config.CollectSampleEmail {
+		run.samples = make(map[string]string)
+	}
+	if ci.config.CasingReport != nil && !ci.config.collectDomains {
+		// A first pass of WithTwoPassAggregation sees the same rows again, so only the second reports
+		run.casings = newCasingTracker()
+	}
+	if ci.config.DomainGroups != nil {
+		run.groups = domainGroupIndex(ci.config.DomainGroups)
+	}
+	if ci.config.Deduplicate {
+		run.seen = make(map[string]struct{})
+		run.dotlessDomain = make(map[string]struct{}, len(ci.config.DotInsensitiveDomains))
+		for _, domain := range ci.config.DotInsensitiveDomains {
+			run.dotlessDomain[FoldDomain(domain)] = struct{}{}
+		}
+	}
+	return run
+}
+
+// setHeader records the header row of the input being read.
+func (r *importRun) setHeader(header []string) {
+	r.headerWidth = len(header)
+	if r.ci.config.SkipRepeatedHeaders {
+		r.header = slices.Clone(header)
+	}
+}
+
+// isRepeatedHeader reports whether line repeats the header under SkipRepeatedHeaders.
+func (r *importRun) isRepeatedHeader(line []string) bool {
+	return r.header != nil && slices.Equal(line, r.header)
+}
+
+// processRow validates every email of line and counts their domains. Nothing is counted when
+// any email of the row is invalid.
+func (r *importRun) processRow(line []string) error {
+	if r.ci.config.PadShortRows && len(line) < r.headerWidth {
+		line = append(slices.Clip(line), make([]string, r.headerWidth-len(line))...)
+		r.summary.PaddedRows++
+	}
+
+	// Validate CSV has enough columns
+	if len(line) <= r.lastColumn {
+		return fmt.Errorf("%w: expected at least %d columns, got %d", errTooFewColumns, r.lastColumn+1, len(line))
+	}
+
+	if !r.ci.matchesRowFilter(line) {
+		r.summary.FilteredRows++
+		return nil
+	}
+
+	if err := r.ci.validateRow(line); err != nil {
+		return err
+	}
+
+	id, err := r.rowCustomerID(line)
+	if err != nil {
+		return err
+	}
+
+	r.rowDomains = r.rowDomains[:0]
+	r.rowKeys = r.rowKeys[:0]
+	r.rowEmails = r.rowEmails[:0]
+	var trimmed uint64
+	r.rowValues = r.rowValues[:0]
+	for i, column := range r.emailColumns {
+		value := line[column]
+		// Secondary email columns are optional
+		if i > 0 && strings.TrimSpace(value) == "" {
+			continue
+		}
+		r.rowValues = r.ci.appendEmailValues(r.rowValues, value)
+	}
+	for _, value := range r.rowValues {
+		// Validate email and extract domain
+		value, err := r.ci.emailField(value)
+		if err != nil {
+			return fmt.Errorf("invalid email in CSV: %w", err)
+		}
+		domain, err := r.ci.extractDomain(value)
+		if err != nil {
+			return fmt.Errorf("invalid email in CSV: %w", err)
+		}
+		if strings.TrimSpace(value) != value {
+			trimmed++
+		}
+		r.rowDomains = append(r.rowDomains, domain)
+		r.rowEmails = append(r.rowEmails, value)
+		if r.seen != nil {
+			r.rowKeys = append(r.rowKeys, r.dedupKey(id, value, domain))
+		}
+	}
+
+	weight := r.sampleWeight()
+	if r.ci.config.WeightColumn != nil {
+		w, ok, err := r.rowWeight(line)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if w > math.MaxUint64/weight {
+			return fmt.Errorf("weight %d scaled by the sampling factor %d overflows uint64", w, weight)
+		}
+		weight *= w
+	}
+	if r.ci.config.WeightColumn != nil || weight > 1 {
+		for _, domain := range r.rowDomains {
+			if r.data.count(domain) > math.MaxUint64-weight {
+				return fmt.Errorf("count for domain %q overflows uint64", domain)
+			}
+		}
+	}
+
+	var month string
+	if r.months != nil {
+		m, ok, err := r.rowMonth(line)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		month = m
+	}
+	var subnet netip.Prefix
+	if r.subnetCounts != nil {
+		if subnet, err = r.rowSubnet(line); err != nil {
+			return err
+		}
+	}
+
+	r.summary.TrimmedValues += trimmed
+	counted := false
+	for i, domain := range r.rowDomains {
+		var key string
+		if r.seen != nil {
+			key = r.rowKeys[i]
+		}
+		if r.countDomain(domain, r.rowEmails[i], key, month, weight) {
+			counted = true
+		}
+	}
+	// The row is one customer of its subnet, however many emails it holds
+	if counted && r.subnetCounts != nil {
+		r.subnetCounts[subnet] += weight
+	}
+	return nil
+}
+
+// errTooFewColumns is wrapped by the row error of a row lacking a configured column.
+var errTooFewColumns = errors.New("invalid CSV format")
+
+// countDomain adds weight to domain for one validated email, unless the DomainFilter drops the
+// domain or key was already counted under deduplication, and reports whether it was counted.
+func (r *importRun) countDomain(domain, email, key, month string, weight uint64) bool {
+	if keep := r.ci.config.DomainFilter; keep != nil && !keep(domain) {
+		return false
+	}
+	if r.casings != nil {
+		r.casings.add(domain)
+	}
+	if r.groups != nil {
+		domain = r.group(domain)
+	}
+	if r.seen != nil {
+		if _, dup := r.seen[key]; dup {
+			r.summary.Duplicates++
+			return false
+		}
+		r.seen[key] = struct{}{}
+	}
+	if r.samples != nil {
+		if _, ok := r.samples[domain]; !ok || r.ci.config.PreferLastSeen {
+			r.samples[domain] = sampleEmail(email, domain)
+		}
+	}
+	r.data.add(domain, weight)
+	if r.roles != nil && r.roleNames.isRole(email) {
+		r.roles[domain] += weight
+	}
+	if r.months != nil {
+		r.months[monthKey{domain, month}] += weight
+	}
+	return true
+}
+
+// handleRowError applies the configured ErrorPolicy to a row that failed with rowErr. It returns
+// the error that should abort the import, or nil when the row was skipped.
+func (r *importRun) handleRowError(line []string, rowErr error) error {
+	if r.ci.config.ErrorPolicy != ErrorPolicySkip {
+		return rowFailure{rowErr}
+	}
+
+	r.summary.SkippedRows++
+	r.summary.SkipReasons[skipReason(rowErr)]++
+	slog.Debug("skipping invalid row", "row", r.summary.Rows, "error", rowErr)
+	if r.rejects != nil {
+		if err := r.rejects.Write(append(slices.Clip(line), rowErr.Error())); err != nil {
+			return fmt.Errorf("failed to write rejects file: %w", err)
+		}
+	}
+	return nil
+}
+
+// rowFailure marks the error of an invalid row that aborted the import, telling it apart from
+// failures of the run itself, such as an unreadable input, in ImportMetrics.Errors.
+type rowFailure struct {
+	error
+}
+
+func (f rowFailure) Unwrap() error { return f.error }
+
+// result returns the sorted aggregation and the completed summary. It only fails when merging an
+// external aggregation fails.
+func (r *importRun) result() ([]DomainData, Summary, error) {
+	data := make([]DomainData, 0, r.data.len())
+	summary, err := r.each(func(d DomainData) error {
+		data = append(data, d)
+		return nil
+	})
+	return data, summary, err
+}
+
+// each completes the summary and calls fn with every aggregated domain in alphabetical order,
+// stopping at the first error. Only the sorted domain names are allocated on top of the map.
+func (r *importRun) each(fn func(DomainData) error) (Summary, error) {
+	if external, ok := r.data.(*spillCounter); ok {
+		return r.eachExternal(external, fn)
+	}
+	r.summary.finish(r.data)
+	if sketch, ok := r.data.(*sketchCounter); ok {
+		// The estimates of the tracked domains do not add up to the customers counted
+		r.summary.Customers = sketch.total
+	}
+	domains := make([]string, 0, r.data.len())
+	r.data.each(func(domain string, _ uint64) {
+		domains = append(domains, domain)
+	})
+	if ensured := r.ci.ensuredDomains(); len(ensured) > 0 {
+		// Checked against the listed domains rather than their counts: a sketch estimates a
+		// domain it does not list, possibly above zero
+		listed := make(map[string]bool, len(domains))
+		for _, domain := range domains {
+			listed[domain] = true
+		}
+		for _, domain := range ensured {
+			if !listed[domain] {
+				domains = append(domains, domain)
+			}
+		}
+	}
+	slices.Sort(domains)
+
+	categories := r.categorizer()
+	for _, domain := range domains {
+		if err := fn(r.domainData(domain, r.data.count(domain), categories)); err != nil {
+			return r.summary, err
+		}
+	}
+	return r.summary, nil
+}
+
+// eachExternal is each for WithExternalAggregation: the domains come sorted from the merge of
+// the spilled runs, and the summary totals are counted along the way. Missing EnsureDomains are
+// merged into the sorted stream.
+func (r *importRun) eachExternal(external *spillCounter, fn func(DomainData) error) (Summary, error) {
+	r.summary.Domains, r.summary.Customers = 0, 0
+	categories := r.categorizer()
+	ensured := ensuredMerger{domains: r.ci.ensuredDomains()}
+	err := external.eachSorted(func(domain string, count uint64) error {
+		for _, missing := range ensured.before(domain) {
+			if err := fn(r.domainData(missing, 0, categories)); err != nil {
+				return err
+			}
+		}
+		r.summary.Domains++
+		r.summary.Customers += count
+		return fn(r.domainData(domain, count, categories))
+	})
+	if err != nil {
+		return r.summary, err
+	}
+	for _, missing := range ensured.rest() {
+		if err := fn(r.domainData(missing, 0, categories)); err != nil {
+			return r.summary, err
+		}
+	}
+	return r.summary, nil
+}
+
+// categorizer returns the categorizer for DomainData.Category, or nil unless Categorize is set.
+func (r *importRun) categorizer() *categorizer {
+	if !r.ci.config.Categorize {
+		return nil
+	}
+	providers := r.ci.config.CategoryProviders
+	if providers == nil {
+		providers = FreemailProviders
+	}
+	return newCategorizer(providers, r.ci.config.publicSuffixes())
+}
+
+// domainData builds the result record of domain.
+func (r *importRun) domainData(domain string, count uint64, categories *categorizer) DomainData {
+	d := DomainData{Domain: domain, CustomerQuantity: count, SampleEmail: r.samples[domain], RoleAccounts: r.roles[domain]}
+	if categories != nil {
+		d.Category = categories.classify(domain)
+	}
+	return d
+}
+
+// release deletes the temporary files of an external aggregation once the result was read.
+func (r *importRun) release() {
+	if external, ok := r.data.(*spillCounter); ok {
+		external.remove()
+	}
+}
+
+// sampleEmail renders a validated email with its normalized domain.
+func sampleEmail(email, domain string) string {
+	local, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	return strings.TrimSpace(local) + "@" + domain
+}
+
+// close flushes and closes the rejects output. It is safe to call more than once.
+func (r *importRun) close() error {
+	if r.rejectsFile == nil {
+		return nil
+	}
+	r.rejects.Flush()
+	err := r.rejects.Error()
+	if closeErr := r.rejectsFile.Close(); err == nil {
+		err = closeErr
+	}
+	r.rejectsFile = nil
+	if err != nil {
+		return fmt.Errorf("failed to write rejects file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package customerimporter
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportSkipInvalidRows(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Bad,Row,not-an-email,Male,192.168.1.2
+Short,Row
+Jane,Doe,jane@example.com,Female,192.168.1.3`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	if _, err := NewCustomerImporter(csvPath).ImportDomainData(); err == nil {
+		t.Fatal("default policy did not abort on invalid rows")
+	}
+
+	importer := NewCustomerImporter(csvPath, WithErrorPolicy(ErrorPolicySkip))
+	data, summary, err := importer.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0] != (DomainData{Domain: "example.com", CustomerQuantity: 2}) {
+		t.Errorf("unexpected aggregation: %v", data)
+	}
+	if summary.Rows != 4 || summary.SkippedRows != 2 {
+		t.Errorf("summary rows=%d skipped=%d, want rows=4 skipped=2", summary.Rows, summary.SkippedRows)
+	}
+}
+
+func TestImportSkipCountsRowAllOrNothing(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `name,primary_email,secondary_email
+John,john@example.com,not-an-email
+Jane,jane@example.com,jane@other.org`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	importer := NewCustomerImporter(csvPath, WithEmailColumns([]int{1, 2}), WithErrorPolicy(ErrorPolicySkip))
+	data, err := importer.ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example.com", CustomerQuantity: 1},
+		{Domain: "other.org", CustomerQuantity: 1},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+}
+
+func TestImportRejectsOutput(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := dir + "/test.csv"
+	rejectsPath := dir + "/rejects.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Bad,Row,not-an-email,Male,192.168.1.2
+Jane,Doe,jane@,Female,192.168.1.3
+Jim,Beam,jim@other.org,Male,192.168.1.4`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	importer := NewCustomerImporter(csvPath, WithErrorPolicy(ErrorPolicySkip), WithRejectsOutput(rejectsPath))
+	data, err := importer.ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 {
+		t.Errorf("valid rows not aggregated: %v", data)
+	}
+
+	file, err := os.Open(rejectsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("rejects file has %d records, want header plus 2 rows: %v", len(records), records)
+	}
+	if got := strings.Join(records[0], ","); got != "first_name,last_name,email,gender,ip_address,error" {
+		t.Errorf("rejects header = %q", got)
+	}
+	tests := []struct {
+		email  string
+		reason string
+	}{
+		{email: "not-an-email", reason: "missing '@' separator"},
+		{email: "jane@", reason: "empty domain"},
+	}
+	for i, tt := range tests {
+		record := records[i+1]
+		if len(record) != 6 {
+			t.Errorf("reject %d has %d fields, want 6: %v", i, len(record), record)
+			continue
+		}
+		if record[2] != tt.email {
+			t.Errorf("reject %d email = %q, want %q", i, record[2], tt.email)
+		}
+		if !strings.Contains(record[5], tt.reason) {
+			t.Errorf("reject %d reason = %q, want it to contain %q", i, record[5], tt.reason)
+		}
+	}
+}
+
+func TestImportShortRowPadding(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@example.com,Male,192.168.1.1
+Jane,Doe,jane@example.com
+Jim,Beam,jim@other.org,Male`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	if _, err := NewCustomerImporter(csvPath).ImportDomainData(); err == nil {
+		t.Fatal("short rows accepted without WithShortRowPadding")
+	}
+
+	data, summary, err := NewCustomerImporter(csvPath, WithShortRowPadding()).ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "other.org", CustomerQuantity: 1},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+	if summary.PaddedRows != 2 {
+		t.Errorf("summary.PaddedRows = %d, want 2", summary.PaddedRows)
+	}
+}
+
+func TestImportShortRowPaddingMissingEmail(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	_, err := NewCustomerImporter(csvPath, WithShortRowPadding()).ImportDomainData()
+	if err == nil || !strings.Contains(err.Error(), "email address is empty") {
+		t.Errorf("expected empty email error for row padded past the email column, got %v", err)
+	}
+}
+
+func TestImportSampleEmail(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe, john@www.example.com ,Male,192.168.1.1
+Bad,Row,bad@,Male,192.168.1.2
+Jane,Doe,jane@example.com,Female,192.168.1.3
+Jim,Beam,jim@other.org,Male,192.168.1.4
+Joe,Beam,joe@other.org,Male,192.168.1.5`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	importer := NewCustomerImporter(csvPath, WithSampleEmail(), WithStripWWWPrefix(), WithErrorPolicy(ErrorPolicySkip))
+	data, err := importer.ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example.com", CustomerQuantity: 2, SampleEmail: "john@example.com"},
+		{Domain: "other.org", CustomerQuantity: 2, SampleEmail: "jim@other.org"},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+
+	data, err = NewCustomerImporter(csvPath, WithErrorPolicy(ErrorPolicySkip)).ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range data {
+		if d.SampleEmail != "" {
+			t.Errorf("sample collected without WithSampleEmail: %+v", d)
+		}
+	}
+}
+
+func TestImportPreferLastSeen(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@www.example.com,Male,192.168.1.1
+Jane,Doe,jane@example.com,Female,192.168.1.2
+Jim,Beam,jim@other.org,Male,192.168.1.3
+Bad,Row,bad@,Male,192.168.1.4
+Joe,Beam,joe@other.org,Male,192.168.1.5`
+
+	importer := NewCustomerImporter("", WithSampleEmail(), WithPreferLastSeen(), WithStripWWWPrefix(),
+		WithErrorPolicy(ErrorPolicySkip))
+	data, _, err := importer.ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example.com", CustomerQuantity: 2, SampleEmail: "jane@example.com"},
+		{Domain: "other.org", CustomerQuantity: 2, SampleEmail: "joe@other.org"},
+	}
+	if !slices.Equal(data, want) {
+		t.Errorf("got %+v, want %+v", data, want)
+	}
+
+	// A duplicate is not counted, so it does not become the sample either.
+	content += "\nJohn,Doe,john@example.com,Male,192.168.1.6"
+	data, _, err = NewCustomerImporter("", WithSampleEmail(), WithPreferLastSeen(), WithStripWWWPrefix(),
+		WithDeduplication(), WithErrorPolicy(ErrorPolicySkip)).ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(data, want) {
+		t.Errorf("with deduplication got %+v, want %+v", data, want)
+	}
+}
@@ -0,0 +1,17 @@
+package customerimporter
+
+// sampled reports whether the row just read, counted in Summary.Rows, is processed under
+// WithSampling: the first of every SampleEvery rows.
+func (r *importRun) sampled() bool {
+	n := r.ci.config.SampleEvery
+	return n <= 1 || (r.summary.Rows-1)%uint64(n) == 0
+}
+
+// sampleWeight returns what a sampled row contributes before its weight column: SampleEvery
+// with SampleScale, otherwise one.
+func (r *importRun) sampleWeight() uint64 {
+	if n := r.ci.config.SampleEvery; n > 1 && r.ci.config.SampleScale {
+		return uint64(n)
+	}
+	return 1
+}
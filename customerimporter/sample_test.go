@@ -0,0 +1,77 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestImportSampling(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("first_name,last_name,email,weight\n")
+	for i := 0; i < 10; i++ {
+		// Only the sampled rows 0, 3, 6 and 9 must be valid
+		email := "invalid"
+		if i%3 == 0 {
+			email = fmt.Sprintf("john%d@a.com", i)
+			if i%2 == 1 {
+				email = fmt.Sprintf("john%d@b.com", i)
+			}
+		}
+		fmt.Fprintf(&sb, "John,Doe,%s,2\n", email)
+	}
+	content := sb.String()
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "stride", opts: []Option{WithSampling(3, false)}, want: "[a.com:2 b.com:2]"},
+		{name: "scaled", opts: []Option{WithSampling(3, true)}, want: "[a.com:6 b.com:6]"},
+		{name: "scaled weights", opts: []Option{WithSampling(3, true), WithWeightColumn(3)}, want: "[a.com:12 b.com:12]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, summary, err := NewCustomerImporter("", tt.opts...).
+				ImportReader(context.Background(), strings.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range data {
+				got = append(got, fmt.Sprintf("%s:%d", d.Domain, d.CustomerQuantity))
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+			if summary.Rows != 10 || summary.UnsampledRows != 6 {
+				t.Errorf("summary counted %d rows, %d unsampled, want 10 and 6", summary.Rows, summary.UnsampledRows)
+			}
+		})
+	}
+}
+
+func TestImportSamplingErrorRate(t *testing.T) {
+	// Rows 1, 3 and 5 are sampled; one of them is invalid
+	content := "first_name,last_name,email\n" +
+		"John,Doe,john@example.com\n" +
+		"Skip,Doe,invalid\n" +
+		"Jane,Doe,invalid\n" +
+		"Skip,Doe,invalid\n" +
+		"Max,Roe,max@example.com\n"
+	opts := []Option{WithSampling(2, false), WithErrorPolicy(ErrorPolicySkip)}
+
+	_, _, err := NewCustomerImporter("", append(opts, WithMaxErrorRate(0.4))...).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Errorf("1 of 3 sampled rows invalid exceeded a 40%% error rate: %v", err)
+	}
+	_, _, err = NewCustomerImporter("", append(opts, WithMaxErrorRate(0.3))...).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 rows invalid") {
+		t.Errorf("expected 1 of 3 rows error rate failure, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrShareExceeded is returned by CheckMaxShare when a single domain holds too large a share of
+// all customers.
+var ErrShareExceeded = errors.New("domain share exceeded")
+
+// CheckMaxShare fails with an error wrapping ErrShareExceeded when a domain accounts for more
+// than limit of all customers in data, e.g. 0.4 for 40%. Such a concentration often points at
+// skewed data or a bot rather than real customers. The error names the domain with the largest
+// share, the alphabetically first on ties. A limit of zero or less disables the check.
+func CheckMaxShare(data []DomainData, limit float64) error {
+	if limit <= 0 {
+		return nil
+	}
+	var total uint64
+	for _, d := range data {
+		total += d.CustomerQuantity
+	}
+	if total == 0 {
+		return nil
+	}
+	top, _ := MaxDomain(data)
+	share := float64(top.CustomerQuantity) / float64(total)
+	if share <= limit {
+		return nil
+	}
+	return fmt.Errorf("%w: %q has %d of %d customers (%.1f%%, limit %.1f%%)",
+		ErrShareExceeded, top.Domain, top.CustomerQuantity, total, share*100, limit*100)
+}
@@ -0,0 +1,63 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckMaxShare(t *testing.T) {
+	balanced := []DomainData{
+		{Domain: "a.com", CustomerQuantity: 4},
+		{Domain: "b.com", CustomerQuantity: 3},
+		{Domain: "c.com", CustomerQuantity: 3},
+	}
+	tests := []struct {
+		name    string
+		data    []DomainData
+		limit   float64
+		wantErr string
+	}{
+		{name: "at the limit", data: balanced, limit: 0.4},
+		{name: "above the limit", data: balanced, limit: 0.35, wantErr: `"a.com" has 4 of 10 customers (40.0%, limit 35.0%)`},
+		{name: "disabled", data: balanced, limit: 0},
+		{name: "tie names the first domain", data: []DomainData{
+			{Domain: "b.com", CustomerQuantity: 1},
+			{Domain: "a.com", CustomerQuantity: 1},
+		}, limit: 0.4, wantErr: `"a.com"`},
+		{name: "no customers", data: []DomainData{{Domain: "a.com"}}, limit: 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckMaxShare(tt.data, tt.limit)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckMaxShare() unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrShareExceeded) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CheckMaxShare() = %v, want %v containing %q", err, ErrShareExceeded, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckMaxShareSkewedImport(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+Bot,One,bot1@spam.example,Male,10.0.0.1
+Bot,Two,bot2@spam.example,Male,10.0.0.1
+Bot,Three,bot3@spam.example,Male,10.0.0.1
+John,Doe,john@example.com,Male,192.168.1.1
+Jane,Doe,jane@other.org,Female,192.168.1.2`
+
+	data, _, err := NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CheckMaxShare(data, 0.4)
+	if !errors.Is(err, ErrShareExceeded) || !strings.Contains(err.Error(), "spam.example") {
+		t.Errorf("CheckMaxShare() = %v, want spam.example exceeding the share limit", err)
+	}
+}
@@ -0,0 +1,150 @@
+package customerimporter
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// sketchCounter is the counter of WithCountMinSketch: a count-min sketch of depth rows of width
+// counters each, estimating the count of any domain in fixed memory, plus the topK domains with
+// the highest estimates, the only ones it can enumerate.
+type sketchCounter struct {
+	width uint64
+	depth int
+	// cells holds the depth rows of width counters one after another.
+	cells []uint64
+	// total is the exact sum of everything added.
+	total uint64
+
+	topK int
+	top  sketchHeap
+}
+
+// newSketchCounter returns a sketch overestimating each count by at most epsilon times the total
+// with probability 1-delta, tracking the topK domains with the highest estimates.
+func newSketchCounter(epsilon, delta float64, topK int) *sketchCounter {
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	return &sketchCounter{
+		width: width,
+		depth: depth,
+		cells: make([]uint64, width*uint64(depth)),
+		topK:  topK,
+		top:   sketchHeap{index: make(map[string]int, topK)},
+	}
+}
+
+// validateSketch checks the WithCountMinSketch parameters.
+func validateSketch(epsilon, delta float64, topK int) error {
+	if !(epsilon > 0 && epsilon < 1) {
+		return fmt.Errorf("invalid count-min sketch error bound %v: want a value between 0 and 1", epsilon)
+	}
+	if !(delta > 0 && delta < 1) {
+		return fmt.Errorf("invalid count-min sketch failure probability %v: want a value between 0 and 1", delta)
+	}
+	if topK <= 0 {
+		return fmt.Errorf("invalid count-min sketch domain limit %d: want a positive number", topK)
+	}
+	return nil
+}
+
+// sketchHashes returns the two hashes of domain from which the column of every row is derived,
+// (h1 + row*h2) mod width (Kirsch and Mitzenmacher). h1 is 64-bit FNV-1a, computed inline so
+// hashing allocates nothing, and h2 is h1 mixed by the SplitMix64 finalizer, made odd.
+func sketchHashes(domain string) (h1, h2 uint64) {
+	h1 = 14695981039346656037
+	for i := 0; i < len(domain); i++ {
+		h1 ^= uint64(domain[i])
+		h1 *= 1099511628211
+	}
+	h2 = h1
+	h2 = (h2 ^ h2>>30) * 0xbf58476d1ce4e5b9
+	h2 = (h2 ^ h2>>27) * 0x94d049bb133111eb
+	h2 ^= h2 >> 31
+	return h1, h2 | 1
+}
+
+func (s *sketchCounter) add(domain string, n uint64) {
+	h1, h2 := sketchHashes(domain)
+	estimate := uint64(math.MaxUint64)
+	for row := 0; row < s.depth; row++ {
+		cell := &s.cells[uint64(row)*s.width+(h1+uint64(row)*h2)%s.width]
+		*cell += n
+		estimate = min(estimate, *cell)
+	}
+	s.total += n
+
+	if i, ok := s.top.index[domain]; ok {
+		s.top.entries[i].estimate = estimate
+		heap.Fix(&s.top, i)
+		return
+	}
+	if len(s.top.entries) < s.topK {
+		heap.Push(&s.top, sketchEntry{domain: domain, estimate: estimate})
+		return
+	}
+	if lowest := s.top.entries[0]; estimate > lowest.estimate {
+		delete(s.top.index, lowest.domain)
+		s.top.entries[0] = sketchEntry{domain: domain, estimate: estimate}
+		s.top.index[domain] = 0
+		heap.Fix(&s.top, 0)
+	}
+}
+
+// count returns the estimate of domain: the smallest of its counters, which never undercounts.
+func (s *sketchCounter) count(domain string) uint64 {
+	h1, h2 := sketchHashes(domain)
+	estimate := uint64(math.MaxUint64)
+	for row := 0; row < s.depth; row++ {
+		estimate = min(estimate, s.cells[uint64(row)*s.width+(h1+uint64(row)*h2)%s.width])
+	}
+	return estimate
+}
+
+// len returns the number of tracked domains, at most topK.
+func (s *sketchCounter) len() int { return len(s.top.entries) }
+
+// each calls fn for every tracked domain with its current estimate, which may have grown since
+// the domain was last added through collisions with other domains.
+func (s *sketchCounter) each(fn func(domain string, count uint64)) {
+	for _, e := range s.top.entries {
+		fn(e.domain, s.count(e.domain))
+	}
+}
+
+// sketchEntry is a tracked domain with its estimate as of its last add.
+type sketchEntry struct {
+	domain   string
+	estimate uint64
+}
+
+// sketchHeap is a min-heap of the tracked domains by estimate, indexed by domain so a tracked
+// domain is updated in place.
+type sketchHeap struct {
+	entries []sketchEntry
+	index   map[string]int
+}
+
+func (h *sketchHeap) Len() int { return len(h.entries) }
+
+func (h *sketchHeap) Less(i, j int) bool { return h.entries[i].estimate < h.entries[j].estimate }
+
+func (h *sketchHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.index[h.entries[i].domain] = i
+	h.index[h.entries[j].domain] = j
+}
+
+func (h *sketchHeap) Push(x any) {
+	e := x.(sketchEntry)
+	h.index[e.domain] = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *sketchHeap) Pop() any {
+	e := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	delete(h.index, e.domain)
+	return e
+}
@@ -0,0 +1,93 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// skewedCSV returns an input in which domain i of domains has about domains/i customers, so a
+// few large domains stand out from a long tail, together with the total number of rows.
+func skewedCSV(domains int) (string, uint64) {
+	var sb strings.Builder
+	var rows uint64
+	sb.WriteString("first_name,last_name,email,gender,ip_address\n")
+	for i := 1; i <= domains; i++ {
+		for n := 0; n < domains/i; n++ {
+			fmt.Fprintf(&sb, "A,B,user%d@domain%d.com,Male,10.0.0.1\n", n, i)
+			rows++
+		}
+	}
+	return sb.String(), rows
+}
+
+func TestWithCountMinSketch(t *testing.T) {
+	const (
+		epsilon = 0.01
+		delta   = 0.01
+		topK    = 20
+	)
+	content, rows := skewedCSV(2000)
+
+	exact, _, err := NewCustomerImporter("").ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]uint64, len(exact))
+	for _, d := range exact {
+		counts[d.Domain] = d.CustomerQuantity
+	}
+
+	estimated, summary, err := NewCustomerImporter("", WithCountMinSketch(epsilon, delta, topK)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(estimated) != topK || summary.Domains != topK {
+		t.Fatalf("got %d domains (summary %d), want %d", len(estimated), summary.Domains, topK)
+	}
+	if summary.Customers != rows {
+		t.Errorf("Summary.Customers = %d, want the exact %d", summary.Customers, rows)
+	}
+
+	bound := uint64(epsilon * float64(rows))
+	for _, d := range estimated {
+		want := counts[d.Domain]
+		if d.CustomerQuantity < want || d.CustomerQuantity-want > bound {
+			t.Errorf("%s estimated at %d, want between %d and %d", d.Domain, d.CustomerQuantity, want, want+bound)
+		}
+	}
+	// Domains larger than the error bound by a wide margin cannot be displaced by the tail.
+	reported := make(map[string]bool, len(estimated))
+	for _, d := range estimated {
+		reported[d.Domain] = true
+	}
+	for i := 1; i <= 10; i++ {
+		if domain := fmt.Sprintf("domain%d.com", i); !reported[domain] {
+			t.Errorf("large domain %s with %d customers not reported", domain, counts[domain])
+		}
+	}
+}
+
+func TestWithCountMinSketchInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{name: "epsilon", opts: []Option{WithCountMinSketch(0, 0.01, 10)}, wantErr: "error bound"},
+		{name: "delta", opts: []Option{WithCountMinSketch(0.01, 1, 10)}, wantErr: "failure probability"},
+		{name: "top", opts: []Option{WithCountMinSketch(0.01, 0.01, -1)}, wantErr: "domain limit"},
+		{name: "external", opts: []Option{WithCountMinSketch(0.01, 0.01, 10), WithExternalAggregation(10, "")}, wantErr: "cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewCustomerImporter("", tt.opts...).
+				ImportReader(context.Background(), strings.NewReader("a,b,email\nJohn,Doe,john@example.com\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportReader() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
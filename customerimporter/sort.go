@@ -0,0 +1,40 @@
+package customerimporter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// SortOrder selects how SortDomainData orders domains.
+type SortOrder string
+
+const (
+	// SortByDomain orders domains alphabetically. It is the import order.
+	SortByDomain SortOrder = "domain"
+	// SortByCount orders domains by descending CustomerQuantity, alphabetically among equal counts.
+	SortByCount SortOrder = "count"
+)
+
+// SortDomainData sorts data in place by order. The result is deterministic for any input order
+// as long as domains are unique.
+func SortDomainData(data []DomainData, order SortOrder) error {
+	switch order {
+	case SortByDomain:
+		slices.SortFunc(data, compareDomain)
+	case SortByCount:
+		slices.SortFunc(data, func(l, r DomainData) int {
+			if c := cmp.Compare(r.CustomerQuantity, l.CustomerQuantity); c != 0 {
+				return c
+			}
+			return compareDomain(l, r)
+		})
+	default:
+		return fmt.Errorf("unsupported sort order %q (want %q or %q)", order, SortByDomain, SortByCount)
+	}
+	return nil
+}
+
+func compareDomain(l, r DomainData) int {
+	return cmp.Compare(l.Domain, r.Domain)
+}
@@ -0,0 +1,47 @@
+package customerimporter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortDomainData(t *testing.T) {
+	input := []DomainData{
+		{Domain: "b.com", CustomerQuantity: 2},
+		{Domain: "c.com", CustomerQuantity: 5},
+		{Domain: "a.com", CustomerQuantity: 2},
+	}
+
+	tests := []struct {
+		order   SortOrder
+		want    []string
+		wantErr bool
+	}{
+		{order: SortByDomain, want: []string{"a.com", "b.com", "c.com"}},
+		{order: SortByCount, want: []string{"c.com", "a.com", "b.com"}},
+		{order: "size", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			data := slices.Clone(input)
+			err := SortDomainData(data, tt.order)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SortDomainData(%q) expected error, got nil", tt.order)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(data))
+			for i, d := range data {
+				got[i] = d.Domain
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SortDomainData(%q) = %v, want %v", tt.order, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package customerimporter
+
+// MaxDomain returns the domain with the most customers, the alphabetically first one among
+// equal counts. ok is false, with a zero DomainData, when data is empty.
+func MaxDomain(data []DomainData) (d DomainData, ok bool) {
+	return extremeDomain(data, func(d, best DomainData) bool {
+		return d.CustomerQuantity > best.CustomerQuantity
+	})
+}
+
+// MinDomain returns the domain with the fewest customers, the alphabetically first one among
+// equal counts. ok is false, with a zero DomainData, when data is empty.
+func MinDomain(data []DomainData) (d DomainData, ok bool) {
+	return extremeDomain(data, func(d, best DomainData) bool {
+		return d.CustomerQuantity < best.CustomerQuantity
+	})
+}
+
+// extremeDomain returns the record of data for which better holds against all others, breaking
+// ties by domain so the result does not depend on the order of data.
+func extremeDomain(data []DomainData, better func(d, best DomainData) bool) (DomainData, bool) {
+	if len(data) == 0 {
+		return DomainData{}, false
+	}
+	best := data[0]
+	for _, d := range data[1:] {
+		if better(d, best) || d.CustomerQuantity == best.CustomerQuantity && d.Domain < best.Domain {
+			best = d
+		}
+	}
+	return best, true
+}
@@ -0,0 +1,72 @@
+package customerimporter
+
+import "testing"
+
+func TestMaxMinDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []DomainData
+		wantMax string
+		wantMin string
+		wantOK  bool
+	}{
+		{name: "empty", data: nil},
+		{
+			name:    "single",
+			data:    []DomainData{{Domain: "only.com", CustomerQuantity: 3}},
+			wantMax: "only.com",
+			wantMin: "only.com",
+			wantOK:  true,
+		},
+		{
+			name: "distinct counts",
+			data: []DomainData{
+				{Domain: "mid.com", CustomerQuantity: 5},
+				{Domain: "big.com", CustomerQuantity: 9},
+				{Domain: "small.com", CustomerQuantity: 1},
+			},
+			wantMax: "big.com",
+			wantMin: "small.com",
+			wantOK:  true,
+		},
+		{
+			name: "ties broken alphabetically",
+			data: []DomainData{
+				{Domain: "zeta.com", CustomerQuantity: 9},
+				{Domain: "omega.com", CustomerQuantity: 1},
+				{Domain: "alpha.com", CustomerQuantity: 9},
+				{Domain: "beta.com", CustomerQuantity: 1},
+			},
+			wantMax: "alpha.com",
+			wantMin: "beta.com",
+			wantOK:  true,
+		},
+		{
+			name: "all equal",
+			data: []DomainData{
+				{Domain: "c.com", CustomerQuantity: 2},
+				{Domain: "a.com", CustomerQuantity: 2},
+				{Domain: "b.com", CustomerQuantity: 2},
+			},
+			wantMax: "a.com",
+			wantMin: "a.com",
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxDomain, ok := MaxDomain(tt.data)
+			if ok != tt.wantOK || maxDomain.Domain != tt.wantMax {
+				t.Errorf("MaxDomain() = %+v, %t; want %q, %t", maxDomain, ok, tt.wantMax, tt.wantOK)
+			}
+			minDomain, ok := MinDomain(tt.data)
+			if ok != tt.wantOK || minDomain.Domain != tt.wantMin {
+				t.Errorf("MinDomain() = %+v, %t; want %q, %t", minDomain, ok, tt.wantMin, tt.wantOK)
+			}
+			if !ok && (maxDomain != DomainData{} || minDomain != DomainData{}) {
+				t.Errorf("empty input returned non-zero records %+v, %+v", maxDomain, minDomain)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package customerimporter
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// StreamDomainData is like ImportWithSummary but hands each aggregated domain to fn, in the same
+// alphabetical order, instead of returning a slice, e.g. to write records straight to an output.
+//
+// Sorting still needs every domain, so the aggregation map is held for the whole import; what
+// is saved is the []DomainData copy of it. The only extra allocation is a slice of the sorted
+// domain names (one string header per domain instead of a full DomainData record), and each
+// record is built just before it is passed to fn. Map entries are not deleted along the way
+// since Go maps do not shrink on delete.
+//
+// fn is not called when the import fails. On cancellation fn receives the domains aggregated so
+// far and the returned error wraps ErrPartialResult. An error returned by fn stops the stream and
+// is returned as is.
+func (ci CustomerImporter) StreamDomainData(ctx context.Context, fn func(DomainData) error) (Summary, error) {
+	file, err := os.Open(ci.path)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ci.streamWith(func() (*importRun, error) {
+		return ci.aggregateFile(ctx, file)
+	}, fn)
+}
+
+// StreamReader is like StreamDomainData but reads the CSV from r instead of the configured path.
+func (ci CustomerImporter) StreamReader(ctx context.Context, r io.Reader, fn func(DomainData) error) (Summary, error) {
+	return ci.streamWith(func() (*importRun, error) {
+		return ci.aggregate(ctx, r)
+	}, fn)
+}
+
+// ImportDomainDataChan is like StreamDomainData but sends the domains on the returned channel
+// from a separate goroutine, for composing larger pipelines: the consumer ranges over the
+// domains, and a slow consumer holds back the stream instead of buffering it.
+//
+// The domain channel is closed once the stream ends. The error channel then receives the
+// terminal error, nil on success, and is closed as well, so it is read after the domains. A
+// consumer stopping early must cancel ctx; the goroutine then exits without sending further
+// domains and the error channel receives ctx.Err(). The partial result of a cancelled import is
+// not sent either.
+func (ci CustomerImporter) ImportDomainDataChan(ctx context.Context) (<-chan DomainData, <-chan error) {
+	domains := make(chan DomainData)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		_, err := ci.StreamDomainData(ctx, func(d DomainData) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			select {
+			case domains <- d:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(domains)
+		errs <- err
+	}()
+	return domains, errs
+}
+
+// streamWith passes the domains of the run returned by aggregate to fn, recording metrics.
+func (ci CustomerImporter) streamWith(aggregate func() (*importRun, error), fn func(DomainData) error) (summary Summary, err error) {
+	start := time.Now()
+	defer func() {
+		ci.recordMetrics(start, summary, err)
+	}()
+
+	run, err := aggregate()
+	if run == nil {
+		return Summary{}, err
+	}
+	defer run.release()
+	if err != nil && !isPartial(err) {
+		return run.summary, err
+	}
+	summary, fnErr := run.each(fn)
+	if fnErr != nil {
+		return summary, fnErr
+	}
+	if err == nil {
+		logComplete(summary)
+	}
+	return summary, err
+}
@@ -0,0 +1,143 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamReader(t *testing.T) {
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@gmail.com,Male,192.168.1.1
+Jane,Doe,jane@acme.com,Female,192.168.1.2
+Max,Roe,max@gmail.com,Male,192.168.1.3
+Ann,Poe,ann@beta.org,Female,192.168.1.4`
+	ci := NewCustomerImporter("", WithSampleEmail(), WithCategory())
+
+	want, wantSummary, err := ci.ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []DomainData
+	summary, err := ci.StreamReader(context.Background(), strings.NewReader(content), func(d DomainData) error {
+		got = append(got, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != wantSummary {
+		t.Errorf("summary = %+v, want %+v", summary, wantSummary)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("streamed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStreamReaderErrors(t *testing.T) {
+	content := "first_name,last_name,email\nJohn,Doe,john@a.com\nJane,Doe,jane@b.com\n"
+
+	errStop := errors.New("stop")
+	calls := 0
+	_, err := NewCustomerImporter("").StreamReader(context.Background(), strings.NewReader(content), func(DomainData) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("callback error: err = %v after %d calls, want errStop after 1", err, calls)
+	}
+
+	calls = 0
+	_, err = NewCustomerImporter("").StreamReader(context.Background(), strings.NewReader(content+"Bad,Row,invalid\n"), func(DomainData) error {
+		calls++
+		return nil
+	})
+	if err == nil || calls != 0 {
+		t.Errorf("invalid input: err = %v after %d calls, want an error and no calls", err, calls)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("first_name,last_name,email\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&sb, "John,Doe,john%d@example%d.com\n", i, i%10)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader := &cancelAfterReads{r: strings.NewReader(sb.String()), reads: 2, cancel: cancel}
+	calls = 0
+	_, err = NewCustomerImporter("").StreamReader(ctx, reader, func(DomainData) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, ErrPartialResult) || calls == 0 {
+		t.Errorf("cancelled: err = %v after %d calls, want ErrPartialResult with the partial domains", err, calls)
+	}
+}
+
+func TestImportDomainDataChan(t *testing.T) {
+	ci := NewCustomerImporter("./test_data.csv")
+	want, err := ci.ImportDomainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domains, errs := ci.ImportDomainDataChan(context.Background())
+	var got []DomainData
+	for d := range domains {
+		got = append(got, d)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("received %d domains, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	domains, errs = NewCustomerImporter("./test_invalid_data.csv").ImportDomainDataChan(context.Background())
+	for range domains {
+		t.Error("received a domain from a failed import")
+	}
+	if err := <-errs; err == nil {
+		t.Error("expected the import error, got nil")
+	}
+}
+
+func TestImportDomainDataChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	domains, errs := NewCustomerImporter("./benchmark10k.csv").ImportDomainDataChan(ctx)
+	if _, ok := <-domains; !ok {
+		t.Fatal("domain channel closed before the first domain")
+	}
+	cancel()
+
+	// The domains are deliberately not drained: the goroutine must exit regardless
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("import goroutine did not exit after cancellation")
+	}
+	if _, ok := <-errs; ok {
+		t.Error("error channel not closed")
+	}
+	if _, ok := <-domains; ok {
+		t.Error("domain channel not closed")
+	}
+}
@@ -0,0 +1,92 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxLabelLength is the DNS limit for a single domain label (RFC 1035).
+	maxLabelLength = 63
+	// maxDomainLength is the DNS limit for a full domain name (RFC 1035).
+	maxDomainLength = 253
+	// maxLocalPartLength is the SMTP limit for the local part (RFC 5321).
+	maxLocalPartLength = 64
+	// maxEmailLength is the SMTP limit for a forward path address (RFC 5321).
+	maxEmailLength = 254
+)
+
+// ErrEmailTooLong is wrapped by the strict mode error about the length of the whole address,
+// which neither part exceeds on its own.
+var ErrEmailTooLong = errors.New("email too long")
+
+// ErrInvalidLocalPart is wrapped by the strict mode errors about the part before the '@'.
+var ErrInvalidLocalPart = errors.New("invalid local part")
+
+// ErrInvalidDomain is wrapped by the strict mode and label validation errors about the domain.
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// validateStrict applies the StrictMode bundle to an email that already passed validateEmail:
+// RFC length limits, a local part without whitespace or control characters, a domain without
+// whitespace, a dot in the domain and DNS label syntax. Problems are reported wrapping
+// ErrEmailTooLong, ErrInvalidLocalPart or ErrInvalidDomain.
+func validateStrict(email, domain string) error {
+	email = strings.TrimSpace(email)
+	if len(email) > maxEmailLength {
+		return fmt.Errorf("%w: length %d exceeds %d characters", ErrEmailTooLong, len(email), maxEmailLength)
+	}
+	local, rawDomain, _ := strings.Cut(email, "@")
+	if err := validateLocalPart(local); err != nil {
+		return err
+	}
+	if strings.IndexFunc(rawDomain, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%w %q: contains whitespace", ErrInvalidDomain, rawDomain)
+	}
+	if len(domain) > maxDomainLength {
+		return fmt.Errorf("%w: length %d exceeds %d characters", ErrInvalidDomain, len(domain), maxDomainLength)
+	}
+	if !strings.Contains(domain, ".") {
+		return fmt.Errorf("%w %q: missing '.' separator", ErrInvalidDomain, domain)
+	}
+	return validateDomainLabels(domain)
+}
+
+// validateLocalPart rejects a local part that is too long or contains whitespace or control
+// characters. Quoted-string local parts such as "\"john doe\"@example.com" are not supported.
+func validateLocalPart(local string) error {
+	if strings.HasPrefix(local, `"`) {
+		return fmt.Errorf("%w %q: quoted strings are not supported", ErrInvalidLocalPart, local)
+	}
+	if len(local) > maxLocalPartLength {
+		return fmt.Errorf("%w: length %d exceeds %d characters", ErrInvalidLocalPart, len(local), maxLocalPartLength)
+	}
+	for i, r := range local {
+		switch {
+		case unicode.IsSpace(r):
+			return fmt.Errorf("%w %q: contains whitespace", ErrInvalidLocalPart, local)
+		case unicode.IsControl(r):
+			return fmt.Errorf("%w %q: contains control character %U at byte %d", ErrInvalidLocalPart, local, r, i)
+		}
+	}
+	return nil
+}
+
+// validateDomainLabels checks every dot-separated label of domain against DNS label rules.
+// Each violation is reported with its own message naming the offending label.
+func validateDomainLabels(domain string) error {
+	for _, label := range strings.Split(domain, ".") {
+		switch {
+		case label == "":
+			return fmt.Errorf("%w %q: empty label", ErrInvalidDomain, domain)
+		case len(label) > maxLabelLength:
+			return fmt.Errorf("%w %q: label %q exceeds %d characters", ErrInvalidDomain, domain, label, maxLabelLength)
+		case strings.HasPrefix(label, "-"):
+			return fmt.Errorf("%w %q: label %q starts with a hyphen", ErrInvalidDomain, domain, label)
+		case strings.HasSuffix(label, "-"):
+			return fmt.Errorf("%w %q: label %q ends with a hyphen", ErrInvalidDomain, domain, label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package customerimporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateDomainLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		errorMsg string
+	}{
+		{name: "valid domain", domain: "example.com"},
+		{name: "valid hyphenated label", domain: "my-shop.example.com"},
+		{name: "valid 63 character label", domain: strings.Repeat("a", 63) + ".com"},
+		{name: "leading hyphen", domain: "-foo.com", errorMsg: "starts with a hyphen"},
+		{name: "trailing hyphen", domain: "foo-.com", errorMsg: "ends with a hyphen"},
+		{name: "empty label", domain: "foo..com", errorMsg: "empty label"},
+		{name: "leading dot", domain: ".foo.com", errorMsg: "empty label"},
+		{name: "trailing dot", domain: "foo.com.", errorMsg: "empty label"},
+		{name: "label too long", domain: strings.Repeat("a", 64) + ".com", errorMsg: "exceeds 63 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDomainLabels(tt.domain)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("validateDomainLabels(%q) unexpected error: %v", tt.domain, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateDomainLabels(%q) expected error containing %q, got nil", tt.domain, tt.errorMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("validateDomainLabels(%q) error = %q, want it to contain %q", tt.domain, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestImportDomainLabelValidation(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := `first_name,last_name,email,gender,ip_address
+John,Doe,john@foo-.com,Male,192.168.1.1`
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	if _, err := NewCustomerImporter(csvPath).ImportDomainData(); err != nil {
+		t.Fatalf("lenient import unexpectedly failed: %v", err)
+	}
+	_, err := NewCustomerImporter(csvPath, WithDomainLabelValidation()).ImportDomainData()
+	if err == nil || !strings.Contains(err.Error(), "ends with a hyphen") {
+		t.Errorf("expected label validation error, got %v", err)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		errorMsg string
+	}{
+		{name: "valid email", email: "john.doe@mail.example.com"},
+		{name: "surrounding whitespace is trimmed", email: "  john@example.com "},
+		{name: "internal whitespace in local part", email: "john doe@example.com", errorMsg: `invalid local part "john doe": contains whitespace`},
+		{name: "tab in local part", email: "john\tdoe@example.com", errorMsg: "invalid local part"},
+		{name: "control character in local part", email: "john\x00doe@example.com", errorMsg: "contains control character U+0000 at byte 4"},
+		{name: "delete character in local part", email: "john\x7f@example.com", errorMsg: "contains control character U+007F"},
+		{name: "quoted local part", email: `"john doe"@example.com`, errorMsg: "quoted strings are not supported"},
+		{name: "internal whitespace in domain", email: "john@exa mple.com", errorMsg: `invalid domain "exa mple.com": contains whitespace`},
+		{name: "email too long", email: "john@" + strings.Repeat("a.", 125) + "com", errorMsg: "exceeds 254 characters"},
+		{name: "local part too long", email: strings.Repeat("j", 65) + "@example.com", errorMsg: "invalid local part: length 65 exceeds 64"},
+		{name: "domain without dot", email: "john@localhost", errorMsg: "missing '.' separator"},
+		{name: "label syntax", email: "john@-foo.com", errorMsg: "starts with a hyphen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, err := validateEmail(tt.email)
+			if err != nil {
+				t.Fatalf("validateEmail(%q) rejected a leniently valid email: %v", tt.email, err)
+			}
+			err = validateStrict(tt.email, domain)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("validateStrict(%q) unexpected error: %v", tt.email, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("validateStrict(%q) error = %v, want it to contain %q", tt.email, err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidateStrictErrorKinds(t *testing.T) {
+	tests := []struct {
+		email string
+		want  error
+	}{
+		{email: "john doe@example.com", want: ErrInvalidLocalPart},
+		{email: "john\x01@example.com", want: ErrInvalidLocalPart},
+		{email: strings.Repeat("j", 65) + "@example.com", want: ErrInvalidLocalPart},
+		{email: "john@localhost", want: ErrInvalidDomain},
+		{email: "john@foo..com", want: ErrInvalidDomain},
+		// Neither part exceeds its own limit, only the address as a whole
+		{email: strings.Repeat("j", 64) + "@" + strings.Repeat(strings.Repeat("a", 60)+".", 4) + "com", want: ErrEmailTooLong},
+	}
+
+	for _, tt := range tests {
+		domain, err := validateEmail(tt.email)
+		if err != nil {
+			t.Fatalf("validateEmail(%q) rejected a leniently valid email: %v", tt.email, err)
+		}
+		err = validateStrict(tt.email, domain)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("validateStrict(%q) error = %v, want it to wrap %v", tt.email, err, tt.want)
+		}
+		for _, other := range []error{ErrEmailTooLong, ErrInvalidLocalPart, ErrInvalidDomain} {
+			if other != tt.want && errors.Is(err, other) {
+				t.Errorf("validateStrict(%q) error = %v, unexpectedly wraps %v", tt.email, err, other)
+			}
+		}
+	}
+}
+
+func TestImportStrictMode(t *testing.T) {
+	inputs := []string{
+		"john doe@example.com",
+		"john\x07@example.com",
+		"john@localhost",
+		"john@foo..com",
+		strings.Repeat("j", 65) + "@example.com",
+	}
+	for _, email := range inputs {
+		t.Run(email, func(t *testing.T) {
+			csvPath := t.TempDir() + "/test.csv"
+			content := "first_name,last_name,email,gender,ip_address\nJohn,Doe," + email + ",Male,192.168.1.1"
+			if err := writeTestCSV(csvPath, content); err != nil {
+				t.Fatalf("failed to write test CSV: %v", err)
+			}
+
+			if _, err := NewCustomerImporter(csvPath).ImportDomainData(); err != nil {
+				t.Fatalf("lenient import rejected %q: %v", email, err)
+			}
+			if _, err := NewCustomerImporter(csvPath, WithStrictMode()).ImportDomainData(); err == nil {
+				t.Errorf("strict import accepted %q", email)
+			}
+		})
+	}
+}
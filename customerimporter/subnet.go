@@ -0,0 +1,101 @@
+package customerimporter
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// IPv6SubnetBits is the prefix length IPv6 addresses are grouped by under WithIPColumn.
+const IPv6SubnetBits = 64
+
+// SubnetData is the number of customers connecting from one IP subnet (see WithIPColumn).
+type SubnetData struct {
+	// Subnet is the network in CIDR notation, e.g. "192.168.1.0/24".
+	Subnet string
+	// CustomerQuantity is the number of customers with an address within Subnet.
+	CustomerQuantity uint64
+}
+
+// errInvalidIP is wrapped by the row error of an invalid WithIPColumn value.
+var errInvalidIP = errors.New("invalid IP address")
+
+// rowSubnet returns the subnet of the row's IP column: the IPv4 network of IPPrefixLen bits, or
+// the IPv6 network of IPv6SubnetBits bits. IPv4-mapped IPv6 addresses count as IPv4.
+func (r *importRun) rowSubnet(line []string) (netip.Prefix, error) {
+	value := line[*r.ci.config.IPColumn]
+	addr, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w %q", errInvalidIP, value)
+	}
+	addr = addr.Unmap()
+	bits := IPv6SubnetBits
+	if addr.Is4() {
+		bits = r.ci.config.IPPrefixLen
+	}
+	// Zones such as "fe80::1%eth0" cannot be part of a prefix
+	return addr.WithZone("").Prefix(bits)
+}
+
+// subnets returns the subnet aggregation, IPv4 networks first, each in address order.
+func (r *importRun) subnets() []SubnetData {
+	prefixes := make([]netip.Prefix, 0, len(r.subnetCounts))
+	for prefix := range r.subnetCounts {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.SortFunc(prefixes, func(l, r netip.Prefix) int {
+		if c := l.Addr().Compare(r.Addr()); c != 0 {
+			return c
+		}
+		return cmp.Compare(l.Bits(), r.Bits())
+	})
+	data := make([]SubnetData, len(prefixes))
+	for i, prefix := range prefixes {
+		data[i] = SubnetData{Subnet: prefix.String(), CustomerQuantity: r.subnetCounts[prefix]}
+	}
+	return data
+}
+
+// ImportSubnets is like ImportWithSummary but returns the customers per IP subnet of the
+// WithIPColumn column, IPv4 networks first, each in address order. Every other option applies
+// as usual. A row is counted once however many emails it holds, and only when at least one of
+// them is counted for its domain, so without WithEmailSeparators or several WithEmailColumns
+// the subnet counts sum to the customers of all domains. It fails when no IP column is
+// configured.
+func (ci CustomerImporter) ImportSubnets(ctx context.Context) (data []SubnetData, summary Summary, err error) {
+	if ci.config.IPColumn == nil {
+		return nil, Summary{}, fmt.Errorf("subnet import requires an IP column")
+	}
+	file, err := os.Open(ci.path)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	start := time.Now()
+	defer func() {
+		ci.recordMetrics(start, summary, err)
+	}()
+
+	run, err := ci.aggregateFile(ctx, file)
+	if run == nil {
+		return nil, Summary{}, err
+	}
+	defer run.release()
+	if err != nil && !isPartial(err) {
+		return nil, run.summary, err
+	}
+	run.summary.finish(run.data)
+	if err == nil {
+		logComplete(run.summary)
+	}
+	return run.subnets(), run.summary, err
+}
@@ -0,0 +1,120 @@
+package customerimporter
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportSubnets(t *testing.T) {
+	const header = "first_name,last_name,email,gender,ip_address\n"
+	valid := "John,Doe,john@example.com,Male,192.168.1.10\n" +
+		"Jane,Doe,jane@example.com,Female,192.168.1.200\n" +
+		"Max,Roe,max@other.com,Male, 10.0.0.1 \n" +
+		"Ann,Poe,ann@other.com,Female,192.168.2.1\n" +
+		"Eve,Poe,eve@example.com,Female,2001:db8:1:2::5\n" +
+		"Bob,Poe,bob@example.com,Male,::ffff:10.0.0.9\n"
+	invalid := "Tom,Ray,tom@example.com,Male,not-an-ip\n"
+
+	tests := []struct {
+		name    string
+		content string
+		opts    []Option
+		want    []SubnetData
+		// emails is the number of counted emails when it differs from the rows
+		emails   uint64
+		errorMsg string
+	}{
+		{
+			name:    "/24 networks",
+			content: valid,
+			opts:    []Option{WithIPColumn(4, 24)},
+			want: []SubnetData{
+				{Subnet: "10.0.0.0/24", CustomerQuantity: 2},
+				{Subnet: "192.168.1.0/24", CustomerQuantity: 2},
+				{Subnet: "192.168.2.0/24", CustomerQuantity: 1},
+				{Subnet: "2001:db8:1:2::/64", CustomerQuantity: 1},
+			},
+		},
+		{
+			name:    "/16 networks",
+			content: valid,
+			opts:    []Option{WithIPColumn(4, 16)},
+			want: []SubnetData{
+				{Subnet: "10.0.0.0/16", CustomerQuantity: 2},
+				{Subnet: "192.168.0.0/16", CustomerQuantity: 3},
+				{Subnet: "2001:db8:1:2::/64", CustomerQuantity: 1},
+			},
+		},
+		{
+			name:     "invalid address is a row error",
+			content:  valid + invalid,
+			opts:     []Option{WithIPColumn(4, 24)},
+			errorMsg: `invalid IP address "not-an-ip"`,
+		},
+		{
+			name:    "invalid address skipped",
+			content: invalid + valid,
+			opts:    []Option{WithIPColumn(4, 24), WithErrorPolicy(ErrorPolicySkip)},
+			want: []SubnetData{
+				{Subnet: "10.0.0.0/24", CustomerQuantity: 2},
+				{Subnet: "192.168.1.0/24", CustomerQuantity: 2},
+				{Subnet: "192.168.2.0/24", CustomerQuantity: 1},
+				{Subnet: "2001:db8:1:2::/64", CustomerQuantity: 1},
+			},
+		},
+		{
+			name:    "row with several emails counts once",
+			content: "John,Doe,john@example.com;jd@other.com,Male,192.168.1.10\nJane,Doe,jane@example.com,Female,192.168.1.200\n",
+			opts:    []Option{WithIPColumn(4, 24), WithEmailSeparators([]rune{';'})},
+			want:    []SubnetData{{Subnet: "192.168.1.0/24", CustomerQuantity: 2}},
+			emails:  3,
+		},
+		{
+			name:     "invalid prefix length",
+			content:  valid,
+			opts:     []Option{WithIPColumn(4, 33)},
+			errorMsg: "invalid IPv4 prefix length 33",
+		},
+		{
+			name:     "no IP column",
+			content:  valid,
+			errorMsg: "subnet import requires an IP column",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "customers.csv")
+			if err := writeTestCSV(path, header+tt.content); err != nil {
+				t.Fatalf("failed to write test CSV: %v", err)
+			}
+			got, summary, err := NewCustomerImporter(path, tt.opts...).ImportSubnets(context.Background())
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImportSubnets() = %+v, want %+v", got, tt.want)
+			}
+			var total uint64
+			for _, d := range got {
+				total += d.CustomerQuantity
+			}
+			if tt.emails != 0 {
+				if summary.Customers != tt.emails {
+					t.Errorf("summary counts %d customers, want %d", summary.Customers, tt.emails)
+				}
+			} else if total != summary.Customers {
+				t.Errorf("subnet counts sum to %d, want the %d customers", total, summary.Customers)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package customerimporter
+
+// Summary holds statistics collected while importing a file, for reporting alongside the
+// aggregated domains.
+type Summary struct {
+	// Rows is the number of data rows read, excluding the header.
+	Rows uint64
+	// Domains is the number of unique domains aggregated.
+	Domains int
+	// Customers is the total number of counted email addresses across all domains.
+	Customers uint64
+	// TrimmedValues is the number of email values that carried leading or trailing whitespace.
+	// They are still counted; a non-zero value hints that the upstream export needs cleaning.
+	TrimmedValues uint64
+	// SkippedRows is the number of invalid rows left out under ErrorPolicySkip.
+	SkippedRows uint64
+	// SkipReasons breaks SkippedRows down by the reason of each row's error.
+	SkipReasons SkipReasonCounts
+	// Duplicates is the number of email addresses not counted again under WithDeduplication.
+	Duplicates uint64
+	// FilteredRows is the number of rows left out because they did not match WithRowFilter.
+	FilteredRows uint64
+	// RepeatedHeaders is the number of rows identical to the header skipped under
+	// WithSkipRepeatedHeaders. They are not included in Rows.
+	RepeatedHeaders uint64
+	// UnsampledRows is the number of rows read but not processed under WithSampling.
+	UnsampledRows uint64
+	// PaddedRows is the number of short rows padded to the header width under WithShortRowPadding.
+	PaddedRows uint64
+	// InvalidWeights is the number of rows with a blank or invalid weight that were skipped or
+	// counted once under WithWeightPolicy.
+	InvalidWeights uint64
+	// InvalidDates is the number of rows with a blank or unparseable date that were skipped or
+	// counted under MonthUnknown with WithDatePolicy.
+	InvalidDates uint64
+}
+
+// finish fills in the totals derived from the final aggregation.
+func (s *Summary) finish(data counter) {
+	s.Domains = data.len()
+	s.Customers = 0
+	data.each(func(_ string, count uint64) {
+		s.Customers += count
+	})
+}
+
+// add adds the row statistics of other to s, as when other covers more input of the same
+// source. Domains and Customers are left to the caller, as domains may overlap.
+func (s *Summary) add(other Summary) {
+	s.Rows += other.Rows
+	s.TrimmedValues += other.TrimmedValues
+	s.SkippedRows += other.SkippedRows
+	for reason, rows := range other.SkipReasons {
+		s.SkipReasons[reason] += rows
+	}
+	s.Duplicates += other.Duplicates
+	s.FilteredRows += other.FilteredRows
+	s.RepeatedHeaders += other.RepeatedHeaders
+	s.UnsampledRows += other.UnsampledRows
+	s.PaddedRows += other.PaddedRows
+	s.InvalidWeights += other.InvalidWeights
+	s.InvalidDates += other.InvalidDates
+}
@@ -0,0 +1,30 @@
+package customerimporter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestImportWithSummary(t *testing.T) {
+	csvPath := t.TempDir() + "/test.csv"
+	content := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,john@example.com ,Male,192.168.1.1\n" +
+		"Jane,Doe,  jane@example.com,Female,192.168.1.2\n" +
+		"Jim,Beam,jim@other.org,Male,192.168.1.3\n"
+	if err := writeTestCSV(csvPath, content); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	data, summary, err := NewCustomerImporter(csvPath).ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0] != (DomainData{Domain: "example.com", CustomerQuantity: 2}) {
+		t.Errorf("padded emails not merged with their trimmed domain: %v", data)
+	}
+
+	want := Summary{Rows: 3, Domains: 2, Customers: 3, TrimmedValues: 2}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+}
@@ -0,0 +1,63 @@
+package customerimporter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// tarFormat reports whether name is a tar archive by its extension, and whether it is
+// gzip-compressed (.tar.gz or .tgz).
+func tarFormat(name string) (isTar, gzipped bool) {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return true, false
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return true, true
+	}
+	return false, false
+}
+
+// aggregateTar is aggregate for a tar archive: every regular .csv member is read in archive
+// order, with its own header row, into a single result. Other members are skipped. Errors other
+// than a partial result are prefixed with the name of the failing member.
+func (ci CustomerImporter) aggregateTar(ctx context.Context, r io.Reader, gzipped bool) (*importRun, error) {
+	if gzipped {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer func() {
+			_ = zr.Close()
+		}()
+		r = zr
+	}
+
+	return ci.aggregateInputs(ctx, func(consume func(io.Reader) error) error {
+		archive := tar.NewReader(r)
+		for {
+			member, err := archive.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read tar archive: %w", err)
+			}
+			if member.Typeflag != tar.TypeReg || !strings.EqualFold(path.Ext(member.Name), ".csv") {
+				slog.Debug("skipping archive member", "name", member.Name)
+				continue
+			}
+			slog.Info("reading archive member", "name", member.Name)
+			if err := prefixError(member.Name, consume(archive)); err != nil {
+				return err
+			}
+		}
+	})
+}
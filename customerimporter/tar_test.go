@@ -0,0 +1,151 @@
+package customerimporter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tarMember is a file in a generated tar fixture.
+type tarMember struct {
+	name, content string
+	dir           bool
+}
+
+// writeTestTar writes members to a tar archive at path, gzip-compressed if gzipped is set.
+func writeTestTar(path string, gzipped bool, members ...tarMember) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var w io.Writer = file
+	if gzipped {
+		zw := gzip.NewWriter(file)
+		defer func() {
+			_ = zw.Close()
+		}()
+		w = zw
+	}
+	archive := tar.NewWriter(w)
+	for _, m := range members {
+		header := &tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.content)), Typeflag: tar.TypeReg}
+		if m.dir {
+			header = &tar.Header{Name: m.name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(archive, m.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func TestImportTarArchive(t *testing.T) {
+	members := []tarMember{
+		{name: "daily/", dir: true},
+		{name: "daily/2024-01-01.csv", content: "first_name,last_name,email\nJohn,Doe,john@example.com\nJoe,Doe,joe@other.com\n"},
+		{name: "daily/README.txt", content: "not,a,csv\n"},
+		{name: "daily/2024-01-02.CSV", content: "first_name,last_name,email\nJane,Doe,jane@example.com\n"},
+	}
+	want := fmt.Sprint([]DomainData{
+		{Domain: "example.com", CustomerQuantity: 2},
+		{Domain: "other.com", CustomerQuantity: 1},
+	})
+
+	for _, name := range []string{"backup.tar", "backup.tar.gz", "backup.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			_, gzipped := tarFormat(name)
+			if err := writeTestTar(path, gzipped, members...); err != nil {
+				t.Fatal(err)
+			}
+
+			ci := NewCustomerImporter(path, WithHeaderValidation())
+			if err := ci.CheckHeader(); err != nil {
+				t.Fatalf("CheckHeader: %v", err)
+			}
+			data, summary, err := ci.ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(data); got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+			if summary.Rows != 3 || summary.Customers != 3 {
+				t.Errorf("summary counted %d rows and %d customers, want 3 and 3", summary.Rows, summary.Customers)
+			}
+
+			var streamed []DomainData
+			if _, err := ci.StreamDomainData(context.Background(), func(d DomainData) error {
+				streamed = append(streamed, d)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(streamed); got != want {
+				t.Errorf("streamed %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestImportTarArchiveErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		members  []tarMember
+		errorMsg string
+	}{
+		{
+			name:     "no CSV members",
+			file:     "empty.tar",
+			members:  []tarMember{{name: "notes.txt", content: "nothing here\n"}},
+			errorMsg: "no CSV input found",
+		},
+		{
+			name: "invalid row names member",
+			file: "backup.tar",
+			members: []tarMember{
+				{name: "valid.csv", content: "first_name,last_name,email\nJohn,Doe,john@example.com\n"},
+				{name: "invalid.csv", content: "first_name,last_name,email\nJohn,Doe,not-an-email\n"},
+			},
+			errorMsg: "invalid.csv: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := writeTestTar(path, false, tt.members...); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err := NewCustomerImporter(path).ImportWithSummary(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+
+	t.Run("not gzip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "plain.tar.gz")
+		if err := writeTestTar(path, false, tarMember{name: "a.csv", content: "email\n"}); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := NewCustomerImporter(path).ImportWithSummary(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "gzip") {
+			t.Errorf("expected gzip error, got %v", err)
+		}
+	})
+}
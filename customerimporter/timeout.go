@@ -0,0 +1,102 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrReadTimeout is returned when the source is not read to its end within the configured
+// ReadTimeout.
+var ErrReadTimeout = errors.New("read timeout")
+
+// readResult carries the outcome of a background read into timeoutReader.buf.
+type readResult struct {
+	n   int
+	err error
+}
+
+// timeoutReader fails every read with ErrReadTimeout once timeout has passed since it was
+// created, including a read still blocked at that time. Reads are performed by one background
+// goroutine into one reused buffer, so a blocked read can be abandoned for any io.Reader,
+// including pipes without deadline support.
+type timeoutReader struct {
+	r       io.Reader
+	timeout time.Duration
+	// deadline is done once timeout has passed, or once the source reported an error.
+	deadline context.Context
+	stop     context.CancelFunc
+
+	// buf is written by the background goroutine between a request and its result only.
+	buf      []byte
+	requests chan int
+	results  chan readResult
+	// err is returned by every read after the source failed or the deadline passed.
+	err error
+}
+
+func newTimeoutReader(r io.Reader, timeout time.Duration) *timeoutReader {
+	deadline, stop := context.WithTimeout(context.Background(), timeout)
+	return &timeoutReader{
+		r:        r,
+		timeout:  timeout,
+		deadline: deadline,
+		stop:     stop,
+		requests: make(chan int),
+		// Buffered so an abandoned read can deliver its result and the goroutine exit
+		results: make(chan readResult, 1),
+	}
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	if t.buf == nil {
+		t.buf = make([]byte, len(p))
+		go t.readLoop()
+	}
+
+	select {
+	case t.requests <- len(p):
+	case <-t.deadline.Done():
+		return 0, t.expire()
+	}
+	select {
+	case res := <-t.results:
+		if res.err != nil {
+			t.err = res.err
+			t.stop()
+		}
+		return copy(p, t.buf[:res.n]), res.err
+	case <-t.deadline.Done():
+		return 0, t.expire()
+	}
+}
+
+// readLoop serves the read requests of Read until the source fails or the deadline passes.
+func (t *timeoutReader) readLoop() {
+	for {
+		select {
+		case size := <-t.requests:
+			if len(t.buf) < size {
+				t.buf = make([]byte, size)
+			}
+			n, err := t.r.Read(t.buf[:size])
+			t.results <- readResult{n: n, err: err}
+			if err != nil {
+				return
+			}
+		case <-t.deadline.Done():
+			return
+		}
+	}
+}
+
+// expire makes every further read fail with ErrReadTimeout.
+func (t *timeoutReader) expire() error {
+	t.err = fmt.Errorf("%w: input not read within %s", ErrReadTimeout, t.timeout)
+	return t.err
+}
@@ -0,0 +1,137 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportReaderPipe(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = pr.Close()
+	}()
+
+	// The producer writes incrementally, pausing between rows, and closes when done.
+	go func() {
+		defer func() {
+			_ = pw.Close()
+		}()
+		_, _ = io.WriteString(pw, "first_name,last_name,email,gender,ip_address\n")
+		for i := 0; i < 5; i++ {
+			time.Sleep(5 * time.Millisecond)
+			_, _ = fmt.Fprintf(pw, "John,Doe,john%d@example%d.com,Male,192.168.1.1\n", i, i%2)
+		}
+	}()
+
+	importer := NewCustomerImporter("", WithReadTimeout(5*time.Second))
+	data, summary, err := importer.ImportReader(context.Background(), pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{
+		{Domain: "example0.com", CustomerQuantity: 3},
+		{Domain: "example1.com", CustomerQuantity: 2},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d domains, want %d: %v", len(data), len(want), data)
+	}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("data[%d] = %+v, want %+v", i, data[i], want[i])
+		}
+	}
+	if summary.Rows != 5 {
+		t.Errorf("summary.Rows = %d, want 5", summary.Rows)
+	}
+}
+
+func TestImportReaderTimeout(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = pr.Close()
+		_ = pw.Close()
+	}()
+
+	// The producer sends the header and one row, then stalls without closing.
+	_, _ = io.WriteString(pw, "first_name,last_name,email,gender,ip_address\nJohn,Doe,john@example.com,Male,192.168.1.1\n")
+
+	importer := NewCustomerImporter("", WithReadTimeout(20*time.Millisecond))
+	start := time.Now()
+	_, _, err = importer.ImportReader(context.Background(), pr)
+	if !errors.Is(err, ErrReadTimeout) {
+		t.Fatalf("expected ErrReadTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timeout took %v to fire", elapsed)
+	}
+}
+
+func TestImportReaderTimeoutIsOverall(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = pr.Close()
+	}()
+
+	// The producer never stalls for long but also never finishes
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer func() {
+			_ = pw.Close()
+		}()
+		_, _ = io.WriteString(pw, "first_name,last_name,email,gender,ip_address\n")
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				_, _ = io.WriteString(pw, "John,Doe,john@example.com,Male,192.168.1.1\n")
+			}
+		}
+	}()
+
+	_, _, err = NewCustomerImporter("", WithReadTimeout(50*time.Millisecond)).ImportReader(context.Background(), pr)
+	if !errors.Is(err, ErrReadTimeout) {
+		t.Fatalf("expected ErrReadTimeout, got %v", err)
+	}
+}
+
+func TestTimeoutReaderReusesBuffer(t *testing.T) {
+	r := newTimeoutReader(strings.NewReader("first,second"), time.Second)
+	p := make([]byte, 6)
+	var got []byte
+	for {
+		n, err := r.Read(p)
+		got = append(got, p[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(got) != "first,second" {
+		t.Errorf("read %q, want %q", got, "first,second")
+	}
+	if len(r.buf) != len(p) {
+		t.Errorf("buffer grew to %d bytes, want one of %d", len(r.buf), len(p))
+	}
+	if _, err := r.Read(p); err != io.EOF {
+		t.Errorf("read after EOF = %v, want io.EOF", err)
+	}
+}
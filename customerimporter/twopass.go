@@ -0,0 +1,124 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// aggregateTwoPass is aggregateFile under WithTwoPassAggregation: a first pass collects the
+// distinct domains, then file is rewound and a second pass counts them into a fixedCounter.
+func (ci CustomerImporter) aggregateTwoPass(ctx context.Context, file io.ReadSeeker) (*importRun, error) {
+	first := ci
+	first.config.collectDomains = true
+	run, err := first.aggregatePath(ctx, file)
+	if run == nil {
+		return nil, err
+	}
+	domains, collectErr := run.sortedDomains()
+	run.release()
+	if err != nil {
+		// The first pass has no counts, so there is no partial result to report either
+		return nil, err
+	}
+	if collectErr != nil {
+		return nil, collectErr
+	}
+	slog.Info("first pass complete", "rows", run.summary.Rows, "unique_domains", len(domains))
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind input for the second pass: %w", err)
+	}
+	second := ci
+	second.config.fixedDomains = domains
+	run, err = second.aggregatePath(ctx, file)
+	if run != nil && err == nil {
+		if unknown := run.data.(*fixedCounter).unknown; unknown != "" {
+			return run, fmt.Errorf("domain %q not seen in the first pass: input changed between passes", unknown)
+		}
+	}
+	return run, err
+}
+
+// sortedDomains returns the distinct domains of the run in alphabetical order.
+func (r *importRun) sortedDomains() ([]string, error) {
+	domains := make([]string, 0, r.data.len())
+	if external, ok := r.data.(*spillCounter); ok {
+		// Merged runs are already sorted, so the domains are never all held twice
+		err := external.eachSorted(func(domain string, _ uint64) error {
+			domains = append(domains, domain)
+			return nil
+		})
+		return domains, err
+	}
+	r.data.each(func(domain string, _ uint64) {
+		domains = append(domains, domain)
+	})
+	slices.Sort(domains)
+	return domains, nil
+}
+
+// domainSet is the first-pass backend of WithTwoPassAggregation without spilling: it records
+// which domains occur, not how often, so every count is zero.
+type domainSet map[string]struct{}
+
+func (s domainSet) add(domain string, _ uint64) { s[domain] = struct{}{} }
+
+func (s domainSet) count(string) uint64 { return 0 }
+
+func (s domainSet) len() int { return len(s) }
+
+func (s domainSet) each(fn func(domain string, count uint64)) {
+	for domain := range s {
+		fn(domain, 0)
+	}
+}
+
+// fixedCounter is the second-pass backend of WithTwoPassAggregation: the counts of a domain set
+// known up front, held in a slice parallel to the sorted domains and found by binary search, so
+// nothing is allocated per domain while counting.
+type fixedCounter struct {
+	domains []string
+	counts  []uint64
+	// counted is the number of domains with a non-zero count.
+	counted int
+	// unknown is the first domain added that is not in domains; its count is dropped.
+	unknown string
+}
+
+func newFixedCounter(domains []string) *fixedCounter {
+	return &fixedCounter{domains: domains, counts: make([]uint64, len(domains))}
+}
+
+func (c *fixedCounter) add(domain string, n uint64) {
+	i, found := slices.BinarySearch(c.domains, domain)
+	if !found {
+		if c.unknown == "" {
+			c.unknown = domain
+		}
+		return
+	}
+	if c.counts[i] == 0 {
+		c.counted++
+	}
+	c.counts[i] += n
+}
+
+func (c *fixedCounter) count(domain string) uint64 {
+	if i, found := slices.BinarySearch(c.domains, domain); found {
+		return c.counts[i]
+	}
+	return 0
+}
+
+func (c *fixedCounter) len() int { return c.counted }
+
+func (c *fixedCounter) each(fn func(domain string, count uint64)) {
+	for i, domain := range c.domains {
+		if c.counts[i] > 0 {
+			fn(domain, c.counts[i])
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package customerimporter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTwoPassAggregationMatchesSinglePass(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("first_name,last_name,email\n")
+	for i := 0; i < 5000; i++ {
+		// Skewed counts over 997 domains, with some invalid rows and mixed-case duplicates
+		switch {
+		case i%101 == 0:
+			fmt.Fprintf(&sb, "Bad,Row,not-an-email-%d\n", i)
+		case i%7 == 0:
+			fmt.Fprintf(&sb, "John,Doe,john%d@Domain%d.example.com\n", i, i*i%997)
+		default:
+			fmt.Fprintf(&sb, "John,Doe,john%d@domain%d.example.com\n", i, i*i%997)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "generated.csv")
+	if err := writeTestCSV(path, sb.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	single, singleSummary, err := NewCustomerImporter(path, WithErrorPolicy(ErrorPolicySkip)).ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "in memory", opts: []Option{WithTwoPassAggregation()}},
+		{name: "spilling first pass", opts: []Option{WithTwoPassAggregation(), WithExternalAggregation(50, t.TempDir())}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithErrorPolicy(ErrorPolicySkip)}, tt.opts...)
+			data, summary, err := NewCustomerImporter(path, opts...).ImportWithSummary(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := fmt.Sprint(data), fmt.Sprint(single); got != want {
+				t.Errorf("two-pass result differs from single pass:\nhave: %.200s\nwant: %.200s", got, want)
+			}
+			if summary != singleSummary {
+				t.Errorf("summary = %+v, want %+v", summary, singleSummary)
+			}
+		})
+	}
+}
+
+func TestFixedCounter(t *testing.T) {
+	c := newFixedCounter([]string{"a.com", "b.com", "c.com"})
+	c.add("b.com", 2)
+	c.add("a.com", 1)
+	c.add("b.com", 3)
+	if c.count("b.com") != 5 || c.count("c.com") != 0 || c.len() != 2 {
+		t.Errorf("counts a=%d b=%d c=%d len=%d, want 1, 5, 0 and 2", c.count("a.com"), c.count("b.com"), c.count("c.com"), c.len())
+	}
+	var got []string
+	c.each(func(domain string, count uint64) {
+		got = append(got, fmt.Sprintf("%s=%d", domain, count))
+	})
+	if want := "[a.com=1 b.com=5]"; fmt.Sprint(got) != want {
+		t.Errorf("each yielded %v, want %s", got, want)
+	}
+
+	c.add("new.com", 1)
+	if c.unknown != "new.com" || c.len() != 2 {
+		t.Errorf("unknown = %q, len = %d after adding an unseen domain, want new.com and 2", c.unknown, c.len())
+	}
+}
+
+func TestTwoPassAggregationEmptyInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.csv")
+	if err := os.WriteFile(path, []byte("first_name,last_name,email\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, summary, err := NewCustomerImporter(path, WithTwoPassAggregation()).ImportWithSummary(context.Background())
+	if err != nil || len(data) != 0 || summary.Rows != 0 {
+		t.Errorf("got %v, %+v, %v; want an empty result", data, summary, err)
+	}
+}
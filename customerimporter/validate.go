@@ -0,0 +1,21 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRowRejected wraps the errors returned by a WithRowValidator hook.
+var ErrRowRejected = errors.New("row rejected")
+
+// validateRow runs the RowValidator hook, if any, on a parsed row.
+func (ci CustomerImporter) validateRow(record []string) error {
+	validate := ci.config.RowValidator
+	if validate == nil {
+		return nil
+	}
+	if err := validate(record); err != nil {
+		return fmt.Errorf("%w: %w", ErrRowRejected, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestImportRowValidator(t *testing.T) {
+	content := `first_name,last_name,email,region
+John,Doe,john@example.com,EU
+Jane,Doe,jane@example.com,US
+Joe,Doe,joe@other.com,EU
+Jim,Doe,not-an-email,US`
+
+	var calls int
+	euOnly := WithRowValidator(func(record []string) error {
+		calls++
+		if record[3] != "EU" {
+			return fmt.Errorf("region %q is not EU", record[3])
+		}
+		return nil
+	})
+
+	_, _, err := NewCustomerImporter("", euOnly).ImportReader(context.Background(), strings.NewReader(content))
+	if !errors.Is(err, ErrRowRejected) || !strings.Contains(err.Error(), `region "US" is not EU`) {
+		t.Errorf("expected rejected row error for the US region, got %v", err)
+	}
+
+	calls = 0
+	data, summary, err := NewCustomerImporter("", euOnly, WithErrorPolicy(ErrorPolicySkip)).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DomainData{{Domain: "example.com", CustomerQuantity: 1}, {Domain: "other.com", CustomerQuantity: 1}}
+	if fmt.Sprint(data) != fmt.Sprint(want) || summary.SkippedRows != 2 {
+		t.Errorf("got %v with %d skipped rows, want %v and 2 skipped rows", data, summary.SkippedRows, want)
+	}
+	// The header is not validated, and the hook runs before email validation, so the invalid
+	// US row is rejected by the validator.
+	if calls != 4 {
+		t.Errorf("validator called %d times, want 4", calls)
+	}
+}
@@ -0,0 +1,53 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WeightPolicy selects how rows with a blank or invalid weight are handled under
+// WithWeightColumn.
+type WeightPolicy string
+
+const (
+	// WeightPolicyError treats an invalid weight as a row error, subject to the ErrorPolicy.
+	// It is the default.
+	WeightPolicyError WeightPolicy = "error"
+	// WeightPolicySkip leaves rows with an invalid weight out of the aggregation.
+	WeightPolicySkip WeightPolicy = "skip"
+	// WeightPolicyOne counts rows with an invalid weight once, as without a weight column.
+	WeightPolicyOne WeightPolicy = "one"
+)
+
+// errInvalidWeight is wrapped by the row error of an invalid weight under WeightPolicyError.
+var errInvalidWeight = errors.New("invalid weight")
+
+// parseWeight parses a weight column value, which must be a positive integer.
+func parseWeight(value string) (uint64, error) {
+	weight, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	if err != nil || weight == 0 {
+		return 0, fmt.Errorf("%w %q: must be a positive integer", errInvalidWeight, value)
+	}
+	return weight, nil
+}
+
+// rowWeight returns how much the row adds to each of its domains. ok is false when the row must
+// be left out under WeightPolicySkip. Both skipped and defaulted weights are counted in
+// Summary.InvalidWeights.
+func (r *importRun) rowWeight(line []string) (weight uint64, ok bool, err error) {
+	weight, err = parseWeight(line[*r.ci.config.WeightColumn])
+	if err == nil {
+		return weight, true, nil
+	}
+	switch r.ci.config.WeightPolicy {
+	case WeightPolicySkip:
+		r.summary.InvalidWeights++
+		return 0, false, nil
+	case WeightPolicyOne:
+		r.summary.InvalidWeights++
+		return 1, true, nil
+	}
+	return 0, false, err
+}
@@ -0,0 +1,122 @@
+package customerimporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseWeight(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    uint64
+		wantErr bool
+	}{
+		{value: "1", want: 1},
+		{value: " 25 ", want: 25},
+		{value: "18446744073709551615", want: 18446744073709551615},
+		{value: "", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "-3", wantErr: true},
+		{value: "2.5", wantErr: true},
+		{value: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseWeight(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseWeight(%q) = %d, expected error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseWeight(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportWeightColumn(t *testing.T) {
+	const header = "first_name,last_name,email,count\n"
+	valid := "John,Doe,john@example.com,3\n" +
+		"Jane,Doe,jane@example.com,2\n" +
+		"Max,Roe,max@other.com,10\n"
+	invalid := "Ann,Poe,ann@example.com,\n" +
+		"Bob,Poe,bob@other.com,lots\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		opts        []Option
+		want        map[string]uint64
+		wantInvalid uint64
+		errorMsg    string
+	}{
+		{
+			name:    "weights summed",
+			content: valid,
+			want:    map[string]uint64{"example.com": 5, "other.com": 10},
+		},
+		{
+			name:     "invalid weight fails by default",
+			content:  valid + invalid,
+			errorMsg: `invalid weight ""`,
+		},
+		{
+			name:        "invalid weight skipped",
+			content:     valid + invalid,
+			opts:        []Option{WithWeightPolicy(WeightPolicySkip)},
+			want:        map[string]uint64{"example.com": 5, "other.com": 10},
+			wantInvalid: 2,
+		},
+		{
+			name:        "invalid weight counted once",
+			content:     valid + invalid,
+			opts:        []Option{WithWeightPolicy(WeightPolicyOne)},
+			want:        map[string]uint64{"example.com": 6, "other.com": 11},
+			wantInvalid: 2,
+		},
+		{
+			name:     "overflow",
+			content:  "John,Doe,john@example.com,18446744073709551615\nJane,Doe,jane@example.com,1\n",
+			errorMsg: "overflows uint64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithWeightColumn(3)}, tt.opts...)
+			data, summary, err := NewCustomerImporter("", opts...).ImportReader(context.Background(), strings.NewReader(header+tt.content))
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != len(tt.want) {
+				t.Fatalf("got %d domains, want %d: %+v", len(data), len(tt.want), data)
+			}
+			for _, d := range data {
+				if d.CustomerQuantity != tt.want[d.Domain] {
+					t.Errorf("%s = %d, want %d", d.Domain, d.CustomerQuantity, tt.want[d.Domain])
+				}
+			}
+			if summary.InvalidWeights != tt.wantInvalid {
+				t.Errorf("InvalidWeights = %d, want %d", summary.InvalidWeights, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestImportWeightColumnMissing(t *testing.T) {
+	content := "first_name,last_name,email\nJohn,Doe,john@example.com\n"
+	_, _, err := NewCustomerImporter("", WithWeightColumn(3)).ImportReader(context.Background(), strings.NewReader(content))
+	if err == nil || !strings.Contains(err.Error(), "expected at least 4 columns") {
+		t.Errorf("expected column count error, got %v", err)
+	}
+}
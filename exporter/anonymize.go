@@ -0,0 +1,31 @@
+package exporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"importer/customerimporter"
+)
+
+// ErrAnonymizeUnsupported is returned under WithAnonymizedDomains by exports that would write
+// the real domains.
+var ErrAnonymizeUnsupported = errors.New("export cannot anonymize domains")
+
+// AnonymizeDomain returns the pseudonym WithAnonymizedDomains writes for domain:
+// "domain-<hash8>.example", where hash8 is the first 8 hex characters of the SHA-256 digest of
+// the case-folded domain (see customerimporter.FoldDomain). The mapping is stable across runs
+// and platforms. It is not salted, so anyone can confirm a guessed domain by hashing it; it hides
+// domains from casual readers only.
+func AnonymizeDomain(domain string) string {
+	sum := sha256.Sum256([]byte(customerimporter.FoldDomain(domain)))
+	return "domain-" + hex.EncodeToString(sum[:4]) + ".example"
+}
+
+// checkRealDomains fails with ErrAnonymizeUnsupported under WithAnonymizedDomains; exports
+// writing the real domains call it before writing anything.
+func (ex CustomerExporter) checkRealDomains() error {
+	if ex.config.AnonymizeDomains {
+		return ErrAnonymizeUnsupported
+	}
+	return nil
+}
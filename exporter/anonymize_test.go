@@ -0,0 +1,91 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestAnonymizeDomain(t *testing.T) {
+	got := AnonymizeDomain("Example.com")
+	if !regexp.MustCompile(`^domain-[0-9a-f]{8}\.example$`).MatchString(got) {
+		t.Errorf("AnonymizeDomain(Example.com) = %q, want domain-<hash8>.example", got)
+	}
+	if again := AnonymizeDomain("example.com"); again != got {
+		t.Errorf("pseudonym not stable: %q then %q", got, again)
+	}
+	if other := AnonymizeDomain("other.com"); other == got {
+		t.Errorf("example.com and other.com share the pseudonym %q", got)
+	}
+}
+
+func TestWithAnonymizedDomains(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "zeta.com", CustomerQuantity: 3, SampleEmail: "john@zeta.com"},
+		{Domain: "alpha.com", CustomerQuantity: 12, SampleEmail: "jane@alpha.com"},
+		{Domain: "beta.org", CustomerQuantity: 1, SampleEmail: "max@beta.org"},
+	}
+
+	var sb strings.Builder
+	ex := NewCustomerExporter("", WithAnonymizedDomains(), WithSortOnExport(customerimporter.SortByDomain),
+		WithDomainID(HashFNV), WithSampleEmail())
+	if err := ex.ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+	want := "domain,number_of_customers,domain_id,sample_email\n" +
+		AnonymizeDomain("alpha.com") + ",12," + DomainID(AnonymizeDomain("alpha.com"), HashFNV) + ",\n" +
+		AnonymizeDomain("beta.org") + ",1," + DomainID(AnonymizeDomain("beta.org"), HashFNV) + ",\n" +
+		AnonymizeDomain("zeta.com") + ",3," + DomainID(AnonymizeDomain("zeta.com"), HashFNV) + ",\n"
+	if got != want {
+		t.Errorf("anonymized output mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+	for _, d := range data {
+		if strings.Contains(got, d.Domain) {
+			t.Errorf("output leaks %q:\n%s", d.Domain, got)
+		}
+	}
+}
+
+func TestWithAnonymizedDomainsUnsupported(t *testing.T) {
+	data := []customerimporter.DomainData{{Domain: "acme.com", CustomerQuantity: 2}}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out")
+	ex := NewCustomerExporter(path, WithAnonymizedDomains())
+
+	exports := map[string]func() error{
+		"ExportList":         func() error { return ex.ExportList(data) },
+		"ExportBreakdown":    func() error { return ex.ExportBreakdown([]customerimporter.DomainBreakdown{}) },
+		"ExportEnvelope":     func() error { return ex.ExportEnvelope("in.csv", customerimporter.Summary{}, data) },
+		"ExportReverseIndex": func() error { return ex.ExportReverseIndex(data) },
+		"ExportJSONTree":     func() error { return ex.ExportJSONTree(data) },
+		"ExportXLSX":         func() error { return ex.ExportXLSX(data) },
+		"ExportTrend":        func() error { return ex.ExportTrend(map[string]uint64{}, data) },
+		"ExportComparison":   func() error { return ex.ExportComparison(data, data) },
+		"ExportMonthly":      func() error { return ex.ExportMonthly([]customerimporter.MonthlyData{}) },
+		"Publish":            func() error { return ex.Publish(context.Background(), &fakePublisher{}, data) },
+		"Template": func() error {
+			tmpl := template.Must(template.New("row").Parse("{{.Domain}}\n"))
+			return NewCustomerExporter(path, WithAnonymizedDomains(), WithTemplate(tmpl)).ExportData(data)
+		},
+	}
+	for name, export := range exports {
+		if err := export(); !errors.Is(err, ErrAnonymizeUnsupported) {
+			t.Errorf("%s() error = %v, want %v", name, err, ErrAnonymizeUnsupported)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("output was created: %v", err)
+	}
+
+	var sb strings.Builder
+	if err := ex.ExportXLSXTo(&sb, data); !errors.Is(err, ErrAnonymizeUnsupported) || sb.Len() != 0 {
+		t.Errorf("ExportXLSXTo() error = %v after %d bytes, want %v before writing", err, sb.Len(), ErrAnonymizeUnsupported)
+	}
+}
@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// ExportBreakdown writes the per-domain analytics report built with customerimporter.Breakdown
+// to the output file, in the given order:
+//
+//	domain,number_of_customers,role_accounts,personal_accounts,category
+//	acme.com,3,2,1,corporate
+//	gmail.com,2,1,1,freemail
+//
+// Column options do not apply. Returns an error if data is nil or the file cannot be written.
+func (ex CustomerExporter) ExportBreakdown(data []customerimporter.DomainBreakdown) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportBreakdownTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("breakdown written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportBreakdownTo writes the same CSV as ExportBreakdown to w.
+func ExportBreakdownTo(w io.Writer, data []customerimporter.DomainBreakdown) error {
+	csvWriter := csv.NewWriter(w)
+	header := []string{"domain", "number_of_customers", "role_accounts", "personal_accounts", "category"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	for _, d := range data {
+		record := []string{
+			d.Domain,
+			strconv.FormatUint(d.CustomerQuantity, 10),
+			strconv.FormatUint(d.RoleAccounts, 10),
+			strconv.FormatUint(d.PersonalAccounts, 10),
+			string(d.Category),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"context"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportBreakdown(t *testing.T) {
+	content := `first_name,last_name,email
+Info,Desk,info@acme.com
+John,Doe,john@acme.com
+Sales,Team,sales@acme.com
+Jane,Doe,jane@gmail.com
+Help,Desk,SUPPORT@gmail.com
+Ann,Poe,ann@gmail.com`
+	data, _, err := customerimporter.NewCustomerImporter("", customerimporter.WithRoleAccounts(), customerimporter.WithCategory()).
+		ImportReader(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "breakdown.csv")
+
+	if err := NewCustomerExporter(path).ExportBreakdown(customerimporter.Breakdown(data)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,role_accounts,personal_accounts,category\n" +
+		"acme.com,3,2,1,corporate\n" +
+		"gmail.com,3,1,2,freemail\n"
+	if string(got) != want {
+		t.Errorf("breakdown CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if err := NewCustomerExporter(path).ExportBreakdown(nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+}
@@ -0,0 +1,55 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultChartWidth is the bar width in terminal cells used by ExportChartTo for a width of zero.
+const DefaultChartWidth = 40
+
+// partialBlocks are the left-aligned block characters one to seven eighths of a cell wide.
+var partialBlocks = [...]string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// ExportChartTo writes data to w as a horizontal bar chart for the terminal, one line per domain
+// in input order, e.g. after customerimporter.TopN:
+//
+//	gmail.com  ████████████████████████████████████████ 120
+//	acme.com   █████████████▍ 40
+//
+// Bars are scaled so the largest count fills width cells, in steps of an eighth of a cell, and
+// every non-zero count gets at least one step.
+func ExportChartTo(w io.Writer, data []customerimporter.DomainData, width int) error {
+	if width <= 0 {
+		width = DefaultChartWidth
+	}
+	var largest uint64
+	domainWidth := 0
+	for _, d := range data {
+		largest = max(largest, d.CustomerQuantity)
+		domainWidth = max(domainWidth, utf8.RuneCountInString(d.Domain))
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, d := range data {
+		if _, err := fmt.Fprintf(bw, "%-*s %s %d\n", domainWidth, d.Domain, chartBar(d.CustomerQuantity, largest, width), d.CustomerQuantity); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// chartBar renders count as a bar of block characters, width cells long for largest.
+func chartBar(count, largest uint64, width int) string {
+	if count == 0 || largest == 0 {
+		return ""
+	}
+	eighths := int(math.Round(float64(count) / float64(largest) * float64(width*8)))
+	eighths = max(eighths, 1)
+	return strings.Repeat("█", eighths/8) + partialBlocks[eighths%8]
+}
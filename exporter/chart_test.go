@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChartBar(t *testing.T) {
+	tests := []struct {
+		count, largest uint64
+		width          int
+		want           string
+	}{
+		{count: 120, largest: 120, width: 10, want: "██████████"},
+		{count: 60, largest: 120, width: 10, want: "█████"},
+		{count: 40, largest: 120, width: 10, want: "███▍"},
+		{count: 1, largest: 1000, width: 10, want: "▏"},
+		{count: 0, largest: 120, width: 10, want: ""},
+		{count: 1 << 63, largest: 1<<64 - 1, width: 4, want: "██"},
+	}
+	for _, tt := range tests {
+		got := chartBar(tt.count, tt.largest, tt.width)
+		if got != tt.want {
+			t.Errorf("chartBar(%d, %d, %d) = %q, want %q", tt.count, tt.largest, tt.width, got, tt.want)
+		}
+		if cells := utf8.RuneCountInString(got); cells > tt.width {
+			t.Errorf("chartBar(%d, %d, %d) is %d cells wide, more than the width", tt.count, tt.largest, tt.width, cells)
+		}
+	}
+}
+
+func TestExportChartTo(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "gmail.com", CustomerQuantity: 8},
+		{Domain: "acme.com", CustomerQuantity: 2},
+	}
+	var sb strings.Builder
+	if err := ExportChartTo(&sb, data, 4); err != nil {
+		t.Fatal(err)
+	}
+	want := "gmail.com ████ 8\n" +
+		"acme.com  █ 2\n"
+	if sb.String() != want {
+		t.Errorf("chart mismatch:\nhave: %q\nwant: %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checksumSuffix is appended to the output path to name the SHA-256 sidecar file.
+const checksumSuffix = ".sha256"
+
+// exportIdempotent renders the CSV in memory, compares its hash with the sidecar and the
+// existing output file, and only writes both when the content changed.
+func (ex CustomerExporter) exportIdempotent(data []customerimporter.DomainData, cols []column) (ExportStatus, error) {
+	var buf bytes.Buffer
+	if err := ex.exportCsv(data, cols, &buf); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	digest := hex.EncodeToString(sum[:])
+
+	if ex.isUnchanged(digest) {
+		slog.Info("export unchanged", "file", ex.outputPath, "sha256", digest)
+		return StatusUnchanged, nil
+	}
+
+	if err := ex.writeVerified(len(data), func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}); err != nil {
+		return "", err
+	}
+	// Same layout as sha256sum so the sidecar can be checked with `sha256sum -c`.
+	sidecar := fmt.Sprintf("%s  %s\n", digest, filepath.Base(ex.outputPath))
+	if err := os.WriteFile(ex.outputPath+checksumSuffix, []byte(sidecar), 0666); err != nil {
+		return "", fmt.Errorf("failed to write checksum file: %w", err)
+	}
+
+	slog.Info("export written successfully", "file", ex.outputPath, "sha256", digest)
+	return StatusWritten, nil
+}
+
+// isUnchanged reports whether both the sidecar and the current output file match digest.
+// Any read error is treated as "changed" so the export falls back to rewriting.
+func (ex CustomerExporter) isUnchanged(digest string) bool {
+	sidecar, err := os.ReadFile(ex.outputPath + checksumSuffix)
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(sidecar))
+	if len(fields) == 0 || fields[0] != digest {
+		return false
+	}
+
+	existing, err := os.Open(ex.outputPath)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = existing.Close()
+	}()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, existing); err != nil {
+		return false
+	}
+	return hex.EncodeToString(hash.Sum(nil)) == digest
+}
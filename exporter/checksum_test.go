@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportSkipUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "example.com", CustomerQuantity: 3},
+		{Domain: "test.org", CustomerQuantity: 1},
+	}
+	exporter := NewCustomerExporter(path, WithSkipUnchanged())
+
+	status, err := exporter.Export(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusWritten {
+		t.Fatalf("first export status = %q, want %q", status, StatusWritten)
+	}
+	if _, err := os.Stat(path + checksumSuffix); err != nil {
+		t.Fatalf("checksum sidecar not written: %v", err)
+	}
+
+	// Backdate the file so a rewrite would be visible through its mtime.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err = exporter.Export(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusUnchanged {
+		t.Errorf("second export status = %q, want %q", status, StatusUnchanged)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Errorf("output file rewritten: mtime %v, want %v", info.ModTime(), past)
+	}
+}
+
+func TestExportSkipUnchangedRewritesOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	exporter := NewCustomerExporter(path, WithSkipUnchanged())
+
+	if _, err := exporter.Export([]customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := exporter.Export([]customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusWritten {
+		t.Errorf("export with changed data status = %q, want %q", status, StatusWritten)
+	}
+
+	// A manually edited output must be rewritten even if the sidecar still matches.
+	if err := os.WriteFile(path, []byte("tampered\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	status, err = exporter.Export([]customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusWritten {
+		t.Errorf("export over tampered file status = %q, want %q", status, StatusWritten)
+	}
+}
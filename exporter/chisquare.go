@@ -0,0 +1,197 @@
+package exporter
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// ChiSquareRecord compares the observed count of one domain with its count expected under a
+// reference distribution.
+type ChiSquareRecord struct {
+	Domain   string
+	Observed uint64
+	// Expected is the domain's reference share of all observed customers.
+	Expected float64
+	// Contribution is the domain's term of the chi-square statistic, (Observed - Expected)^2 /
+	// Expected. It is +Inf for a domain observed despite an expected count of zero.
+	Contribution float64
+}
+
+// LoadReference reads a reference distribution, mapping domains to their expected shares, from
+// the CSV file at path. It has a "domain" column and either a "share" column, e.g. 0.25, or a
+// "number_of_customers" column, so a previous export as read by LoadBaseline serves as
+// reference as well. Shares need not add up to one; they are scaled to their total.
+func LoadReference(path string) (map[string]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ReadReference(file)
+}
+
+// ReadReference is like LoadReference but reads the CSV from r.
+func ReadReference(r io.Reader) (map[string]float64, error) {
+	records := csv.NewReader(r)
+	header, err := records.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reference header: %w", err)
+	}
+	domainColumn := slices.Index(header, "domain")
+	shareColumn := slices.Index(header, "share")
+	if shareColumn < 0 {
+		shareColumn = slices.Index(header, "number_of_customers")
+	}
+	if domainColumn < 0 || shareColumn < 0 {
+		return nil, fmt.Errorf("invalid reference header %q: want domain and share or number_of_customers columns", header)
+	}
+
+	reference := make(map[string]float64)
+	for {
+		record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			return reference, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read reference: %w", err)
+		}
+		share, err := strconv.ParseFloat(record[shareColumn], 64)
+		if err != nil || share < 0 || math.IsInf(share, 0) || math.IsNaN(share) {
+			return nil, fmt.Errorf("invalid reference share %q for %q: want a non-negative number", record[shareColumn], record[domainColumn])
+		}
+		reference[record[domainColumn]] = share
+	}
+}
+
+// ChiSquare compares data with the reference distribution of expected shares, returning a
+// ChiSquareRecord for every domain of either, sorted by domain, and the chi-square statistic,
+// the sum of all contributions. The expected counts distribute the customers of data over the
+// reference shares scaled to their total; a domain missing from the reference is expected zero
+// times, so observing it makes the statistic +Inf. It fails when the shares add up to zero.
+func ChiSquare(reference map[string]float64, data []customerimporter.DomainData) ([]ChiSquareRecord, float64, error) {
+	var shares float64
+	for _, share := range reference {
+		shares += share
+	}
+	if !(shares > 0) {
+		return nil, 0, fmt.Errorf("reference distribution has no positive share")
+	}
+	var observedTotal uint64
+	observed := make(map[string]uint64, len(data))
+	for _, d := range data {
+		observed[d.Domain] += d.CustomerQuantity
+		observedTotal += d.CustomerQuantity
+	}
+
+	records := make([]ChiSquareRecord, 0, max(len(observed), len(reference)))
+	add := func(domain string) {
+		expected := reference[domain] / shares * float64(observedTotal)
+		r := ChiSquareRecord{Domain: domain, Observed: observed[domain], Expected: expected}
+		diff := float64(r.Observed) - expected
+		switch {
+		case expected > 0:
+			r.Contribution = diff * diff / expected
+		case r.Observed > 0:
+			r.Contribution = math.Inf(1)
+		}
+		records = append(records, r)
+	}
+	for domain := range observed {
+		add(domain)
+	}
+	for domain := range reference {
+		if _, found := observed[domain]; !found {
+			add(domain)
+		}
+	}
+	slices.SortFunc(records, func(l, r ChiSquareRecord) int {
+		return cmp.Compare(l.Domain, r.Domain)
+	})
+
+	var statistic float64
+	for _, r := range records {
+		statistic += r.Contribution
+	}
+	return records, statistic, nil
+}
+
+// ExportChiSquare writes the comparison of data with the reference distribution, as returned by
+// ChiSquare, to the output file for goodness-of-fit testing:
+//
+//	domain,observed,expected,chi_square
+//	acme.com,30,25.0000,1.0000
+//	globex.com,20,25.0000,1.0000
+//	,50,50.0000,2.0000
+//
+// The last row, with an empty domain, holds the totals and the chi-square statistic, to be
+// compared with the critical value for one degree of freedom less than the number of domains.
+// Column options do not apply. Returns an error if data or reference is nil, the reference has
+// no positive share or the file cannot be written.
+func (ex CustomerExporter) ExportChiSquare(reference map[string]float64, data []customerimporter.DomainData) error {
+	if data == nil || reference == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+
+	records, statistic, err := ChiSquare(reference, data)
+	if err != nil {
+		return err
+	}
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return writeChiSquare(w, records, statistic)
+	}); err != nil {
+		return err
+	}
+	slog.Info("chi-square export written successfully", "file", ex.outputPath, "chi_square", statistic)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(records) + 1}})
+}
+
+// ExportChiSquareTo writes the same CSV as ExportChiSquare to w.
+func ExportChiSquareTo(w io.Writer, reference map[string]float64, data []customerimporter.DomainData) error {
+	records, statistic, err := ChiSquare(reference, data)
+	if err != nil {
+		return err
+	}
+	return writeChiSquare(w, records, statistic)
+}
+
+func writeChiSquare(w io.Writer, records []ChiSquareRecord, statistic float64) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"domain", "observed", "expected", "chi_square"}); err != nil {
+		return err
+	}
+	var observed uint64
+	var expected float64
+	for _, r := range records {
+		observed += r.Observed
+		expected += r.Expected
+		if err := csvWriter.Write(chiSquareRow(r.Domain, r.Observed, r.Expected, r.Contribution)); err != nil {
+			return err
+		}
+	}
+	if err := csvWriter.Write(chiSquareRow("", observed, expected, statistic)); err != nil {
+		return err
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// chiSquareRow formats one row of the chi-square CSV with four decimals.
+func chiSquareRow(domain string, observed uint64, expected, contribution float64) []string {
+	return []string{
+		domain,
+		strconv.FormatUint(observed, 10),
+		strconv.FormatFloat(expected, 'f', 4, 64),
+		strconv.FormatFloat(contribution, 'f', 4, 64),
+	}
+}
@@ -0,0 +1,130 @@
+package exporter
+
+import (
+	"bytes"
+	"importer/customerimporter"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChiSquare(t *testing.T) {
+	reference, err := ReadReference(strings.NewReader("domain,share\nacme.com,0.5\nglobex.com,0.3\ninitech.com,0.2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []customerimporter.DomainData{
+		{Domain: "acme.com", CustomerQuantity: 60},
+		{Domain: "globex.com", CustomerQuantity: 20},
+		{Domain: "initech.com", CustomerQuantity: 20},
+	}
+
+	records, statistic, err := ChiSquare(reference, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ChiSquareRecord{
+		{Domain: "acme.com", Observed: 60, Expected: 50, Contribution: 2},
+		{Domain: "globex.com", Observed: 20, Expected: 30, Contribution: 10.0 / 3},
+		{Domain: "initech.com", Observed: 20, Expected: 20, Contribution: 0},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("ChiSquare() = %+v, want %+v", records, want)
+	}
+	for i, r := range records {
+		w := want[i]
+		if r.Domain != w.Domain || r.Observed != w.Observed || math.Abs(r.Expected-w.Expected) > 1e-9 || math.Abs(r.Contribution-w.Contribution) > 1e-9 {
+			t.Errorf("record %d = %+v, want %+v", i, r, w)
+		}
+	}
+	if math.Abs(statistic-16.0/3) > 1e-9 {
+		t.Errorf("ChiSquare() statistic = %v, want %v", statistic, 16.0/3)
+	}
+
+	var out bytes.Buffer
+	if err := ExportChiSquareTo(&out, reference, data); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "domain,observed,expected,chi_square\n" +
+		"acme.com,60,50.0000,2.0000\n" +
+		"globex.com,20,30.0000,3.3333\n" +
+		"initech.com,20,20.0000,0.0000\n" +
+		",100,100.0000,5.3333\n"
+	if out.String() != wantCSV {
+		t.Errorf("ExportChiSquareTo() mismatch:\nhave: %q\nwant: %q", out.String(), wantCSV)
+	}
+}
+
+func TestChiSquareUnexpectedDomains(t *testing.T) {
+	// A previous export serves as reference, its counts scaled to shares
+	reference, err := ReadReference(strings.NewReader("domain,number_of_customers\nacme.com,3\ngone.com,1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []customerimporter.DomainData{
+		{Domain: "acme.com", CustomerQuantity: 6},
+		{Domain: "new.com", CustomerQuantity: 2},
+	}
+
+	records, statistic, err := ChiSquare(reference, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ChiSquareRecord{
+		{Domain: "acme.com", Observed: 6, Expected: 6, Contribution: 0},
+		{Domain: "gone.com", Observed: 0, Expected: 2, Contribution: 2},
+		{Domain: "new.com", Observed: 2, Expected: 0, Contribution: math.Inf(1)},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("ChiSquare() = %+v, want %+v", records, want)
+	}
+	if !math.IsInf(statistic, 1) {
+		t.Errorf("ChiSquare() statistic = %v, want +Inf", statistic)
+	}
+}
+
+func TestExportChiSquare(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	reference := map[string]float64{"a.com": 1, "b.com": 1}
+	data := []customerimporter.DomainData{{Domain: "a.com", CustomerQuantity: 30}, {Domain: "b.com", CustomerQuantity: 20}}
+
+	if err := NewCustomerExporter(path).ExportChiSquare(reference, data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,observed,expected,chi_square\na.com,30,25.0000,1.0000\nb.com,20,25.0000,1.0000\n,50,50.0000,2.0000\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if err := NewCustomerExporter(path).ExportChiSquare(map[string]float64{"a.com": 0}, data); err == nil {
+		t.Error("ExportChiSquare() with zero shares succeeded, want an error")
+	}
+}
+
+func TestReadReferenceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "missing share column", content: "domain,weight\na.com,1\n", wantErr: "want domain and share or number_of_customers columns"},
+		{name: "negative share", content: "domain,share\na.com,-0.1\n", wantErr: `invalid reference share "-0.1"`},
+		{name: "invalid share", content: "domain,share\na.com,half\n", wantErr: `invalid reference share "half"`},
+		{name: "empty file", content: "", wantErr: "failed to read reference header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadReference(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadReference() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
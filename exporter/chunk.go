@@ -0,0 +1,108 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// chunkPath returns the path of the chunk with the 1-based index n, numbered before the
+// extension: "out.csv" becomes "out-0001.csv".
+func chunkPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%04d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// chunkIndex returns the index n of name when it is the base name of chunkPath(path, n) or of
+// its checksum sidecar, and false otherwise.
+func chunkIndex(path, name string) (int, bool) {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(filepath.Base(path), ext) + "-"
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return 0, false
+	}
+	digits, ok := strings.CutSuffix(strings.TrimSuffix(rest, checksumSuffix), ext)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	return n, err == nil && n > 0 && fmt.Sprintf("%04d", n) == digits
+}
+
+// removeStaleChunks removes the chunks numbered above count, and their checksum sidecars, left
+// next to the output by an earlier export of more records, so the directory holds exactly the
+// chunks of this export. Under NoClobber it fails with ErrOutputExists instead.
+func (ex CustomerExporter) removeStaleChunks(count int) error {
+	dir := filepath.Dir(ex.outputPath)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list chunks: %w", err)
+	}
+	for _, entry := range entries {
+		n, ok := chunkIndex(ex.outputPath, entry.Name())
+		if !ok || n <= count {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if ex.config.NoClobber {
+			return fmt.Errorf("refusing to overwrite stale chunk %s: %w", path, ErrOutputExists)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale chunk: %w", err)
+		}
+	}
+	return nil
+}
+
+// exportChunks writes data in files of at most ChunkSize records, each with its own header.
+// Empty data still produces one header-only chunk. Chunks numbered above the last one are
+// removed first (see removeStaleChunks). The status is StatusUnchanged only when every chunk was
+// left untouched under WithSkipUnchanged.
+func (ex CustomerExporter) exportChunks(data []customerimporter.DomainData, cols []column) (ExportStatus, []OutputFile, error) {
+	size := ex.config.ChunkSize
+	if err := ex.removeStaleChunks(max(1, (len(data)+size-1)/size)); err != nil {
+		return "", nil, err
+	}
+	status := StatusUnchanged
+	var files []OutputFile
+	for start, n := 0, 1; start == 0 || start < len(data); start, n = start+size, n+1 {
+		end := min(start+size, len(data))
+		chunk := ex
+		chunk.outputPath = chunkPath(ex.outputPath, n)
+
+		chunkStatus, chunkFiles, err := chunk.exportFile(data[start:end], offsetColumns(cols, start))
+		if err != nil {
+			return "", nil, err
+		}
+		if chunkStatus == StatusWritten {
+			status = StatusWritten
+		}
+		files = append(files, chunkFiles...)
+	}
+	return status, files, nil
+}
+
+// offsetColumns returns cols with row indexes shifted by offset, so dataset-wide columns such as
+// normalized counts keep referring to the right record within a chunk.
+func offsetColumns(cols []column, offset int) []column {
+	if offset == 0 {
+		return cols
+	}
+	shifted := make([]column, len(cols))
+	for i, c := range cols {
+		value := c.value
+		shifted[i] = column{header: c.header, value: func(row int, d customerimporter.DomainData) string {
+			return value(row+offset, d)
+		}}
+	}
+	return shifted
+}
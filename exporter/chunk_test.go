@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChunkPath(t *testing.T) {
+	tests := []struct {
+		path string
+		n    int
+		want string
+	}{
+		{path: "out.csv", n: 1, want: "out-0001.csv"},
+		{path: "dir/out.csv", n: 12, want: "dir/out-0012.csv"},
+		{path: "out", n: 3, want: "out-0003"},
+	}
+
+	for _, tt := range tests {
+		if got := chunkPath(tt.path, tt.n); got != tt.want {
+			t.Errorf("chunkPath(%q, %d) = %q, want %q", tt.path, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestExportChunks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.csv")
+	data := make([]customerimporter.DomainData, 5)
+	for i := range data {
+		data[i] = customerimporter.DomainData{Domain: fmt.Sprintf("d%d.com", i), CustomerQuantity: 1}
+	}
+
+	status, err := NewCustomerExporter(path, WithChunkSize(2), WithNormalizedCounts(5)).Export(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusWritten {
+		t.Errorf("status = %s, want %s", status, StatusWritten)
+	}
+
+	want := []string{
+		"domain,number_of_customers,normalized_count\nd0.com,1,1\nd1.com,1,1\n",
+		"domain,number_of_customers,normalized_count\nd2.com,1,1\nd3.com,1,1\n",
+		"domain,number_of_customers,normalized_count\nd4.com,1,1\n",
+	}
+	for i, w := range want {
+		got, err := os.ReadFile(chunkPath(path, i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != w {
+			t.Errorf("chunk %d mismatch:\nhave: %q\nwant: %q", i+1, got, w)
+		}
+	}
+	if _, err := os.Stat(chunkPath(path, 4)); !os.IsNotExist(err) {
+		t.Errorf("unexpected fourth chunk: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("unchunked output written: %v", err)
+	}
+}
+
+func TestExportChunksEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+
+	if err := NewCustomerExporter(path, WithChunkSize(10)).ExportData([]customerimporter.DomainData{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(chunkPath(path, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "domain,number_of_customers\n") || strings.Count(string(got), "\n") != 1 {
+		t.Errorf("empty export chunk = %q, want header only", got)
+	}
+}
+
+func TestExportChunksRemovesStale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.csv")
+	data := make([]customerimporter.DomainData, 5)
+	for i := range data {
+		data[i] = customerimporter.DomainData{Domain: fmt.Sprintf("d%d.com", i), CustomerQuantity: 1}
+	}
+	if err := NewCustomerExporter(path, WithChunkSize(2), WithSkipUnchanged()).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	unrelated := filepath.Join(dir, "out-notes.csv")
+	if err := os.WriteFile(unrelated, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewCustomerExporter(path, WithChunkSize(2), WithNoClobber()).ExportData(data[:2]); !errors.Is(err, ErrOutputExists) {
+		t.Fatalf("ExportData() with NoClobber error = %v, want %v", err, ErrOutputExists)
+	}
+	if _, err := os.Stat(chunkPath(path, 3)); err != nil {
+		t.Errorf("stale chunk removed under NoClobber: %v", err)
+	}
+
+	if err := NewCustomerExporter(path, WithChunkSize(2)).ExportData(data[:2]); err != nil {
+		t.Fatal(err)
+	}
+	for _, stale := range []string{chunkPath(path, 2), chunkPath(path, 3), chunkPath(path, 3) + checksumSuffix} {
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Errorf("stale %s still exists: %v", stale, err)
+		}
+	}
+	for _, kept := range []string{chunkPath(path, 1), unrelated} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s was removed: %v", kept, err)
+		}
+	}
+}
+
+func TestChunkIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+		ok   bool
+	}{
+		{"out-0003.csv", 3, true},
+		{"out-0003.csv.sha256", 3, true},
+		{"out-12345.csv", 12345, true},
+		{"out-003.csv", 0, false},
+		{"out-00003.csv", 0, false},
+		{"out-0000.csv", 0, false},
+		{"out-0003.txt", 0, false},
+		{"other-0003.csv", 0, false},
+		{"out.csv", 0, false},
+	}
+	for _, tt := range tests {
+		n, ok := chunkIndex("/tmp/out.csv", tt.name)
+		if ok != tt.ok || ok && n != tt.want {
+			t.Errorf("chunkIndex(%q) = %d, %v, want %d, %v", tt.name, n, ok, tt.want, tt.ok)
+		}
+	}
+}
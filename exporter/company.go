@@ -0,0 +1,69 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"os"
+	"slices"
+)
+
+// LoadDomainNames reads a domain -> company name mapping for WithDomainNames from the CSV file
+// at path. The columns are located by their "domain" and "company" headers, so files with extra
+// columns are accepted, e.g.
+//
+//	domain,company
+//	acme.com,Acme Corporation
+//
+// Domains are matched case-insensitively; listing a domain twice is an error.
+func LoadDomainNames(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ReadDomainNames(file)
+}
+
+// ReadDomainNames is like LoadDomainNames but reads the CSV from r.
+func ReadDomainNames(r io.Reader) (map[string]string, error) {
+	records := csv.NewReader(r)
+	header, err := records.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domain names header: %w", err)
+	}
+	domainColumn := slices.Index(header, "domain")
+	companyColumn := slices.Index(header, "company")
+	if domainColumn < 0 || companyColumn < 0 {
+		return nil, fmt.Errorf("invalid domain names header %q: want domain and company columns", header)
+	}
+
+	names := make(map[string]string)
+	for {
+		record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read domain names: %w", err)
+		}
+		domain := customerimporter.FoldDomain(record[domainColumn])
+		if _, ok := names[domain]; ok {
+			return nil, fmt.Errorf("duplicate domain %q in domain names", record[domainColumn])
+		}
+		names[domain] = record[companyColumn]
+	}
+}
+
+// companyName returns the company names maps domain to, matched case-insensitively, or
+// fallback when it is unmapped or mapped to an empty name.
+func companyName(names map[string]string, domain, fallback string) string {
+	if name := names[customerimporter.FoldDomain(domain)]; name != "" {
+		return name
+	}
+	return fallback
+}
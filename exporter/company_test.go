@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithDomainNames(t *testing.T) {
+	names, err := ReadDomainNames(strings.NewReader("company,domain,owner\nAcme Corporation,ACME.com,sales\n\"Globex, Inc.\",globex.net,support\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "acme.com", CustomerQuantity: 3},
+		{Domain: "Globex.net", CustomerQuantity: 2},
+		{Domain: "unknown.org", CustomerQuantity: 1},
+	}
+
+	if err := NewCustomerExporter(path, WithDomainNames(names)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,company\nacme.com,3,Acme Corporation\nGlobex.net,2,\"Globex, Inc.\"\nunknown.org,1,unknown.org\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestWithDomainNamesFoldsCase(t *testing.T) {
+	// Lowercasing maps the capital sigma to "σ", never to the final "ς"
+	names := map[string]string{"ΟΔΥΣΣΕΥΣ.gr": "Odysseus"}
+	data := []customerimporter.DomainData{{Domain: "οδυσσευς.gr", CustomerQuantity: 1}}
+	path := filepath.Join(t.TempDir(), "out.csv")
+	if err := NewCustomerExporter(path, WithDomainNames(names)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "domain,number_of_customers,company\nοδυσσευς.gr,1,Odysseus\n"; string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestReadDomainNamesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "missing company column", content: "domain,name\na.com,A\n", wantErr: "want domain and company columns"},
+		{name: "duplicate domain", content: "domain,company\na.com,A\nA.com,B\n", wantErr: `duplicate domain "A.com"`},
+		{name: "empty file", content: "", wantErr: "failed to read domain names header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadDomainNames(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadDomainNames() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// DomainCounts returns the count of every domain of data, in the form CompareBaseline takes, so
+// two imports can be compared without an intermediate export.
+func DomainCounts(data []customerimporter.DomainData) map[string]uint64 {
+	counts := make(map[string]uint64, len(data))
+	for _, d := range data {
+		counts[d.Domain] = d.CustomerQuantity
+	}
+	return counts
+}
+
+// ExportComparison writes the per-domain counts of two imports, a and b, such as yesterday's and
+// today's input, to the output file:
+//
+//	domain,count_a,count_b,delta
+//	acme.com,8,10,2
+//	gone.com,3,0,-3
+//	new.com,0,4,4
+//
+// delta is count_b minus count_a. Every domain of either import is listed, sorted by domain.
+// Column options do not apply. Returns an error if a or b is nil or the file cannot be written.
+func (ex CustomerExporter) ExportComparison(a, b []customerimporter.DomainData) error {
+	if a == nil || b == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	trends := CompareBaseline(DomainCounts(a), b)
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return writeComparison(w, trends)
+	}); err != nil {
+		return err
+	}
+	slog.Info("comparison export written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(trends)}})
+}
+
+// ExportComparisonTo writes the same CSV as ExportComparison to w.
+func ExportComparisonTo(w io.Writer, a, b []customerimporter.DomainData) error {
+	return writeComparison(w, CompareBaseline(DomainCounts(a), b))
+}
+
+func writeComparison(w io.Writer, trends []TrendRecord) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"domain", "count_a", "count_b", "delta"}); err != nil {
+		return err
+	}
+	for _, t := range trends {
+		record := []string{
+			t.Domain,
+			strconv.FormatUint(t.Previous, 10),
+			strconv.FormatUint(t.Current, 10),
+			formatDelta(t.Current, t.Previous),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
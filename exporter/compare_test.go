@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"context"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportComparisonTo(t *testing.T) {
+	yesterday := "first_name,last_name,email\n" +
+		"John,Doe,john@acme.com\n" +
+		"Jane,Doe,jane@acme.com\n" +
+		"Max,Doe,max@flat.com\n" +
+		"Joe,Doe,joe@gone.com\n" +
+		"Ann,Doe,ann@gone.com\n"
+	today := "first_name,last_name,email\n" +
+		"John,Doe,john@acme.com\n" +
+		"Jane,Doe,jane@acme.com\n" +
+		"Jim,Doe,jim@acme.com\n" +
+		"Max,Doe,max@flat.com\n" +
+		"Eve,Doe,eve@new.com\n"
+
+	ci := customerimporter.NewCustomerImporter("")
+	a, _, err := ci.ImportReader(context.Background(), strings.NewReader(yesterday))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := ci.ImportReader(context.Background(), strings.NewReader(today))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := ExportComparisonTo(&sb, a, b); err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,count_a,count_b,delta\n" +
+		"acme.com,2,3,1\n" +
+		"flat.com,1,1,0\n" +
+		"gone.com,2,0,-2\n" +
+		"new.com,0,1,1\n"
+	if got := sb.String(); got != want {
+		t.Errorf("comparison mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestExportComparison(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comparison.csv")
+	a := []customerimporter.DomainData{{Domain: "acme.com", CustomerQuantity: 5}}
+	b := []customerimporter.DomainData{{Domain: "acme.com", CustomerQuantity: 3}}
+
+	ex := NewCustomerExporter(path)
+	if err := ex.ExportComparison(a, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "domain,count_a,count_b,delta\nacme.com,5,3,-2\n"; string(got) != want {
+		t.Errorf("file content = %q, want %q", got, want)
+	}
+
+	if err := ex.ExportComparison(nil, b); err == nil {
+		t.Error("expected error for nil data")
+	}
+}
@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"log/slog"
+	"strconv"
+)
+
+// CountFetcher returns the current customer count of a domain from an external system, such as
+// a CRM, for the "delta" column of WithCountDelta. See HTTPCountFetcher for an implementation
+// backed by an HTTP API.
+type CountFetcher interface {
+	Fetch(domain string) (uint64, error)
+}
+
+// deltaColumn returns the "delta" column: the computed count minus the count fetched for the
+// domain, empty when the fetch fails.
+func deltaColumn(fetcher CountFetcher) column {
+	return column{header: "delta", value: func(_ int, d customerimporter.DomainData) string {
+		upstream, err := fetcher.Fetch(d.Domain)
+		if err != nil {
+			slog.Warn("failed to fetch upstream count, leaving delta empty", "domain", d.Domain, "error", err)
+			return ""
+		}
+		return formatDelta(d.CustomerQuantity, upstream)
+	}}
+}
+
+// formatDelta renders count - upstream as a signed decimal without overflowing.
+func formatDelta(count, upstream uint64) string {
+	if count >= upstream {
+		return strconv.FormatUint(count-upstream, 10)
+	}
+	return "-" + strconv.FormatUint(upstream-count, 10)
+}
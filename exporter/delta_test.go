@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"errors"
+	"importer/customerimporter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeFetcher serves upstream counts from a map and fails for unknown domains.
+type fakeFetcher map[string]uint64
+
+func (f fakeFetcher) Fetch(domain string) (uint64, error) {
+	count, ok := f[domain]
+	if !ok {
+		return 0, errors.New("not found")
+	}
+	return count, nil
+}
+
+func TestWithCountDelta(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 12},
+		{Domain: "b.org", CustomerQuantity: 3},
+		{Domain: "c.net", CustomerQuantity: 7},
+		{Domain: "d.io", CustomerQuantity: 5},
+	}
+	fetcher := fakeFetcher{"a.com": 10, "b.org": 5, "d.io": 5}
+
+	var sb strings.Builder
+	if err := NewCustomerExporter("", WithCountCap(10), WithCountDelta(fetcher)).ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,capped,delta\n" +
+		"a.com,10,true,2\n" +
+		"b.org,3,false,-2\n" +
+		"c.net,7,false,\n" +
+		"d.io,5,false,0\n"
+	if sb.String() != want {
+		t.Errorf("output mismatch:\nhave: %q\nwant: %q", sb.String(), want)
+	}
+}
+
+func TestHTTPCountFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/counts/example.com":
+			_, _ = w.Write([]byte(`{"domain":"example.com","number_of_customers":42}`))
+		case "/counts/broken.com":
+			_, _ = w.Write([]byte(`{"number_of_customers":"many"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	fetcher := HTTPCountFetcher{BaseURL: server.URL + "/counts", Client: server.Client()}
+
+	count, err := fetcher.Fetch("example.com")
+	if err != nil || count != 42 {
+		t.Errorf("Fetch(example.com) = %d, %v, want 42", count, err)
+	}
+	if _, err := fetcher.Fetch("missing.com"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error, got %v", err)
+	}
+	if _, err := fetcher.Fetch("broken.com"); err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Errorf("expected decode error, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"importer/customerimporter"
+)
+
+// HashAlgorithm selects how the "domain_id" column is computed.
+type HashAlgorithm string
+
+const (
+	// HashFNV is the 64-bit FNV-1a hash, rendered as 16 hex characters. Fast, not cryptographic.
+	HashFNV HashAlgorithm = "fnv"
+	// HashSHA256 is the SHA-256 digest, rendered as 64 hex characters.
+	HashSHA256 HashAlgorithm = "sha256"
+)
+
+// validate returns an error for algorithms other than HashFNV and HashSHA256.
+func (algo HashAlgorithm) validate() error {
+	switch algo {
+	case HashFNV, HashSHA256:
+		return nil
+	default:
+		return fmt.Errorf("unsupported domain ID hash algorithm %q (want %q or %q)", string(algo), HashFNV, HashSHA256)
+	}
+}
+
+// DomainID returns the hex-encoded hash of the case-folded domain (see
+// customerimporter.FoldDomain) using algo.
+// The result is stable across runs and platforms, so it can be used as a join key.
+// An unsupported algorithm yields an empty string.
+func DomainID(domain string, algo HashAlgorithm) string {
+	normalized := []byte(customerimporter.FoldDomain(domain))
+	switch algo {
+	case HashFNV:
+		h := fnv.New64a()
+		_, _ = h.Write(normalized)
+		return hex.EncodeToString(h.Sum(nil))
+	case HashSHA256:
+		sum := sha256.Sum256(normalized)
+		return hex.EncodeToString(sum[:])
+	default:
+		return ""
+	}
+}
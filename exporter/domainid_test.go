@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDomainID(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		algo   HashAlgorithm
+		want   string
+	}{
+		{
+			name:   "fnv",
+			domain: "example.com",
+			algo:   HashFNV,
+			want:   "576846634e2714c6",
+		},
+		{
+			name:   "fnv is case insensitive",
+			domain: "Example.COM",
+			algo:   HashFNV,
+			want:   "576846634e2714c6",
+		},
+		{
+			name:   "sha256",
+			domain: "example.com",
+			algo:   HashSHA256,
+			want:   "a379a6f6eeafb9a55e378c118034e2751e682fab9f2d30ab13d2125586ce1947",
+		},
+		{
+			name:   "unsupported algorithm",
+			domain: "example.com",
+			algo:   "md5",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DomainID(tt.domain, tt.algo); got != tt.want {
+				t.Errorf("DomainID(%q, %q) = %q, want %q", tt.domain, tt.algo, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportDomainIDColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 42}}
+
+	if err := NewCustomerExporter(path, WithDomainID(HashFNV)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,domain_id\nexample.com,42,576846634e2714c6\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestExportDomainIDUnsupportedAlgorithm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	err := NewCustomerExporter(path, WithDomainID("md5")).ExportData([]customerimporter.DomainData{})
+	if err == nil {
+		t.Fatal("unsupported hash algorithm not rejected")
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Errorf("output file created despite configuration error")
+	}
+}
@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Envelope is the JSON document written by ExportEnvelope: the domain records wrapped with
+// metadata about the import that produced them, for API responses.
+type Envelope struct {
+	// GeneratedAt is the UTC time the envelope was written.
+	GeneratedAt time.Time `json:"generated_at"`
+	// Source names the input, such as its file path.
+	Source string `json:"source"`
+	// TotalCustomers is Summary.Customers, the customers of all aggregated domains.
+	TotalCustomers uint64 `json:"total_customers"`
+	// UniqueDomains is Summary.Domains.
+	UniqueDomains int `json:"unique_domains"`
+	// Rows is Summary.Rows, the data rows read.
+	Rows uint64 `json:"rows"`
+	// SkippedRows is Summary.SkippedRows, the invalid rows skipped.
+	SkippedRows uint64 `json:"skipped_rows"`
+	// Domains holds one record per domain, never null.
+	Domains []Record `json:"domains"`
+}
+
+// ExportEnvelope writes data to the output file as a JSON Envelope whose metadata is taken from
+// summary, e.g.
+//
+//	{"generated_at":"2024-05-01T12:00:00Z","source":"customers.csv","total_customers":59,
+//	 "unique_domains":2,"rows":60,"skipped_rows":1,
+//	 "domains":[{"domain":"example.com","number_of_customers":42},...]}
+//
+// The metadata describes the whole import, so it still counts domains left out of data by
+// filters such as customerimporter.TopN. Records are written as by Publish, in order after
+// applying WithSortOnExport; other column options do not apply. Returns an error if data is nil
+// or the file cannot be written.
+func (ex CustomerExporter) ExportEnvelope(source string, summary customerimporter.Summary, data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ex.ExportEnvelopeTo(w, source, summary, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("envelope written successfully", "file", ex.outputPath, "records", len(data))
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportEnvelopeTo writes the same JSON as ExportEnvelope to w, followed by a newline.
+func (ex CustomerExporter) ExportEnvelopeTo(w io.Writer, source string, summary customerimporter.Summary, data []customerimporter.DomainData) error {
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+	data, err := ex.ordered(data)
+	if err != nil {
+		return err
+	}
+	envelope := Envelope{
+		GeneratedAt:    time.Now().UTC().Truncate(time.Second),
+		Source:         source,
+		TotalCustomers: summary.Customers,
+		UniqueDomains:  summary.Domains,
+		Rows:           summary.Rows,
+		SkippedRows:    summary.SkippedRows,
+		Domains:        make([]Record, len(data)),
+	}
+	for i, d := range data {
+		envelope.Domains[i] = newRecord(d)
+	}
+	return json.NewEncoder(w).Encode(envelope)
+}
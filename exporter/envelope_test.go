@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"encoding/json"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportEnvelope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.json")
+	data := []customerimporter.DomainData{
+		{Domain: "beta.org", CustomerQuantity: 3, Category: customerimporter.CategoryCorporate},
+		{Domain: "alpha.com", CustomerQuantity: 12, SampleEmail: "a@alpha.com"},
+	}
+	summary := customerimporter.Summary{Rows: 16, Domains: 2, Customers: 15, SkippedRows: 1}
+
+	before := time.Now().Add(-time.Second)
+	ex := NewCustomerExporter(path, WithSortOnExport(customerimporter.SortByDomain))
+	if err := ex.ExportEnvelope("customers.csv", summary, data); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Envelope
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("invalid envelope %s: %v", content, err)
+	}
+
+	if got.GeneratedAt.Before(before) || got.GeneratedAt.After(time.Now()) {
+		t.Errorf("generated_at = %v, want the export time", got.GeneratedAt)
+	}
+	if got.Source != "customers.csv" || got.TotalCustomers != 15 || got.UniqueDomains != 2 || got.Rows != 16 || got.SkippedRows != 1 {
+		t.Errorf("metadata = %+v, want it taken from the summary", got)
+	}
+	want := []Record{
+		{Domain: "alpha.com", Customers: 12, SampleEmail: "a@alpha.com"},
+		{Domain: "beta.org", Customers: 3, Category: "corporate"},
+	}
+	if !reflect.DeepEqual(got.Domains, want) {
+		t.Errorf("domains = %+v, want %+v", got.Domains, want)
+	}
+
+	if err := ex.ExportEnvelope("customers.csv", summary, []customerimporter.DomainData{}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), `"domains":[]`) {
+		t.Errorf("empty envelope = %s, want an empty domains array", content)
+	}
+}
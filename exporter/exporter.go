@@ -7,33 +7,369 @@
 //	example.com,42
 //	another.com,17
 //
-// The exporter creates or truncates the target file and writes data incrementally,
-// making it suitable for large datasets.
+// The exporter creates or truncates the target file (unless WithNoClobber is set) and writes
+// data incrementally, making it suitable for large datasets.
 package exporter
 
 import (
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"importer/customerimporter"
+	"importer/internal/describe"
 	"io"
+	"io/fs"
 	"log/slog"
+	"math"
 	"os"
+	"slices"
 	"strconv"
+	"text/template"
 )
 
+// ErrOutputExists is returned under WithNoClobber when an output file already exists.
+var ErrOutputExists = errors.New("output file already exists")
+
+// ExportStatus reports what an export did with the target file.
+type ExportStatus string
+
+const (
+	// StatusWritten means the output file was (re)written.
+	StatusWritten ExportStatus = "written"
+	// StatusUnchanged means the output already held identical content and was left untouched.
+	StatusUnchanged ExportStatus = "unchanged"
+)
+
+// Config holds the optional settings of a CustomerExporter.
+// The zero value always rewrites the output file.
+type Config struct {
+	// SkipUnchanged hashes the rendered output and skips rewriting the file when
+	// a matching "<output>.sha256" sidecar exists (see WithSkipUnchanged).
+	SkipUnchanged bool
+	// DomainID adds a "domain_id" column hashed from the case-folded domain with the given
+	// algorithm (see WithDomainID). Empty disables the column.
+	DomainID HashAlgorithm
+	// CountCap clamps reported counts to this ceiling and adds a "capped" column
+	// (see WithCountCap). Zero disables capping.
+	CountCap uint64
+	// SampleEmail adds a "sample_email" column (see WithSampleEmail).
+	SampleEmail bool
+	// NormalizeTo adds a "normalized_count" column scaling counts so they sum to this value
+	// (see WithNormalizedCounts). Zero disables the column.
+	NormalizeTo uint64
+	// PerMille adds a "per_mille" column with customers per thousand of the total
+	// (see WithPerMille).
+	PerMille bool
+	// Decimals is the number of decimal places of the "per_mille" column (see WithDecimals).
+	// Zero writes whole numbers.
+	Decimals int
+	// ScaleTo adds a "scaled_count" column min-max scaling counts into [0, ScaleTo]
+	// (see WithScaledCounts). Zero disables the column.
+	ScaleTo float64
+	// LogCounts adds a "log_count" column (see WithLogCounts).
+	LogCounts bool
+	// SignedCounts writes counts as int64 and fails when one exceeds math.MaxInt64
+	// (see WithSignedCounts).
+	SignedCounts bool
+	// Category adds a "category" column (see WithCategory).
+	Category bool
+	// DomainNames maps case-folded domains to the company names of the "company" column
+	// (see WithDomainNames). Nil disables the column.
+	DomainNames map[string]string
+	// ChunkSize splits the output file into numbered files of at most this many records
+	// (see WithChunkSize). Zero writes a single file.
+	ChunkSize int
+	// Manifest writes a manifest.json listing every produced file (see WithManifest).
+	Manifest bool
+	// NoClobber refuses to overwrite existing output files (see WithNoClobber).
+	NoClobber bool
+	// SortOnExport sorts the records by SortOrder before writing them (see WithSortOnExport).
+	SortOnExport bool
+	// SortOrder is the order applied with SortOnExport. Empty sorts by domain.
+	SortOrder customerimporter.SortOrder
+	// AnonymizeDomains writes pseudonyms instead of the real domains (see WithAnonymizedDomains).
+	AnonymizeDomains bool
+	// CountFetcher supplies the upstream counts of the "delta" column (see WithCountDelta).
+	// Nil disables the column.
+	CountFetcher CountFetcher
+	// Template renders every record instead of the CSV (see WithTemplate). Nil writes CSV.
+	Template *template.Template
+	// LineEnding terminates every record of the domain CSV (see WithLineEnding). Empty writes
+	// LineEndingLF.
+	LineEnding LineEnding
+	// RowHMACKey adds an "hmac" column keyed with it (see WithRowHMAC). Empty disables the column.
+	RowHMACKey HMACKey
+	// VerifyAfterWrite reads every domain CSV file back after writing it and fails the export
+	// when it does not match (see WithVerifyAfterWrite).
+	VerifyAfterWrite bool
+
+	// wrapOutput, when set, wraps the output file writer. It lets tests corrupt what reaches
+	// the file.
+	wrapOutput func(io.Writer) io.Writer
+}
+
+// String renders the config as "exporter.<option>=<value>" lines, one per option, for
+// diagnostics such as the CLI -explain flag.
+func (c Config) String() string {
+	return describe.String("exporter", c)
+}
+
+// Option configures a CustomerExporter.
+type Option func(*Config)
+
+// WithSkipUnchanged makes exports idempotent: the rendered output is hashed with SHA-256
+// and compared against the "<output>.sha256" sidecar. When the sidecar and the existing
+// file both match, the file is not rewritten. Otherwise the file and sidecar are updated.
+func WithSkipUnchanged() Option {
+	return func(c *Config) {
+		c.SkipUnchanged = true
+	}
+}
+
+// WithDomainID adds a "domain_id" column holding a deterministic hash of the case-folded domain,
+// for joining against datasets keyed by hashed domains.
+func WithDomainID(algo HashAlgorithm) Option {
+	return func(c *Config) {
+		c.DomainID = algo
+	}
+}
+
+// WithCountCap reports counts above limit as limit and adds a "capped" column that is true for
+// every clamped row, e.g. for privacy-preserving reports. The DomainData passed in is not
+// modified, so totals computed by the caller still use the raw counts.
+func WithCountCap(limit uint64) Option {
+	return func(c *Config) {
+		c.CountCap = limit
+	}
+}
+
+// WithSampleEmail adds a "sample_email" column holding DomainData.SampleEmail, as collected by
+// customerimporter.WithSampleEmail.
+func WithSampleEmail() Option {
+	return func(c *Config) {
+		c.SampleEmail = true
+	}
+}
+
+// WithNormalizedCounts adds a "normalized_count" column expressing each count as its share of
+// the total scaled to target, e.g. 10000 for per-myriad shares, so datasets of different sizes
+// can be compared. See NormalizeCounts for the rounding rules.
+func WithNormalizedCounts(target uint64) Option {
+	return func(c *Config) {
+		c.NormalizeTo = target
+	}
+}
+
+// WithPerMille adds a "per_mille" column holding each count as customers per thousand of the
+// total, i.e. count / total * 1000, for rate reporting. Values are whole numbers unless
+// WithDecimals is set; see PerMille for the rounding rules.
+func WithPerMille() Option {
+	return func(c *Config) {
+		c.PerMille = true
+	}
+}
+
+// WithDecimals writes the "per_mille" column of WithPerMille with n decimal places, between 0
+// and MaxPerMilleDecimals, e.g. 2 for "12.35".
+func WithDecimals(n int) Option {
+	return func(c *Config) {
+		c.Decimals = n
+	}
+}
+
+// WithScaledCounts adds a "scaled_count" column min-max scaling each count into [0, upper],
+// e.g. 1 or 100, so bar displays of datasets with very different magnitudes are comparable: the
+// smallest count becomes 0 and the largest upper. Values have four decimal places; see
+// ScaleCounts for the case of equal counts.
+func WithScaledCounts(upper float64) Option {
+	return func(c *Config) {
+		c.ScaleTo = upper
+	}
+}
+
+// WithCategory adds a "category" column holding DomainData.Category, as set by
+// customerimporter.WithCategory.
+func WithCategory() Option {
+	return func(c *Config) {
+		c.Category = true
+	}
+}
+
+// WithDomainNames adds a "company" column for human-readable reports, holding the company name
+// names maps each domain to, matched case-insensitively, or the domain itself when it is
+// unmapped. Use LoadDomainNames to read the mapping from a CSV file. Under
+// WithAnonymizedDomains unmapped domains show their pseudonym. The map is copied.
+func WithDomainNames(names map[string]string) Option {
+	return func(c *Config) {
+		c.DomainNames = make(map[string]string, len(names))
+		for domain, name := range names {
+			c.DomainNames[customerimporter.FoldDomain(domain)] = name
+		}
+	}
+}
+
+// WithSortOnExport guarantees the output order regardless of the order of the data passed in,
+// e.g. a slice built from a map, by sorting a copy of it by order (customerimporter.SortByDomain
+// or customerimporter.SortByCount) first. The caller's slice is not modified.
+func WithSortOnExport(order customerimporter.SortOrder) Option {
+	return func(c *Config) {
+		c.SortOnExport = true
+		c.SortOrder = order
+	}
+}
+
+// WithLogCounts adds a "log_count" column holding log10(count+1) with four decimals, for
+// plotting heavily skewed distributions: a count of 0 maps to 0, 9 to 1 and 99 to 2. The +1 keeps
+// single-customer domains visible instead of mapping them to 0 like plain log10. The count is the
+// reported one, i.e. after WithCountCap.
+func WithLogCounts() Option {
+	return func(c *Config) {
+		c.LogCounts = true
+	}
+}
+
+// WithChunkSize splits the output into files of at most size records, each with its own header,
+// named by numbering the output path before its extension: "out.csv" becomes "out-0001.csv",
+// "out-0002.csv" and so on. Chunks numbered above the last one, left by an earlier export of
+// more records, are removed with their checksum sidecars. It applies to Export and ExportData;
+// WithSkipUnchanged then works per chunk.
+func WithChunkSize(size int) Option {
+	return func(c *Config) {
+		c.ChunkSize = size
+	}
+}
+
+// WithManifest writes ManifestName next to the output file after every file export, listing
+// each file produced (chunks, checksum sidecars or the single output) with its size and record
+// count. Use Manifest.Add to list further files such as an importer rejects file.
+func WithManifest() Option {
+	return func(c *Config) {
+		c.Manifest = true
+	}
+}
+
+// WithNoClobber makes every file export fail with ErrOutputExists instead of truncating an
+// output file that already exists, guarding against accidentally overwriting earlier results.
+// With WithChunkSize each chunk is checked, and stale chunks fail the export rather than being
+// removed. Under WithSkipUnchanged an identical existing file is still reported as
+// StatusUnchanged. Checksum sidecars and the manifest are always rewritten.
+func WithNoClobber() Option {
+	return func(c *Config) {
+		c.NoClobber = true
+	}
+}
+
+// WithAnonymizedDomains replaces every domain in the CSV with its AnonymizeDomain pseudonym, such
+// as "domain-1a2b3c4d.example", for sharing sample outputs without leaking customer domains.
+// Records keep their counts and their order, including the one from WithSortOnExport, which is
+// applied to the real domains. The "domain_id" column hashes the pseudonym and the
+// "sample_email" column is left empty. Only the CSV of Export, ExportData, ExportTo and
+// NewRecordWriter is anonymized: WithTemplate, Publish and the other exports writing domains,
+// such as ExportList or ExportXLSX, fail with ErrAnonymizeUnsupported instead of leaking them.
+// Functions without an exporter, such as ExportListTo, always write the domains they are given.
+func WithAnonymizedDomains() Option {
+	return func(c *Config) {
+		c.AnonymizeDomains = true
+	}
+}
+
+// WithCountDelta adds a "delta" column for reconciling with another system such as a CRM:
+// the domain's count minus the count fetcher returns for it, negative when the upstream count
+// is higher. Raw counts are compared, before WithCountCap. Every record fetches once while it
+// is written; when a fetch fails the error is logged and the record's delta is left empty.
+func WithCountDelta(fetcher CountFetcher) Option {
+	return func(c *Config) {
+		c.CountFetcher = fetcher
+	}
+}
+
+// WithRowHMAC adds an "hmac" column for tamper-evident audit exports, holding RowHMAC of each
+// record's domain and count under key. Both are taken as written, i.e. after
+// WithAnonymizedDomains and WithCountCap. The key is copied.
+func WithRowHMAC(key []byte) Option {
+	return func(c *Config) {
+		c.RowHMACKey = HMACKey(slices.Clone(key))
+	}
+}
+
+// WithTemplate replaces the CSV written by Export, ExportData and ExportTo with free-form text
+// such as SQL INSERT statements or HTML table rows: tmpl is executed once per record with its
+// customerimporter.DomainData, e.g.
+//
+//	INSERT INTO domains (domain, customers) VALUES ('{{.Domain}}', {{.CustomerQuantity}});
+//
+// Templates associated with tmpl under the names TemplateHeader and TemplateFooter, e.g. through
+// {{define "header"}}, are executed once before and after the records with the whole slice.
+// WithSortOnExport and WithChunkSize still apply, with a header and footer per chunk; column
+// options do not, and NewRecordWriter fails with ErrStreamUnsupported. Values are written as
+// is, so escape them in the template where the target format requires it.
+func WithTemplate(tmpl *template.Template) Option {
+	return func(c *Config) {
+		c.Template = tmpl
+	}
+}
+
+// WithLineEnding forces the terminator of every record of the domain CSV, so output compared
+// across operating systems does not differ in line endings. The default, LineEndingLF, writes
+// "\n" regardless of the platform, as encoding/csv does. It applies to Export, ExportTo and
+// RecordWriter; the list, breakdown and other reports keep "\n".
+func WithLineEnding(ending LineEnding) Option {
+	return func(c *Config) {
+		c.LineEnding = ending
+	}
+}
+
+// WithSignedCounts writes every count as an int64 for consumers that only handle signed integers,
+// and fails the export with customerimporter.ErrCountOverflow instead of writing a count above
+// math.MaxInt64. Counts are checked after WithCountCap is applied.
+func WithSignedCounts() Option {
+	return func(c *Config) {
+		c.SignedCounts = true
+	}
+}
+
+// WithVerifyAfterWrite reads every file written by Export and ExportData back once it is closed,
+// for storage where a silently truncated or corrupted write is a concern. The file must parse
+// as CSV with one record per exported row plus the header, and its SHA-256 must match the bytes
+// handed to it; otherwise the export fails with ErrVerificationFailed. Under WithTemplate only
+// the checksum is compared. With WithChunkSize every chunk is verified. The reports and ExportTo
+// are not verified.
+func WithVerifyAfterWrite() Option {
+	return func(c *Config) {
+		c.VerifyAfterWrite = true
+	}
+}
+
+// withOutputWrapper wraps the writer of every output file with wrap. It is internal to the
+// package, for tests injecting write faults.
+func withOutputWrapper(wrap func(io.Writer) io.Writer) Option {
+	return func(c *Config) {
+		c.wrapOutput = wrap
+	}
+}
+
 // CustomerExporter exports customer domain statistics to CSV files.
 type CustomerExporter struct {
 	outputPath string
+	config     Config
 }
 
 // NewCustomerExporter creates a new CustomerExporter that will write to the specified file path.
 //
 // The outputPath should be a valid file path. The file is created when ExportData is called.
 // If the file already exists, it will be truncated (all existing content will be lost).
-func NewCustomerExporter(outputPath string) *CustomerExporter {
-	return &CustomerExporter{
+func NewCustomerExporter(outputPath string, opts ...Option) *CustomerExporter {
+	ex := &CustomerExporter{
 		outputPath: outputPath,
 	}
+	for _, opt := range opts {
+		opt(&ex.config)
+	}
+	return ex
+}
+
+// Config returns the effective configuration after all options were applied.
+func (ex CustomerExporter) Config() Config {
+	return ex.config
 }
 
 // ExportData writes customer domain statistics to a CSV file.
@@ -45,7 +381,7 @@ func NewCustomerExporter(outputPath string) *CustomerExporter {
 //	another.com,17
 //
 // The data parameter should be a slice of DomainData, typically from customerimporter.ImportDomainData.
-// The data is written in the order provided (no sorting is performed by this function).
+// The data is written in the order provided unless WithSortOnExport is set.
 //
 // WARNING: If the output file already exists, it will be truncated and all existing content will be lost.
 //
@@ -56,41 +392,302 @@ func NewCustomerExporter(outputPath string) *CustomerExporter {
 //
 // When verbose logging is enabled (via slog), export operations are logged.
 func (ex CustomerExporter) ExportData(data []customerimporter.DomainData) error {
+	_, err := ex.Export(data)
+	return err
+}
+
+// Export behaves like ExportData and additionally reports whether the output file was written
+// or, with WithSkipUnchanged, left untouched because its content was already up to date.
+func (ex CustomerExporter) Export(data []customerimporter.DomainData) (ExportStatus, error) {
 	if data == nil {
-		return fmt.Errorf("provided data is empty (nil)")
+		return "", fmt.Errorf("provided data is empty (nil)")
 	}
 
 	slog.Info("starting export", "file", ex.outputPath, "records", len(data))
 
-	outputFile, err := os.Create(ex.outputPath)
+	data, cols, err := ex.prepare(data)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return "", err
 	}
-	defer func() {
-		_ = outputFile.Close()
-	}()
 
-	if err := exportCsv(data, outputFile); err != nil {
-		return err
+	var status ExportStatus
+	var files []OutputFile
+	if ex.config.ChunkSize > 0 {
+		status, files, err = ex.exportChunks(data, cols)
+	} else {
+		status, files, err = ex.exportFile(data, cols)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := ex.writeManifest(files); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// exportFile writes data to the output path and returns the files it produced.
+func (ex CustomerExporter) exportFile(data []customerimporter.DomainData, cols []column) (ExportStatus, []OutputFile, error) {
+	files := []OutputFile{{Path: ex.outputPath, Records: len(data)}}
+	if ex.config.SkipUnchanged {
+		status, err := ex.exportIdempotent(data, cols)
+		if err != nil {
+			return "", nil, err
+		}
+		return status, append(files, OutputFile{Path: ex.outputPath + checksumSuffix}), nil
+	}
+
+	if err := ex.writeVerified(len(data), func(w io.Writer) error {
+		return ex.exportCsv(data, cols, w)
+	}); err != nil {
+		return "", nil, err
 	}
 
 	slog.Info("export written successfully", "file", ex.outputPath)
-	return nil
+	return StatusWritten, files, nil
 }
 
-func exportCsv(data []customerimporter.DomainData, output io.Writer) error {
-	headers := []string{"domain", "number_of_customers"}
-	csvWriter := csv.NewWriter(output)
-	defer csvWriter.Flush()
+// createOutput creates or truncates the file at path for writing, or fails with ErrOutputExists
+// when it already exists under NoClobber.
+func (ex CustomerExporter) createOutput(path string) (*os.File, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if ex.config.NoClobber {
+		flag |= os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flag, 0666)
+	if ex.config.NoClobber && errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("refusing to overwrite %s: %w", path, ErrOutputExists)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
+}
 
-	if err := csvWriter.Write(headers); err != nil {
+// writeOutput creates the output file and lets write fill it, closing the file before returning
+// so its size is final.
+func (ex CustomerExporter) writeOutput(write func(w io.Writer) error) error {
+	outputFile, err := ex.createOutput(ex.outputPath)
+	if err != nil {
 		return err
 	}
-	for _, v := range data {
-		pair := []string{v.Domain, strconv.FormatUint(v.CustomerQuantity, 10)}
-		if err := csvWriter.Write(pair); err != nil {
-			return err
+	var w io.Writer = outputFile
+	if ex.config.wrapOutput != nil {
+		w = ex.config.wrapOutput(w)
+	}
+	if err := write(w); err != nil {
+		_ = outputFile.Close()
+		return err
+	}
+	return outputFile.Close()
+}
+
+// ExportCounts writes a domain -> count map like ExportData. Go map iteration order is random,
+// so the entries are sorted alphabetically by domain first, making the output byte-identical
+// for equal maps. Returns an error if counts is nil.
+func (ex CustomerExporter) ExportCounts(counts map[string]uint64) error {
+	if counts == nil {
+		return fmt.Errorf("provided counts are empty (nil)")
+	}
+	return ex.ExportData(customerimporter.DomainDataFromCounts(counts))
+}
+
+// ExportTo writes the same CSV as ExportData to w instead of a file, e.g. to print results
+// to the terminal. File-only options such as WithSkipUnchanged are ignored.
+func (ex CustomerExporter) ExportTo(w io.Writer, data []customerimporter.DomainData) error {
+	data, cols, err := ex.prepare(data)
+	if err != nil {
+		return err
+	}
+	return ex.exportCsv(data, cols, w)
+}
+
+// prepare applies the configured ordering to data and resolves the output columns for it.
+func (ex CustomerExporter) prepare(data []customerimporter.DomainData) ([]customerimporter.DomainData, []column, error) {
+	if ex.config.Template != nil {
+		if err := ex.checkRealDomains(); err != nil {
+			return nil, nil, err
+		}
+	}
+	data, err := ex.ordered(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	cols, err := ex.columns(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, cols, nil
+}
+
+// ordered returns data sorted as configured with WithSortOnExport, or data itself otherwise.
+func (ex CustomerExporter) ordered(data []customerimporter.DomainData) ([]customerimporter.DomainData, error) {
+	if !ex.config.SortOnExport {
+		return data, nil
+	}
+	order := ex.config.SortOrder
+	if order == "" {
+		order = customerimporter.SortByDomain
+	}
+	data = slices.Clone(data)
+	if err := customerimporter.SortDomainData(data, order); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// column describes a single output CSV column. value receives the row index and its data.
+type column struct {
+	header string
+	value  func(i int, d customerimporter.DomainData) string
+}
+
+// columns returns the output columns in order: the default domain and count columns followed
+// by any optional columns enabled in the config. Columns derived from the whole dataset, such as
+// normalized counts, are precomputed from data.
+func (ex CustomerExporter) columns(data []customerimporter.DomainData) ([]column, error) {
+	if err := ex.config.LineEnding.validate(); err != nil {
+		return nil, err
+	}
+
+	domain := func(d customerimporter.DomainData) string {
+		return d.Domain
+	}
+	if ex.config.AnonymizeDomains {
+		domain = func(d customerimporter.DomainData) string {
+			return AnonymizeDomain(d.Domain)
+		}
+	}
+
+	cols := []column{
+		{header: "domain", value: func(_ int, d customerimporter.DomainData) string {
+			return domain(d)
+		}},
+		{header: "number_of_customers", value: func(_ int, d customerimporter.DomainData) string {
+			return strconv.FormatUint(d.CustomerQuantity, 10)
+		}},
+	}
+
+	count := func(d customerimporter.DomainData) uint64 {
+		return d.CustomerQuantity
+	}
+	if limit := ex.config.CountCap; limit > 0 {
+		count = func(d customerimporter.DomainData) uint64 {
+			return min(d.CustomerQuantity, limit)
+		}
+		cols[1].value = func(_ int, d customerimporter.DomainData) string {
+			return strconv.FormatUint(count(d), 10)
+		}
+		cols = append(cols, column{header: "capped", value: func(_ int, d customerimporter.DomainData) string {
+			return strconv.FormatBool(d.CustomerQuantity > limit)
+		}})
+	}
+
+	if ex.config.SignedCounts {
+		// Check up front so an overflow never leaves a half-written file behind
+		for _, d := range data {
+			d.CustomerQuantity = count(d)
+			if _, err := d.SignedQuantity(); err != nil {
+				return nil, err
+			}
+		}
+		if ex.config.NormalizeTo > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: normalized total %d", customerimporter.ErrCountOverflow, ex.config.NormalizeTo)
+		}
+		cols[1].value = func(_ int, d customerimporter.DomainData) string {
+			return strconv.FormatInt(int64(count(d)), 10)
+		}
+	}
+
+	if ex.config.DomainID != "" {
+		algo := ex.config.DomainID
+		if err := algo.validate(); err != nil {
+			return nil, err
+		}
+		cols = append(cols, column{header: "domain_id", value: func(_ int, d customerimporter.DomainData) string {
+			return DomainID(domain(d), algo)
+		}})
+	}
+
+	if target := ex.config.NormalizeTo; target > 0 {
+		normalized, err := NormalizeCounts(data, target)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, column{header: "normalized_count", value: func(i int, _ customerimporter.DomainData) string {
+			return strconv.FormatUint(normalized[i], 10)
+		}})
+	}
+
+	if ex.config.PerMille {
+		perMille, err := PerMille(data, ex.config.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, column{header: "per_mille", value: func(i int, _ customerimporter.DomainData) string {
+			return perMille[i]
+		}})
+	}
+
+	if upper := ex.config.ScaleTo; upper != 0 {
+		scaled, err := ScaleCounts(data, upper)
+		if err != nil {
+			return nil, err
 		}
+		cols = append(cols, column{header: "scaled_count", value: func(i int, _ customerimporter.DomainData) string {
+			return strconv.FormatFloat(scaled[i], 'f', 4, 64)
+		}})
+	}
+
+	if ex.config.LogCounts {
+		cols = append(cols, column{header: "log_count", value: func(_ int, d customerimporter.DomainData) string {
+			return strconv.FormatFloat(math.Log10(float64(count(d))+1), 'f', 4, 64)
+		}})
+	}
+
+	if ex.config.Category {
+		cols = append(cols, column{header: "category", value: func(_ int, d customerimporter.DomainData) string {
+			return string(d.Category)
+		}})
+	}
+
+	if names := ex.config.DomainNames; names != nil {
+		cols = append(cols, column{header: "company", value: func(_ int, d customerimporter.DomainData) string {
+			return companyName(names, d.Domain, domain(d))
+		}})
+	}
+
+	if fetcher := ex.config.CountFetcher; fetcher != nil {
+		cols = append(cols, deltaColumn(fetcher))
+	}
+
+	if ex.config.SampleEmail {
+		cols = append(cols, column{header: "sample_email", value: func(_ int, d customerimporter.DomainData) string {
+			if ex.config.AnonymizeDomains {
+				return ""
+			}
+			return d.SampleEmail
+		}})
+	}
+
+	if key := ex.config.RowHMACKey; len(key) > 0 {
+		cols = append(cols, column{header: "hmac", value: func(_ int, d customerimporter.DomainData) string {
+			return RowHMAC(key, domain(d), count(d))
+		}})
+	}
+	return cols, nil
+}
+
+// exportCsv writes data as CSV with cols, or through the Template when one is configured.
+func (ex CustomerExporter) exportCsv(data []customerimporter.DomainData, cols []column, output io.Writer) error {
+	if ex.config.Template != nil {
+		return ex.exportTemplate(data, output)
+	}
+	csvWriter := ex.csvWriter(output)
+	defer csvWriter.Flush()
+
+	if err := writeRecords(data, cols, csvWriter.Write); err != nil {
+		return err
 	}
 
 	// Check for any errors that occurred during flush
@@ -99,3 +696,24 @@ func exportCsv(data []customerimporter.DomainData, output io.Writer) error {
 	}
 	return nil
 }
+
+// writeRecords passes the header and then one record per element of data to write. The record
+// slice is reused between calls.
+func writeRecords(data []customerimporter.DomainData, cols []column, write func(record []string) error) error {
+	record := make([]string, len(cols))
+	for i, c := range cols {
+		record[i] = c.header
+	}
+	if err := write(record); err != nil {
+		return err
+	}
+	for row, v := range data {
+		for i, c := range cols {
+			record[i] = c.value(row, v)
+		}
+		if err := write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,8 +1,16 @@
 package exporter
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"importer/customerimporter"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -60,6 +68,167 @@ func TestExportEmptyData(t *testing.T) {
 	t.Log(err)
 }
 
+func TestExportCountCap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "big.com", CustomerQuantity: 1500},
+		{Domain: "edge.com", CustomerQuantity: 1000},
+		{Domain: "small.com", CustomerQuantity: 7},
+	}
+
+	if err := NewCustomerExporter(path, WithCountCap(1000)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,capped\n" +
+		"big.com,1000,true\n" +
+		"edge.com,1000,false\n" +
+		"small.com,7,false\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+	if data[0].CustomerQuantity != 1500 {
+		t.Errorf("input data modified: %+v", data[0])
+	}
+}
+
+func TestExportCountsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	counts := make(map[string]uint64)
+	for i := 0; i < 100; i++ {
+		counts[fmt.Sprintf("domain%03d.com", i)] = uint64(i)
+	}
+
+	var outputs [2][]byte
+	for i := range outputs {
+		path := filepath.Join(dir, fmt.Sprintf("out%d.csv", i))
+		if err := NewCustomerExporter(path).ExportCounts(counts); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[i] = out
+	}
+
+	if string(outputs[0]) != string(outputs[1]) {
+		t.Error("exporting the same map twice produced different output")
+	}
+	lines := strings.Split(strings.TrimSpace(string(outputs[0])), "\n")
+	if len(lines) != 101 || lines[1] != "domain000.com,0" || lines[100] != "domain099.com,99" {
+		t.Errorf("map export not sorted by domain: first=%q last=%q", lines[1], lines[len(lines)-1])
+	}
+}
+
+func TestExportCountsNil(t *testing.T) {
+	if err := NewCustomerExporter(filepath.Join(t.TempDir(), "out.csv")).ExportCounts(nil); err == nil {
+		t.Error("nil counts not rejected")
+	}
+}
+
+func TestExportSampleEmail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "example.com", CustomerQuantity: 2, SampleEmail: "john@example.com"},
+	}
+
+	if err := NewCustomerExporter(path, WithSampleEmail()).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,sample_email\nexample.com,2,john@example.com\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestExportCategory(t *testing.T) {
+	var sb strings.Builder
+	data := []customerimporter.DomainData{
+		{Domain: "acme.com", CustomerQuantity: 3, Category: customerimporter.CategoryCorporate},
+		{Domain: "gmail.com", CustomerQuantity: 2, Category: customerimporter.CategoryFreemail},
+	}
+
+	if err := NewCustomerExporter("", WithCategory()).ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,category\nacme.com,3,corporate\ngmail.com,2,freemail\n"
+	if sb.String() != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", sb.String(), want)
+	}
+}
+
+func TestExportSortOnExport(t *testing.T) {
+	data := make([]customerimporter.DomainData, 50)
+	for i := range data {
+		data[i] = customerimporter.DomainData{Domain: fmt.Sprintf("domain%02d.com", i), CustomerQuantity: uint64(i % 7)}
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(data), func(i, j int) {
+		data[i], data[j] = data[j], data[i]
+	})
+	first := data[0]
+
+	tests := []struct {
+		order customerimporter.SortOrder
+		less  func(l, r customerimporter.DomainData) bool
+	}{
+		{order: customerimporter.SortByDomain, less: func(l, r customerimporter.DomainData) bool {
+			return l.Domain < r.Domain
+		}},
+		{order: customerimporter.SortByCount, less: func(l, r customerimporter.DomainData) bool {
+			return l.CustomerQuantity > r.CustomerQuantity ||
+				l.CustomerQuantity == r.CustomerQuantity && l.Domain < r.Domain
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewCustomerExporter("", WithSortOnExport(tt.order)).ExportTo(&sb, data); err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			records = records[1:]
+			if len(records) != len(data) {
+				t.Fatalf("exported %d records, want %d", len(records), len(data))
+			}
+			rows := make([]customerimporter.DomainData, len(records))
+			for i, record := range records {
+				count, _ := strconv.ParseUint(record[1], 10, 64)
+				rows[i] = customerimporter.DomainData{Domain: record[0], CustomerQuantity: count}
+			}
+			for i := 1; i < len(rows); i++ {
+				if !tt.less(rows[i-1], rows[i]) {
+					t.Errorf("output not sorted by %s: %+v before %+v", tt.order, rows[i-1], rows[i])
+				}
+			}
+		})
+	}
+	if data[0] != first {
+		t.Errorf("input data reordered: first record is %+v, want %+v", data[0], first)
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	exporter := NewCustomerExporter("./test_output.csv", WithSkipUnchanged(), WithDomainID(HashSHA256))
+	got := exporter.Config().String()
+	for _, want := range []string{"exporter.skip_unchanged=true", "exporter.domain_id=sha256"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config description missing %q:\n%s", want, got)
+		}
+	}
+}
+
 func BenchmarkImportDomainData(b *testing.B) {
 	b.StopTimer()
 	dir := b.TempDir()
@@ -80,3 +249,162 @@ func BenchmarkImportDomainData(b *testing.B) {
 		}
 	}
 }
+
+func TestExportSignedCounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   uint64
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{name: "max int64", count: math.MaxInt64, want: "boundary.com,9223372036854775807\n"},
+		{name: "one above max int64", count: math.MaxInt64 + 1, wantErr: true},
+		{name: "max uint64", count: math.MaxUint64, wantErr: true},
+		{
+			name:  "capped below max int64",
+			count: math.MaxUint64,
+			opts:  []Option{WithCountCap(math.MaxInt64)},
+			want:  "boundary.com,9223372036854775807,true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.csv")
+			data := []customerimporter.DomainData{{Domain: "boundary.com", CustomerQuantity: tt.count}}
+			opts := append([]Option{WithSignedCounts()}, tt.opts...)
+
+			err := NewCustomerExporter(path, opts...).ExportData(data)
+			if tt.wantErr {
+				if !errors.Is(err, customerimporter.ErrCountOverflow) {
+					t.Errorf("expected ErrCountOverflow, got %v", err)
+				}
+				if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+					t.Errorf("output file written despite overflow: %v", statErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lines := strings.SplitAfter(string(got), "\n"); len(lines) < 2 || lines[1] != tt.want {
+				t.Errorf("exported CSV = %q, want data row %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportNoClobber(t *testing.T) {
+	data := []customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 2}}
+	const existing = "precious results\n"
+	const want = "domain,number_of_customers\nexample.com,2\n"
+
+	tests := []struct {
+		name    string
+		exists  bool
+		opts    []Option
+		export  func(ex *CustomerExporter) error
+		want    string
+		wantErr bool
+	}{
+		{name: "refuses to overwrite", exists: true, opts: []Option{WithNoClobber()}, want: existing, wantErr: true},
+		{name: "force overwrites", exists: true, want: want},
+		{name: "new file written", opts: []Option{WithNoClobber()}, want: want},
+		{
+			name:    "refuses to overwrite list",
+			exists:  true,
+			opts:    []Option{WithNoClobber()},
+			export:  func(ex *CustomerExporter) error { return ex.ExportList(data) },
+			want:    existing,
+			wantErr: true,
+		},
+		{
+			name:    "refuses to overwrite changed idempotent export",
+			exists:  true,
+			opts:    []Option{WithNoClobber(), WithSkipUnchanged()},
+			want:    existing,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.csv")
+			if tt.exists {
+				if err := os.WriteFile(path, []byte(existing), 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ex := NewCustomerExporter(path, tt.opts...)
+			export := tt.export
+			if export == nil {
+				export = func(ex *CustomerExporter) error { return ex.ExportData(data) }
+			}
+
+			err := export(ex)
+			if tt.wantErr != errors.Is(err, ErrOutputExists) {
+				t.Fatalf("export error = %v, want ErrOutputExists: %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportNoClobberUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 2}}
+	if _, err := NewCustomerExporter(path, WithSkipUnchanged()).Export(data); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := NewCustomerExporter(path, WithSkipUnchanged(), WithNoClobber()).Export(data)
+	if err != nil || status != StatusUnchanged {
+		t.Errorf("identical export = %s, %v; want %s", status, err, StatusUnchanged)
+	}
+}
+
+func TestExportLogCounts(t *testing.T) {
+	var sb strings.Builder
+	data := []customerimporter.DomainData{
+		{Domain: "empty.com", CustomerQuantity: 0},
+		{Domain: "single.com", CustomerQuantity: 1},
+		{Domain: "nine.com", CustomerQuantity: 9},
+		{Domain: "skewed.com", CustomerQuantity: 999999},
+		{Domain: "capped.com", CustomerQuantity: 5000},
+	}
+
+	if err := NewCustomerExporter("", WithLogCounts(), WithCountCap(99)).ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,capped,log_count\n" +
+		"empty.com,0,false,0.0000\n" +
+		"single.com,1,false,0.3010\n" +
+		"nine.com,9,false,1.0000\n" +
+		"skewed.com,99,true,2.0000\n" +
+		"capped.com,99,true,2.0000\n"
+	if sb.String() != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", sb.String(), want)
+	}
+
+	sb.Reset()
+	if err := NewCustomerExporter("", WithLogCounts()).ExportTo(&sb, data[3:4]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "domain,number_of_customers,log_count\nskewed.com,999999,6.0000\n"; sb.String() != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,26 @@
+package exporter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// HMACKey is the secret key of the "hmac" column. Diagnostics render it as a short SHA-256
+// fingerprint so -explain output and run fingerprints never contain the key itself.
+type HMACKey []byte
+
+func (k HMACKey) String() string {
+	sum := sha256.Sum256(k)
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
+
+// RowHMAC returns the value WithRowHMAC writes for a record: the hex HMAC-SHA256 under key of
+// "<domain>|<count>", with both as written in the record. Consumers holding the key recompute
+// it to detect altered records.
+func RowHMAC(key []byte, domain string, count uint64) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(domain + "|" + strconv.FormatUint(count, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
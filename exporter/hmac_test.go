@@ -0,0 +1,77 @@
+package exporter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"importer/customerimporter"
+	"strings"
+	"testing"
+)
+
+func TestWithRowHMAC(t *testing.T) {
+	key := []byte("audit-key")
+	data := []customerimporter.DomainData{
+		{Domain: "alpha.com", CustomerQuantity: 12},
+		{Domain: "beta.org", CustomerQuantity: 1},
+	}
+
+	var sb strings.Builder
+	ex := NewCustomerExporter("", WithRowHMAC(key))
+	if err := ex.ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("alpha.com|12"))
+	alpha := hex.EncodeToString(mac.Sum(nil))
+	mac.Reset()
+	mac.Write([]byte("beta.org|1"))
+	beta := hex.EncodeToString(mac.Sum(nil))
+
+	want := "domain,number_of_customers,hmac\n" +
+		"alpha.com,12," + alpha + "\n" +
+		"beta.org,1," + beta + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("output mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if tampered := RowHMAC(key, "alpha.com", 13); tampered == alpha {
+		t.Error("changing the count did not change the HMAC")
+	}
+	if rekeyed := RowHMAC([]byte("other-key"), "alpha.com", 12); rekeyed == alpha {
+		t.Error("changing the key did not change the HMAC")
+	}
+}
+
+func TestRowHMACCappedCount(t *testing.T) {
+	key := []byte("audit-key")
+	data := []customerimporter.DomainData{{Domain: "alpha.com", CustomerQuantity: 12}}
+
+	var sb strings.Builder
+	if err := NewCustomerExporter("", WithCountCap(10), WithRowHMAC(key)).ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,capped,hmac\nalpha.com,10,true," + RowHMAC(key, "alpha.com", 10) + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("output mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestHMACKeyNotDescribed(t *testing.T) {
+	key := []byte("super-secret-key")
+	ex := NewCustomerExporter("", WithRowHMAC(key))
+	config := ex.Config().String()
+	if strings.Contains(config, string(key)) || strings.Contains(config, hex.EncodeToString(key)) {
+		t.Errorf("config description leaks the HMAC key:\n%s", config)
+	}
+	if !strings.Contains(config, "exporter.row_hmac_key=sha256:") {
+		t.Errorf("config description lacks the key fingerprint:\n%s", config)
+	}
+
+	// The option keeps its own copy of the key
+	key[0] = 'S'
+	if got, want := ex.Config().RowHMACKey.String(), HMACKey("super-secret-key").String(); got != want {
+		t.Errorf("key changed with the caller's slice: %s, want %s", got, want)
+	}
+}
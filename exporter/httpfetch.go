@@ -0,0 +1,50 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HTTPCountFetcher is a CountFetcher querying an HTTP API: Fetch sends
+//
+//	GET <BaseURL>/<domain>
+//
+// and expects a 200 response with the JSON record also used by Publish, e.g.
+//
+//	{"domain":"example.com","number_of_customers":42}
+type HTTPCountFetcher struct {
+	// BaseURL is the URL the domain is appended to as the last path segment.
+	BaseURL string
+	// Client sends the requests. Nil uses http.DefaultClient, which has no timeout.
+	Client *http.Client
+}
+
+// Fetch implements CountFetcher.
+func (f HTTPCountFetcher) Fetch(domain string) (uint64, error) {
+	endpoint, err := url.JoinPath(f.BaseURL, url.PathEscape(domain))
+	if err != nil {
+		return 0, fmt.Errorf("invalid count API URL: %w", err)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GET %s: unexpected status %s", endpoint, resp.Status)
+	}
+	var rec Record
+	if err := json.NewDecoder(resp.Body).Decode(&rec); err != nil {
+		return 0, fmt.Errorf("GET %s: failed to decode response: %w", endpoint, err)
+	}
+	return rec.Customers, nil
+}
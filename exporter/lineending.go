@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// LineEnding selects the terminator written after each CSV record.
+type LineEnding string
+
+const (
+	// LineEndingLF ends records with "\n". It is the default, matching encoding/csv, on every
+	// platform.
+	LineEndingLF LineEnding = "lf"
+	// LineEndingCRLF ends records with "\r\n", as RFC 4180 specifies.
+	LineEndingCRLF LineEnding = "crlf"
+)
+
+// validate returns an error for line endings other than LineEndingLF and LineEndingCRLF. The
+// empty value selects the default.
+func (le LineEnding) validate() error {
+	switch le {
+	case "", LineEndingLF, LineEndingCRLF:
+		return nil
+	default:
+		return fmt.Errorf("unsupported line ending %q (want %q or %q)", string(le), LineEndingLF, LineEndingCRLF)
+	}
+}
+
+// csvWriter returns a csv.Writer for w terminating records with the configured line ending.
+func (ex CustomerExporter) csvWriter(w io.Writer) *csv.Writer {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.UseCRLF = ex.config.LineEnding == LineEndingCRLF
+	return csvWriter
+}
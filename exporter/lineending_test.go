@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithLineEnding(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 2},
+		{Domain: "b.org", CustomerQuantity: 1},
+	}
+	tests := []struct {
+		name   string
+		ending LineEnding
+		want   string
+	}{
+		{name: "default", want: "domain,number_of_customers\na.com,2\nb.org,1\n"},
+		{name: "lf", ending: LineEndingLF, want: "domain,number_of_customers\na.com,2\nb.org,1\n"},
+		{name: "crlf", ending: LineEndingCRLF, want: "domain,number_of_customers\r\na.com,2\r\nb.org,1\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.csv")
+			ex := NewCustomerExporter(path, WithLineEnding(tt.ending))
+			if err := ex.ExportData(data); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file output mismatch:\nhave: %q\nwant: %q", got, tt.want)
+			}
+
+			var sb strings.Builder
+			rw, err := ex.NewRecordWriter(&sb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range data {
+				if err := rw.Write(d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := rw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("streamed output mismatch:\nhave: %q\nwant: %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLineEndingInvalid(t *testing.T) {
+	var sb strings.Builder
+	err := NewCustomerExporter("", WithLineEnding("cr")).ExportTo(&sb, []customerimporter.DomainData{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported line ending") {
+		t.Errorf("expected unsupported line ending error, got %v", err)
+	}
+	if sb.Len() != 0 {
+		t.Errorf("wrote %q despite the error", sb.String())
+	}
+}
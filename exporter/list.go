@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// ExportList writes only the domain names of data to the output file, one per line in
+// alphabetical order, without header or counts, for feeding into other command-line tools.
+// Column options do not apply. Returns an error if data is nil or the file cannot be written.
+func (ex CustomerExporter) ExportList(data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportListTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("domain list written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportListTo writes the same list as ExportList to w.
+func ExportListTo(w io.Writer, data []customerimporter.DomainData) error {
+	domains := make([]string, len(data))
+	for i, d := range data {
+		domains[i] = d.Domain
+	}
+	slices.Sort(domains)
+
+	bw := bufio.NewWriter(w)
+	for _, domain := range domains {
+		if _, err := bw.WriteString(domain + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
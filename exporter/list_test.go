@@ -0,0 +1,46 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.txt")
+	data := []customerimporter.DomainData{
+		{Domain: "zeta.com", CustomerQuantity: 3},
+		{Domain: "alpha.com", CustomerQuantity: 12},
+		{Domain: "beta.org", CustomerQuantity: 1},
+	}
+
+	if err := NewCustomerExporter(path, WithDomainID(HashFNV)).ExportList(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "alpha.com\nbeta.org\nzeta.com\n"; string(got) != want {
+		t.Errorf("domain list mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+	if data[0].Domain != "zeta.com" {
+		t.Errorf("input data reordered: %+v", data)
+	}
+}
+
+func TestExportListTo(t *testing.T) {
+	var sb strings.Builder
+	if err := ExportListTo(&sb, []customerimporter.DomainData{}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "" {
+		t.Errorf("empty list = %q, want no output", sb.String())
+	}
+
+	if err := NewCustomerExporter(filepath.Join(t.TempDir(), "domains.txt")).ExportList(nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+}
@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManifestName is the file name of the manifest written next to the output by WithManifest.
+const ManifestName = "manifest.json"
+
+// OutputFile describes a single file produced by an export.
+type OutputFile struct {
+	// Path is the file path as passed to the exporter.
+	Path string `json:"path"`
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+	// Records is the number of data records in the file, excluding headers. Checksum sidecars
+	// hold no records.
+	Records int `json:"records"`
+}
+
+// Manifest lists the files produced by an export, for pipelines that need to pick them up.
+type Manifest struct {
+	Files []OutputFile `json:"files"`
+}
+
+// ReadManifest loads a manifest previously written with Write.
+func ReadManifest(path string) (Manifest, error) {
+	var m Manifest
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(content, &m); err != nil {
+		return m, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Add appends the file at path holding records data records, taking its size from the file
+// system, so the file must be complete.
+func (m *Manifest) Add(path string, records int) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to manifest: %w", path, err)
+	}
+	m.Files = append(m.Files, OutputFile{Path: path, Size: info.Size(), Records: records})
+	return nil
+}
+
+// Write stores the manifest as indented JSON at path.
+func (m Manifest) Write(path string) error {
+	if m.Files == nil {
+		m.Files = []OutputFile{}
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0666); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// ManifestPath returns where WithManifest writes the manifest: ManifestName in the directory of
+// the output file.
+func (ex CustomerExporter) ManifestPath() string {
+	return filepath.Join(filepath.Dir(ex.outputPath), ManifestName)
+}
+
+// writeManifest writes the manifest of files when WithManifest is set. Sizes are read from the
+// finished files.
+func (ex CustomerExporter) writeManifest(files []OutputFile) error {
+	if !ex.config.Manifest {
+		return nil
+	}
+	var m Manifest
+	for _, f := range files {
+		if err := m.Add(f.Path, f.Records); err != nil {
+			return err
+		}
+	}
+	return m.Write(ex.ManifestPath())
+}
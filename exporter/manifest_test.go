@@ -0,0 +1,114 @@
+package exporter
+
+import (
+	"fmt"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManifestChunkedExport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.csv")
+	data := make([]customerimporter.DomainData, 7)
+	for i := range data {
+		data[i] = customerimporter.DomainData{Domain: fmt.Sprintf("domain%d.com", i), CustomerQuantity: uint64(i + 1)}
+	}
+	exporter := NewCustomerExporter(path, WithChunkSize(3), WithManifest())
+
+	if err := exporter.ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	if exporter.ManifestPath() != filepath.Join(dir, ManifestName) {
+		t.Errorf("manifest path = %q, want it next to the output", exporter.ManifestPath())
+	}
+	m, err := ReadManifest(exporter.ManifestPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantRecords := []int{3, 3, 1}
+	if len(m.Files) != len(wantRecords) {
+		t.Fatalf("manifest lists %d files, want %d: %+v", len(m.Files), len(wantRecords), m.Files)
+	}
+	for i, f := range m.Files {
+		if f.Path != chunkPath(path, i+1) {
+			t.Errorf("file %d path = %q, want %q", i, f.Path, chunkPath(path, i+1))
+		}
+		content, err := os.ReadFile(f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Size != int64(len(content)) {
+			t.Errorf("%s size = %d, want %d", f.Path, f.Size, len(content))
+		}
+		if lines := strings.Count(string(content), "\n") - 1; f.Records != wantRecords[i] || lines != f.Records {
+			t.Errorf("%s records = %d, file has %d, want %d", f.Path, f.Records, lines, wantRecords[i])
+		}
+	}
+}
+
+func TestManifestSkipUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{{Domain: "example.com", CustomerQuantity: 2}}
+	exporter := NewCustomerExporter(path, WithSkipUnchanged(), WithManifest())
+
+	for i := 0; i < 2; i++ {
+		if _, err := exporter.Export(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := ReadManifest(exporter.ManifestPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 2 || m.Files[0].Path != path || m.Files[0].Records != 1 ||
+		m.Files[1].Path != path+checksumSuffix || m.Files[1].Records != 0 {
+		t.Errorf("manifest files = %+v, want output and sidecar", m.Files)
+	}
+}
+
+func TestManifestAdd(t *testing.T) {
+	dir := t.TempDir()
+	rejects := filepath.Join(dir, "rejects.csv")
+	if err := os.WriteFile(rejects, []byte("email,error\nbad,invalid\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+
+	if err := m.Add(rejects, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(filepath.Join(dir, "missing.csv"), 0); err == nil {
+		t.Error("missing file added to manifest")
+	}
+	manifestPath := filepath.Join(dir, ManifestName)
+	if err := m.Write(manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Files) != 1 || got.Files[0] != (OutputFile{Path: rejects, Size: 24, Records: 1}) {
+		t.Errorf("manifest files = %+v", got.Files)
+	}
+}
+
+func TestManifestList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	exporter := NewCustomerExporter(path, WithManifest())
+
+	if err := exporter.ExportList([]customerimporter.DomainData{{Domain: "a.com"}, {Domain: "b.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(exporter.ManifestPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 1 || m.Files[0] != (OutputFile{Path: path, Size: 12, Records: 2}) {
+		t.Errorf("manifest files = %+v", m.Files)
+	}
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"fmt"
+	"importer/customerimporter"
+	"slices"
+	"sync"
+)
+
+// MemoryExporter captures the records an export would write instead of writing them anywhere,
+// so the export pipeline can be tested or embedded without temporary files. It is safe for
+// concurrent use.
+type MemoryExporter struct {
+	ex CustomerExporter
+
+	mu      sync.Mutex
+	records [][]string
+}
+
+// NewMemoryExporter creates a MemoryExporter rendering records with the given options, like
+// ExportTo does. File-only options such as WithSkipUnchanged, WithChunkSize and WithManifest
+// are ignored.
+func NewMemoryExporter(opts ...Option) *MemoryExporter {
+	return &MemoryExporter{ex: *NewCustomerExporter("", opts...)}
+}
+
+// Export renders data and replaces the captured records with the result, header first, just
+// as an export to a file truncates it. Returns an error if data is nil, in which case the
+// previously captured records are kept.
+func (m *MemoryExporter) Export(data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	data, cols, err := m.ex.prepare(data)
+	if err != nil {
+		return err
+	}
+
+	records := make([][]string, 0, len(data)+1)
+	err = writeRecords(data, cols, func(record []string) error {
+		records = append(records, slices.Clone(record))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = records
+	return nil
+}
+
+// Records returns the records captured by the last successful Export, header first, or nil
+// before the first one. The returned slice is shared and must not be modified.
+func (m *MemoryExporter) Records() [][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.records
+}
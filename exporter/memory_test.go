@@ -0,0 +1,40 @@
+package exporter
+
+import (
+	"fmt"
+	"importer/customerimporter"
+	"testing"
+)
+
+func TestMemoryExporter(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "zeta.com", CustomerQuantity: 3},
+		{Domain: "alpha.com", CustomerQuantity: 12},
+	}
+	m := NewMemoryExporter(WithSortOnExport(customerimporter.SortByDomain), WithCountCap(10))
+	if got := m.Records(); got != nil {
+		t.Errorf("records before export = %v, want nil", got)
+	}
+
+	if err := m.Export(data); err != nil {
+		t.Fatal(err)
+	}
+	want := "[[domain number_of_customers capped] [alpha.com 10 true] [zeta.com 3 false]]"
+	if got := fmt.Sprint(m.Records()); got != want {
+		t.Errorf("records = %s, want %s", got, want)
+	}
+
+	if err := m.Export(nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+	if got := fmt.Sprint(m.Records()); got != want {
+		t.Errorf("records after failed export = %s, want %s", got, want)
+	}
+
+	if err := m.Export([]customerimporter.DomainData{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(m.Records()), "[[domain number_of_customers capped]]"; got != want {
+		t.Errorf("records after empty export = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// ExportMonthly writes the per-domain monthly breakdown returned by
+// customerimporter.ImportMonthly to the output file, in the given order:
+//
+//	domain,month,count
+//	example.com,2024-01,2
+//	example.com,2024-02,1
+//
+// Column options do not apply. Returns an error if data is nil or the file cannot be written.
+func (ex CustomerExporter) ExportMonthly(data []customerimporter.MonthlyData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportMonthlyTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("monthly breakdown written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportMonthlyTo writes the same CSV as ExportMonthly to w.
+func ExportMonthlyTo(w io.Writer, data []customerimporter.MonthlyData) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"domain", "month", "count"}); err != nil {
+		return err
+	}
+	for _, d := range data {
+		if err := csvWriter.Write([]string{d.Domain, d.Month, strconv.FormatUint(d.CustomerQuantity, 10)}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
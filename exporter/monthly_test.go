@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportMonthly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monthly.csv")
+	data := []customerimporter.MonthlyData{
+		{Domain: "example.com", Month: "2024-01", CustomerQuantity: 2},
+		{Domain: "example.com", Month: "2024-02", CustomerQuantity: 1},
+		{Domain: "other.com", Month: customerimporter.MonthUnknown, CustomerQuantity: 3},
+	}
+
+	if err := NewCustomerExporter(path, WithSampleEmail()).ExportMonthly(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,month,count\nexample.com,2024-01,2\nexample.com,2024-02,1\nother.com,unknown,3\n"
+	if string(got) != want {
+		t.Errorf("monthly CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestExportMonthlyTo(t *testing.T) {
+	var sb strings.Builder
+	if err := ExportMonthlyTo(&sb, []customerimporter.MonthlyData{}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "domain,month,count\n" {
+		t.Errorf("empty breakdown = %q, want header only", sb.String())
+	}
+
+	if err := NewCustomerExporter(filepath.Join(t.TempDir(), "monthly.csv")).ExportMonthly(nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+}
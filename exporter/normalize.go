@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"cmp"
+	"fmt"
+	"importer/customerimporter"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// NormalizeCounts scales each CustomerQuantity to its share of the total expressed out of target,
+// i.e. round(count / total * target), returning one value per element of data.
+//
+// Rounding uses the largest remainder method: every value starts as the exact share rounded down,
+// and the units still missing to reach target go to the entries with the largest fractional parts
+// (ties favour the earlier entry). The result therefore always sums to exactly target, and every
+// value is within one of the exact share. Shares are computed in exact 128-bit integer arithmetic.
+// If the total is zero, all values are zero. It fails when the total exceeds math.MaxUint64, as
+// weighted counts can.
+func NormalizeCounts(data []customerimporter.DomainData, target uint64) ([]uint64, error) {
+	total, err := sumCustomers(data)
+	if err != nil {
+		return nil, err
+	}
+	normalized := make([]uint64, len(data))
+	if total == 0 {
+		return normalized, nil
+	}
+
+	remainders := make([]uint64, len(data))
+	var assigned uint64
+	for i, d := range data {
+		// count <= total guarantees hi < total, as bits.Div64 requires
+		hi, lo := bits.Mul64(d.CustomerQuantity, target)
+		normalized[i], remainders[i] = bits.Div64(hi, lo, total)
+		assigned += normalized[i]
+	}
+
+	order := make([]int, len(data))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(remainders[b], remainders[a])
+	})
+	for _, i := range order[:target-assigned] {
+		normalized[i]++
+	}
+	return normalized, nil
+}
+
+// sumCustomers returns the total CustomerQuantity of data, failing instead of wrapping around when
+// it exceeds math.MaxUint64.
+func sumCustomers(data []customerimporter.DomainData) (uint64, error) {
+	var total, carry uint64
+	for _, d := range data {
+		if total, carry = bits.Add64(total, d.CustomerQuantity, 0); carry != 0 {
+			return 0, fmt.Errorf("total customers exceed %d", uint64(math.MaxUint64))
+		}
+	}
+	return total, nil
+}
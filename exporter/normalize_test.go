@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeCounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []uint64
+		target uint64
+		want   []uint64
+	}{
+		{
+			name:   "exact shares",
+			counts: []uint64{50, 30, 20},
+			target: 10000,
+			want:   []uint64{5000, 3000, 2000},
+		},
+		{
+			name:   "thirds distribute the remainder to the earliest tie",
+			counts: []uint64{1, 1, 1},
+			target: 100,
+			want:   []uint64{34, 33, 33},
+		},
+		{
+			name:   "largest fractional part wins",
+			counts: []uint64{1, 2, 4},
+			target: 10,
+			// exact shares 1.43, 2.86, 5.71
+			want: []uint64{1, 3, 6},
+		},
+		{
+			name:   "zero total",
+			counts: []uint64{0, 0},
+			target: 100,
+			want:   []uint64{0, 0},
+		},
+		{
+			name:   "large counts do not overflow",
+			counts: []uint64{1 << 62, 1 << 62},
+			target: 1 << 40,
+			want:   []uint64{1 << 39, 1 << 39},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]customerimporter.DomainData, len(tt.counts))
+			for i, c := range tt.counts {
+				data[i] = customerimporter.DomainData{CustomerQuantity: c}
+			}
+			got, err := NormalizeCounts(data, tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeCounts(%v, %d) = %v, want %v", tt.counts, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCountsOverflow(t *testing.T) {
+	// Weighted counts can exceed the uint64 range together while each fits
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 1 << 63},
+		{Domain: "b.com", CustomerQuantity: 1<<63 + 5},
+	}
+	if got, err := NormalizeCounts(data, 100); err == nil {
+		t.Errorf("NormalizeCounts() = %v, want an overflow error", got)
+	}
+	if err := NewCustomerExporter(filepath.Join(t.TempDir(), "out.csv"), WithNormalizedCounts(100)).ExportData(data); err == nil {
+		t.Error("ExportData() with an overflowing total succeeded, want an error")
+	}
+}
+
+func TestExportNormalizedCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 1},
+		{Domain: "b.com", CustomerQuantity: 3},
+	}
+
+	if err := NewCustomerExporter(path, WithNormalizedCounts(10000)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,normalized_count\na.com,1,2500\nb.com,3,7500\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
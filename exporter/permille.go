@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"fmt"
+	"importer/customerimporter"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// MaxPerMilleDecimals is the largest number of decimal places WithDecimals accepts.
+const MaxPerMilleDecimals = 16
+
+// PerMille returns each CustomerQuantity as customers per thousand of the total, i.e.
+// count / total * 1000, formatted with exactly decimals decimal places, one value per element of
+// data.
+//
+// Values are computed in exact 128-bit integer arithmetic rather than floating point and rounded
+// half up, so 0.0625 with two decimals is always "0.06" and 0.125 is always "0.13", whatever the
+// platform. Unlike NormalizeCounts the values are rounded independently and need not add up to
+// exactly 1000. If the total is zero, all values are zero. decimals must be between 0 and
+// MaxPerMilleDecimals, and the total must not exceed math.MaxUint64.
+func PerMille(data []customerimporter.DomainData, decimals int) ([]string, error) {
+	if decimals < 0 || decimals > MaxPerMilleDecimals {
+		return nil, fmt.Errorf("unsupported per mille decimals %d (want 0 to %d)", decimals, MaxPerMilleDecimals)
+	}
+	scale := uint64(1000)
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	total, err := sumCustomers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(data))
+	for i, d := range data {
+		var scaled uint64
+		if total > 0 {
+			// count <= total and scale < 2^64 guarantee hi < total, as bits.Div64 requires
+			hi, lo := bits.Mul64(d.CustomerQuantity, scale)
+			var rem uint64
+			scaled, rem = bits.Div64(hi, lo, total)
+			if rem >= total-rem {
+				scaled++
+			}
+		}
+		values[i] = formatFixed(scaled, decimals)
+	}
+	return values, nil
+}
+
+// formatFixed formats scaled, a value multiplied by 10^decimals, as a decimal number with
+// exactly decimals decimal places.
+func formatFixed(scaled uint64, decimals int) string {
+	digits := strconv.FormatUint(scaled, 10)
+	if decimals == 0 {
+		return digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	point := len(digits) - decimals
+	return digits[:point] + "." + digits[point:]
+}
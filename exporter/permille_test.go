@@ -0,0 +1,110 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPerMille(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   []uint64
+		decimals int
+		want     []string
+	}{
+		{
+			name:     "whole numbers",
+			counts:   []uint64{1, 3},
+			decimals: 0,
+			want:     []string{"250", "750"},
+		},
+		{
+			name:     "thirds",
+			counts:   []uint64{1, 2},
+			decimals: 2,
+			// exact values 333.333... and 666.666...
+			want: []string{"333.33", "666.67"},
+		},
+		{
+			name:     "halves round up",
+			counts:   []uint64{1, 1999},
+			decimals: 0,
+			// exact values 0.5 and 999.5
+			want: []string{"1", "1000"},
+		},
+		{
+			name:     "half of the last place rounds up",
+			counts:   []uint64{1, 7999},
+			decimals: 2,
+			// exact values 0.125 and 999.875
+			want: []string{"0.13", "999.88"},
+		},
+		{
+			name:     "below half rounds down with leading zeros",
+			counts:   []uint64{1, 15999},
+			decimals: 3,
+			// exact values 0.0625 and 999.9375
+			want: []string{"0.063", "999.938"},
+		},
+		{
+			name:     "zero total",
+			counts:   []uint64{0, 0},
+			decimals: 1,
+			want:     []string{"0.0", "0.0"},
+		},
+		{
+			name:     "large counts do not overflow",
+			counts:   []uint64{1 << 62, 1 << 62},
+			decimals: MaxPerMilleDecimals,
+			want:     []string{"500.0000000000000000", "500.0000000000000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]customerimporter.DomainData, len(tt.counts))
+			for i, c := range tt.counts {
+				data[i] = customerimporter.DomainData{CustomerQuantity: c}
+			}
+			got, err := PerMille(data, tt.decimals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("PerMille(%v, %d) = %v, want %v", tt.counts, tt.decimals, got, tt.want)
+			}
+		})
+	}
+
+	overflow := []customerimporter.DomainData{{CustomerQuantity: 1 << 63}, {CustomerQuantity: 1<<63 + 5}}
+	if got, err := PerMille(overflow, 1); err == nil {
+		t.Errorf("PerMille() of an overflowing total = %v, want an error", got)
+	}
+	if _, err := PerMille(nil, MaxPerMilleDecimals+1); err == nil {
+		t.Errorf("PerMille with %d decimals succeeded, want an error", MaxPerMilleDecimals+1)
+	}
+}
+
+func TestExportPerMille(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 1},
+		{Domain: "b.com", CustomerQuantity: 2},
+		{Domain: "c.com", CustomerQuantity: 5},
+	}
+
+	if err := NewCustomerExporter(path, WithPerMille(), WithDecimals(1)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,per_mille\na.com,1,125.0\nb.com,2,250.0\nc.com,5,625.0\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultPostProcessTimeout is the time a CommandProcessor without a Timeout may run.
+const DefaultPostProcessTimeout = 30 * time.Second
+
+// postProcessStderrLimit is the number of bytes of the command's stderr quoted in errors.
+const postProcessStderrLimit = 512
+
+// ErrPostProcessFailed is wrapped by every CommandProcessor error caused by the command: a
+// non-zero exit, a timeout or output that is not a valid JSON record array.
+var ErrPostProcessFailed = errors.New("post-processor failed")
+
+// CommandProcessor transforms aggregated domain data with an external command, for custom
+// transforms that cannot live in this module. Process writes the data to the command's stdin as
+// a JSON array of the records also used by Publish, e.g.
+//
+//	[{"domain":"example.com","number_of_customers":42}]
+//
+// and reads the transformed array back from its stdout, so any program reading and writing JSON
+// can filter, rename, re-count or reorder domains.
+type CommandProcessor struct {
+	// Command is the program to run followed by its arguments. It is run directly, not
+	// through a shell.
+	Command []string
+	// Timeout kills the command when it runs longer. Zero uses DefaultPostProcessTimeout.
+	Timeout time.Duration
+}
+
+// Process runs the command on data and returns the records it printed, in its order. Fields
+// outside Record, such as RoleAccounts, are not passed through. The command's stderr is logged
+// on success and quoted in the error on failure. Errors caused by the command wrap
+// ErrPostProcessFailed; when ctx is done the command is killed and ctx.Err() is returned.
+func (p CommandProcessor) Process(ctx context.Context, data []customerimporter.DomainData) ([]customerimporter.DomainData, error) {
+	if len(p.Command) == 0 || p.Command[0] == "" {
+		return nil, fmt.Errorf("post-processor command is empty")
+	}
+	records := make([]Record, len(data))
+	for i, d := range data {
+		records[i] = newRecord(d)
+	}
+	input, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode post-processor input: %w", err)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPostProcessTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(runCtx, p.Command[0], p.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children of the command may keep its output open after it was killed
+	cmd.WaitDelay = time.Second
+
+	slog.Info("starting post-processor", "command", p.Command, "records", len(data))
+	start := time.Now()
+	runErr := cmd.Run()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: %s did not finish within %s%s", ErrPostProcessFailed, p.Command[0], timeout, stderrSuffix(stderr.Bytes()))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		return nil, fmt.Errorf("%w: %s exited with status %d%s", ErrPostProcessFailed, p.Command[0], exitErr.ExitCode(), stderrSuffix(stderr.Bytes()))
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("failed to run post-processor: %w", runErr)
+	}
+	if stderr.Len() > 0 {
+		slog.Info("post-processor stderr", "command", p.Command[0], "output", strings.TrimSpace(stderr.String()))
+	}
+
+	var transformed []Record
+	if err := json.Unmarshal(stdout.Bytes(), &transformed); err != nil {
+		return nil, fmt.Errorf("%w: %s printed no JSON record array: %v", ErrPostProcessFailed, p.Command[0], err)
+	}
+	result := make([]customerimporter.DomainData, len(transformed))
+	for i, rec := range transformed {
+		if rec.Domain == "" {
+			return nil, fmt.Errorf("%w: %s printed a record without domain at index %d", ErrPostProcessFailed, p.Command[0], i)
+		}
+		result[i] = customerimporter.DomainData{
+			Domain:           rec.Domain,
+			CustomerQuantity: rec.Customers,
+			SampleEmail:      rec.SampleEmail,
+			Category:         customerimporter.Category(rec.Category),
+		}
+	}
+	slog.Info("post-processor complete", "records", len(result), "duration", time.Since(start).Round(time.Millisecond).String())
+	return result, nil
+}
+
+// stderrSuffix quotes the end of a failed command's stderr for its error message, or returns ""
+// when it printed nothing.
+func stderrSuffix(stderr []byte) string {
+	stderr = bytes.TrimSpace(stderr)
+	if len(stderr) == 0 {
+		return ""
+	}
+	if len(stderr) > postProcessStderrLimit {
+		stderr = stderr[len(stderr)-postProcessStderrLimit:]
+	}
+	return fmt.Sprintf(": %q", stderr)
+}
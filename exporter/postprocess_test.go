@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"importer/customerimporter"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandProcessor(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell available:", err)
+	}
+	data := []customerimporter.DomainData{
+		{Domain: "alpha.com", CustomerQuantity: 12, Category: customerimporter.CategoryCorporate},
+		{Domain: "beta.org", CustomerQuantity: 4, SampleEmail: "jo@beta.org"},
+	}
+
+	tests := []struct {
+		name    string
+		command []string
+		timeout time.Duration
+		want    []customerimporter.DomainData
+		wantErr string
+	}{
+		{name: "identity", command: []string{"cat"}, want: data},
+		{name: "transform", command: []string{"sh", "-c", `sed 's/"alpha.com"/"Alpha Inc"/; s/:4/:40/'`}, want: []customerimporter.DomainData{
+			{Domain: "Alpha Inc", CustomerQuantity: 12, Category: customerimporter.CategoryCorporate},
+			{Domain: "beta.org", CustomerQuantity: 40, SampleEmail: "jo@beta.org"},
+		}},
+		{name: "empty result", command: []string{"sh", "-c", "cat >/dev/null; echo '[]'"}, want: []customerimporter.DomainData{}},
+		{name: "exit status", command: []string{"sh", "-c", "echo boom >&2; exit 3"}, wantErr: `exited with status 3: "boom"`},
+		{name: "timeout", command: []string{"sleep", "5"}, timeout: 50 * time.Millisecond, wantErr: "did not finish within 50ms"},
+		{name: "invalid output", command: []string{"echo", "done"}, wantErr: "printed no JSON record array"},
+		{name: "missing domain", command: []string{"echo", `[{"number_of_customers":1}]`}, wantErr: "record without domain at index 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CommandProcessor{Command: tt.command, Timeout: tt.timeout}.Process(context.Background(), data)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrPostProcessFailed) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Process() error = %v, want %v containing %q", err, ErrPostProcessFailed, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Process() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandProcessorErrors(t *testing.T) {
+	if _, err := (CommandProcessor{}).Process(context.Background(), nil); err == nil {
+		t.Error("Process() without command succeeded, want an error")
+	}
+	_, err := CommandProcessor{Command: []string{"./does-not-exist"}}.Process(context.Background(), nil)
+	if err == nil || errors.Is(err, ErrPostProcessFailed) {
+		t.Errorf("Process() of a missing program = %v, want a start error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (CommandProcessor{Command: []string{"cat"}}).Process(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Process() with a canceled context = %v, want %v", err, context.Canceled)
+	}
+}
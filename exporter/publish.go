@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"importer/customerimporter"
+	"log/slog"
+)
+
+// Publisher sends a single keyed message to a message broker such as Kafka or NATS. Implement
+// it with an adapter around the broker client of your choice; Publish must be safe to call
+// repeatedly and should honour ctx for cancellation.
+type Publisher interface {
+	Publish(ctx context.Context, key string, value []byte) error
+}
+
+// Record is the JSON form of a DomainData, published by Publish and listed by ExportEnvelope.
+type Record struct {
+	Domain      string `json:"domain"`
+	Customers   uint64 `json:"number_of_customers"`
+	SampleEmail string `json:"sample_email,omitempty"`
+	Category    string `json:"category,omitempty"`
+}
+
+// newRecord returns the Record of d.
+func newRecord(d customerimporter.DomainData) Record {
+	return Record{
+		Domain:      d.Domain,
+		Customers:   d.CustomerQuantity,
+		SampleEmail: d.SampleEmail,
+		Category:    string(d.Category),
+	}
+}
+
+// Publish sends every record of data to publisher as a JSON message keyed by its domain, e.g.
+//
+//	{"domain":"example.com","number_of_customers":42}
+//
+// with "sample_email" and "category" included when set. Records are published one at a time in
+// order, after applying WithSortOnExport; with WithSignedCounts nothing is published when a count
+// overflows int64. Other CSV column options do not apply. Publishing stops at the first error or
+// when ctx is done.
+func (ex CustomerExporter) Publish(ctx context.Context, publisher Publisher, data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+	data, err := ex.ordered(data)
+	if err != nil {
+		return err
+	}
+	if ex.config.SignedCounts {
+		for _, d := range data {
+			if _, err := d.SignedQuantity(); err != nil {
+				return err
+			}
+		}
+	}
+
+	slog.Info("starting publish", "records", len(data))
+	for _, d := range data {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		value, err := json.Marshal(newRecord(d))
+		if err != nil {
+			return fmt.Errorf("failed to encode %q: %w", d.Domain, err)
+		}
+		if err := publisher.Publish(ctx, d.Domain, value); err != nil {
+			return fmt.Errorf("failed to publish %q: %w", d.Domain, err)
+		}
+	}
+	slog.Info("publish complete", "records", len(data))
+	return nil
+}
@@ -0,0 +1,107 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"importer/customerimporter"
+	"math"
+	"testing"
+)
+
+// message is a single call captured by fakePublisher.
+type message struct {
+	key   string
+	value string
+}
+
+// fakePublisher records every published message and fails once failAfter messages were sent.
+type fakePublisher struct {
+	messages  []message
+	failAfter int
+}
+
+func (f *fakePublisher) Publish(_ context.Context, key string, value []byte) error {
+	if f.failAfter > 0 && len(f.messages) == f.failAfter {
+		return errors.New("broker unavailable")
+	}
+	f.messages = append(f.messages, message{key: key, value: string(value)})
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "gmail.com", CustomerQuantity: 2, Category: customerimporter.CategoryFreemail},
+		{Domain: "example.com", CustomerQuantity: 42, SampleEmail: "john@example.com"},
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []message
+	}{
+		{
+			name: "input order",
+			want: []message{
+				{key: "gmail.com", value: `{"domain":"gmail.com","number_of_customers":2,"category":"freemail"}`},
+				{key: "example.com", value: `{"domain":"example.com","number_of_customers":42,"sample_email":"john@example.com"}`},
+			},
+		},
+		{
+			name: "sorted",
+			opts: []Option{WithSortOnExport(customerimporter.SortByDomain)},
+			want: []message{
+				{key: "example.com", value: `{"domain":"example.com","number_of_customers":42,"sample_email":"john@example.com"}`},
+				{key: "gmail.com", value: `{"domain":"gmail.com","number_of_customers":2,"category":"freemail"}`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			if err := NewCustomerExporter("", tt.opts...).Publish(context.Background(), publisher, data); err != nil {
+				t.Fatal(err)
+			}
+			if len(publisher.messages) != len(tt.want) {
+				t.Fatalf("published %d messages, want %d: %v", len(publisher.messages), len(tt.want), publisher.messages)
+			}
+			for i, want := range tt.want {
+				if publisher.messages[i] != want {
+					t.Errorf("message %d = %+v, want %+v", i, publisher.messages[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 1},
+		{Domain: "b.com", CustomerQuantity: math.MaxUint64},
+	}
+
+	publisher := &fakePublisher{failAfter: 1}
+	if err := NewCustomerExporter("").Publish(context.Background(), publisher, data); err == nil {
+		t.Error("expected publisher error, got nil")
+	}
+	if len(publisher.messages) != 1 {
+		t.Errorf("published %d messages before the failure, want 1", len(publisher.messages))
+	}
+
+	publisher = &fakePublisher{}
+	err := NewCustomerExporter("", WithSignedCounts()).Publish(context.Background(), publisher, data)
+	if !errors.Is(err, customerimporter.ErrCountOverflow) || len(publisher.messages) != 0 {
+		t.Errorf("signed overflow: err = %v, %d messages published, want ErrCountOverflow and none", err, len(publisher.messages))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	publisher = &fakePublisher{}
+	if err := NewCustomerExporter("").Publish(ctx, publisher, data); !errors.Is(err, context.Canceled) || len(publisher.messages) != 0 {
+		t.Errorf("cancelled: err = %v, %d messages published, want context.Canceled and none", err, len(publisher.messages))
+	}
+
+	if err := NewCustomerExporter("").Publish(context.Background(), &fakePublisher{}, nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+}
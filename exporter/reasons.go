@@ -0,0 +1,49 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// ExportSkipReasons writes the reason-frequency table of the rows an import skipped under
+// customerimporter.ErrorPolicySkip, taken from Summary.SkipReasons, as "reason,rows" records with
+// the most frequent reason first:
+//
+//	reason,rows
+//	empty_domain,12
+//	multiple_at,3
+//
+// Reasons that did not occur are left out, so a clean import writes the header only. Column
+// options do not apply.
+func (ex CustomerExporter) ExportSkipReasons(counts customerimporter.SkipReasonCounts) error {
+	reasons := counts.Top(len(counts))
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return writeSkipReasons(w, reasons)
+	}); err != nil {
+		return err
+	}
+	slog.Info("skip reasons written successfully", "file", ex.outputPath, "reasons", len(reasons))
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(reasons)}})
+}
+
+// ExportSkipReasonsTo writes the same CSV as ExportSkipReasons to w.
+func ExportSkipReasonsTo(w io.Writer, counts customerimporter.SkipReasonCounts) error {
+	return writeSkipReasons(w, counts.Top(len(counts)))
+}
+
+func writeSkipReasons(w io.Writer, reasons []customerimporter.ReasonCount) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"reason", "rows"}); err != nil {
+		return err
+	}
+	for _, r := range reasons {
+		if err := csvWriter.Write([]string{r.Reason.String(), strconv.FormatUint(r.Rows, 10)}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
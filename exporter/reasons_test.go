@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"context"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportSkipReasons(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "customers.csv")
+	content := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,john@example.com,Male,1.1.1.1\n" +
+		"Jane,Roe,jane@,Female,1.1.1.2\n" +
+		"Max,Poe,max@@example.com,Male,1.1.1.3\n" +
+		"Ann,Lee,ann@,Female,1.1.1.4\n" +
+		"Bob,Kay,bob.example.com,Male,1.1.1.5\n" +
+		"Eve,Fox,,Female,1.1.1.6\n" +
+		"Tom,Ray,tom@,Male,1.1.1.7\n" +
+		"Sue,Ivy,sue@example.org,Female,1.1.1.8\n"
+	if err := os.WriteFile(input, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	importer := customerimporter.NewCustomerImporter(input, customerimporter.WithErrorPolicy(customerimporter.ErrorPolicySkip))
+	_, summary, err := importer.ImportWithSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "reasons.csv")
+	if err := NewCustomerExporter(path).ExportSkipReasons(summary.SkipReasons); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "reason,rows\n" +
+		"empty_domain,3\n" +
+		"empty_email,1\n" +
+		"missing_at,1\n" +
+		"multiple_at,1\n"
+	if string(got) != want {
+		t.Errorf("skip reasons mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	var sb strings.Builder
+	if err := ExportSkipReasonsTo(&sb, customerimporter.SkipReasonCounts{}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "reason,rows\n" {
+		t.Errorf("clean import = %q, want the header only", sb.String())
+	}
+}
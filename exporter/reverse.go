@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ReverseIndexSeparator joins the domains sharing a count in the reverse index output.
+const ReverseIndexSeparator = ";"
+
+// ExportReverseIndex writes the reverse index of data (see customerimporter.GroupByCount) to the
+// output file, one row per distinct count in ascending order:
+//
+//	number_of_customers,domains
+//	1,alpha.com;beta.com
+//	3,gamma.com
+//
+// Domains are sorted alphabetically and joined with ReverseIndexSeparator. Column options such
+// as WithDomainID do not apply. Returns an error if data is nil or the file cannot be written.
+func (ex CustomerExporter) ExportReverseIndex(data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportReverseIndexTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("reverse index written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(customerimporter.GroupByCount(data))}})
+}
+
+// ExportReverseIndexTo writes the same CSV as ExportReverseIndex to w.
+func ExportReverseIndexTo(w io.Writer, data []customerimporter.DomainData) error {
+	groups := customerimporter.GroupByCount(data)
+	counts := make([]uint64, 0, len(groups))
+	for count := range groups {
+		counts = append(counts, count)
+	}
+	slices.Sort(counts)
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"number_of_customers", "domains"}); err != nil {
+		return err
+	}
+	for _, count := range counts {
+		record := []string{strconv.FormatUint(count, 10), strings.Join(groups[count], ReverseIndexSeparator)}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportReverseIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "zeta.com", CustomerQuantity: 3},
+		{Domain: "alpha.com", CustomerQuantity: 12},
+		{Domain: "beta.com", CustomerQuantity: 3},
+		{Domain: "gamma.com", CustomerQuantity: 1},
+	}
+
+	if err := NewCustomerExporter(path).ExportReverseIndex(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "number_of_customers,domains\n" +
+		"1,gamma.com\n" +
+		"3,beta.com;zeta.com\n" +
+		"12,alpha.com\n"
+	if string(got) != want {
+		t.Errorf("reverse index mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestExportReverseIndexErrors(t *testing.T) {
+	if err := NewCustomerExporter(filepath.Join(t.TempDir(), "index.csv")).ExportReverseIndex(nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+	data := []customerimporter.DomainData{{Domain: "a.com", CustomerQuantity: 1}}
+	if err := NewCustomerExporter("/invalid/path/index.csv").ExportReverseIndex(data); err == nil {
+		t.Error("expected error for invalid path, got nil")
+	}
+
+	var sb strings.Builder
+	if err := ExportReverseIndexTo(&sb, []customerimporter.DomainData{}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "number_of_customers,domains\n" {
+		t.Errorf("empty reverse index = %q, want header only", sb.String())
+	}
+}
@@ -0,0 +1,46 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// ExportRollup writes aggregated totals, such as those of customerimporter.GroupByDomainLength,
+// to the output file as "<dimension>,number_of_customers" rows in the order of labels:
+//
+//	domain_length,number_of_customers
+//	<=5,12
+//	6-10,30
+//
+// Labels missing from totals are written with a zero count. Column options do not apply.
+func (ex CustomerExporter) ExportRollup(dimension string, labels []string, totals map[string]uint64) error {
+	if totals == nil {
+		return fmt.Errorf("provided totals are empty (nil)")
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportRollupTo(w, dimension, labels, totals)
+	}); err != nil {
+		return err
+	}
+	slog.Info("rollup written successfully", "file", ex.outputPath, "dimension", dimension)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(labels)}})
+}
+
+// ExportRollupTo writes the same CSV as ExportRollup to w.
+func ExportRollupTo(w io.Writer, dimension string, labels []string, totals map[string]uint64) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{dimension, "number_of_customers"}); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if err := csvWriter.Write([]string{label, strconv.FormatUint(totals[label], 10)}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,38 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportRollup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollup.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "x.io", CustomerQuantity: 3},
+		{Domain: "example.com", CustomerQuantity: 5},
+		{Domain: "mail.com", CustomerQuantity: 2},
+	}
+	totals := customerimporter.GroupByDomainLength(data, customerimporter.DefaultLengthBuckets)
+	labels := customerimporter.LengthBucketLabels(customerimporter.DefaultLengthBuckets)
+
+	if err := NewCustomerExporter(path).ExportRollup("domain_length", labels, totals); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain_length,number_of_customers\n" +
+		"<=5,3\n" +
+		"6-10,2\n" +
+		"11+,5\n"
+	if string(got) != want {
+		t.Errorf("rollup mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if err := NewCustomerExporter(path).ExportRollup("domain_length", labels, nil); err == nil {
+		t.Error("expected error for nil totals, got nil")
+	}
+}
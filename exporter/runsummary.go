@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"importer/customerimporter"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// RunSummary is the machine-readable outcome of a run written by WriteRunSummary, for wrappers
+// that would otherwise scrape the logs.
+type RunSummary struct {
+	// Status is "success" for exit code zero and "failed" otherwise.
+	Status string `json:"status"`
+	// ExitCode is the exit status of the process.
+	ExitCode int `json:"exit_code"`
+	// Error describes why the run failed or was cut short, empty otherwise.
+	Error string `json:"error,omitempty"`
+	// Source names the input, such as its file path.
+	Source string `json:"source"`
+	// Rows, Domains, Customers, SkippedRows and Duplicates are taken from the import Summary,
+	// zero when the run failed before importing.
+	Rows        uint64 `json:"rows"`
+	Domains     int    `json:"unique_domains"`
+	Customers   uint64 `json:"total_customers"`
+	SkippedRows uint64 `json:"skipped_rows"`
+	Duplicates  uint64 `json:"duplicates"`
+	// DurationMillis is the wall-clock time of the run in milliseconds.
+	DurationMillis int64 `json:"duration_ms"`
+	// FinishedAt is the UTC time the run ended.
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// NewRunSummary returns the RunSummary of a run over source that took duration and exits with
+// exitCode. failure describes why the run failed, or why a successful run holds a partial
+// result, as at a time budget, and is empty otherwise.
+func NewRunSummary(source string, summary customerimporter.Summary, duration time.Duration, exitCode int, failure string) RunSummary {
+	status := "success"
+	if exitCode != 0 {
+		status = "failed"
+	}
+	return RunSummary{
+		Status:         status,
+		ExitCode:       exitCode,
+		Error:          failure,
+		Source:         source,
+		Rows:           summary.Rows,
+		Domains:        summary.Domains,
+		Customers:      summary.Customers,
+		SkippedRows:    summary.SkippedRows,
+		Duplicates:     summary.Duplicates,
+		DurationMillis: duration.Milliseconds(),
+		FinishedAt:     time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+// RunRecorder collects what the RunSummary of a run reports while the run progresses: the
+// import Summary once known and the last error logged through a handler returned by Handler,
+// so a process can write its summary from wherever it exits. It is safe for concurrent use.
+type RunRecorder struct {
+	source string
+	start  time.Time
+
+	mu      sync.Mutex
+	summary customerimporter.Summary
+	failure string
+}
+
+// NewRunRecorder returns a recorder for a run over source starting now.
+func NewRunRecorder(source string) *RunRecorder {
+	return &RunRecorder{source: source, start: time.Now()}
+}
+
+// SetSummary records the import summary of the run.
+func (r *RunRecorder) SetSummary(summary customerimporter.Summary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary = summary
+}
+
+// Fail records failure as the reason the run failed, replacing any earlier one.
+func (r *RunRecorder) Fail(failure string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failure = failure
+}
+
+// Handler wraps next so every record logged at error level is recorded with Fail, as
+// "message: error" when it has an "error" attribute and as its message otherwise.
+func (r *RunRecorder) Handler(next slog.Handler) slog.Handler {
+	return failureHandler{Handler: next, recorder: r}
+}
+
+// Summary returns the RunSummary of the run ending now with exitCode.
+func (r *RunRecorder) Summary(exitCode int) RunSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return NewRunSummary(r.source, r.summary, time.Since(r.start), exitCode, r.failure)
+}
+
+// failureHandler is the slog.Handler of RunRecorder.Handler.
+type failureHandler struct {
+	slog.Handler
+	recorder *RunRecorder
+}
+
+func (h failureHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		failure := r.Message
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key != "error" {
+				return true
+			}
+			failure += ": " + a.Value.String()
+			return false
+		})
+		h.recorder.Fail(failure)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h failureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return failureHandler{Handler: h.Handler.WithAttrs(attrs), recorder: h.recorder}
+}
+
+func (h failureHandler) WithGroup(name string) slog.Handler {
+	return failureHandler{Handler: h.Handler.WithGroup(name), recorder: h.recorder}
+}
+
+// WriteRunSummary writes s to path as a single line of JSON, replacing the file. path may name
+// an inherited file descriptor such as /dev/fd/3, keeping the summary apart from stdout and
+// stderr.
+func WriteRunSummary(path string, s RunSummary) error {
+	line, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode run summary: %w", err)
+	}
+	return os.WriteFile(path, append(line, '\n'), 0666)
+}
@@ -0,0 +1,110 @@
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteRunSummary(t *testing.T) {
+	summary := customerimporter.Summary{Rows: 10, Domains: 3, Customers: 8, SkippedRows: 2, Duplicates: 1}
+	tests := []struct {
+		name       string
+		summary    customerimporter.Summary
+		exitCode   int
+		failure    string
+		wantStatus string
+		wantRows   float64
+	}{
+		{name: "success", summary: summary, wantStatus: "success", wantRows: 10},
+		{name: "import failure", exitCode: 1, failure: "failed to import customer data: invalid email", wantStatus: "failed"},
+		{name: "interrupted", summary: summary, exitCode: 130, failure: "import interrupted", wantStatus: "failed", wantRows: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "summary.json")
+			s := NewRunSummary("customers.csv", tt.summary, 1500*time.Millisecond, tt.exitCode, tt.failure)
+			if err := WriteRunSummary(path, s); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(content); n == 0 || content[n-1] != '\n' {
+				t.Fatalf("summary %q is not a single terminated line", content)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(content, &got); err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]any{
+				"status":          tt.wantStatus,
+				"exit_code":       float64(tt.exitCode),
+				"source":          "customers.csv",
+				"rows":            tt.wantRows,
+				"unique_domains":  float64(tt.summary.Domains),
+				"total_customers": float64(tt.summary.Customers),
+				"skipped_rows":    float64(tt.summary.SkippedRows),
+				"duplicates":      float64(tt.summary.Duplicates),
+				"duration_ms":     float64(1500),
+			}
+			if tt.failure != "" {
+				want["error"] = tt.failure
+			}
+			for key, value := range want {
+				if got[key] != value {
+					t.Errorf("%s = %v, want %v", key, got[key], value)
+				}
+			}
+			if _, ok := got["error"]; ok != (tt.failure != "") {
+				t.Errorf("error present = %v, want %v", ok, tt.failure != "")
+			}
+			if _, err := time.Parse(time.RFC3339, got["finished_at"].(string)); err != nil {
+				t.Errorf("finished_at: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunRecorder(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		recorder := NewRunRecorder("customers.csv")
+		logger := slog.New(recorder.Handler(slog.NewTextHandler(io.Discard, nil)))
+		logger.Warn("domain seen with inconsistent casing", "domain", "example.com")
+		recorder.SetSummary(customerimporter.Summary{Rows: 10, Domains: 3, Customers: 8})
+
+		got := recorder.Summary(0)
+		if got.Status != "success" || got.Error != "" || got.Source != "customers.csv" || got.Rows != 10 || got.Domains != 3 {
+			t.Errorf("Summary(0) = %+v, want a successful run of 10 rows and 3 domains", got)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		recorder := NewRunRecorder("customers.csv")
+		logger := slog.New(recorder.Handler(slog.NewTextHandler(io.Discard, nil))).With("file", "customers.csv")
+		logger.Error("invalid options", "error", errors.New("bad -top"))
+		logger.Error("failed to import customer data", "file", "customers.csv", "error", errors.New("invalid email"))
+
+		got := recorder.Summary(1)
+		want := "failed to import customer data: invalid email"
+		if got.Status != "failed" || got.ExitCode != 1 || got.Error != want || got.Rows != 0 {
+			t.Errorf("Summary(1) = %+v, want a failed run with error %q", got, want)
+		}
+	})
+
+	t.Run("error without error attribute", func(t *testing.T) {
+		recorder := NewRunRecorder("customers.csv")
+		slog.New(recorder.Handler(slog.NewTextHandler(io.Discard, nil))).WithGroup("run").Error("import interrupted")
+		if got := recorder.Summary(130).Error; got != "import interrupted" {
+			t.Errorf("Summary(130).Error = %q, want %q", got, "import interrupted")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"fmt"
+	"importer/customerimporter"
+	"math"
+)
+
+// ScaleCounts min-max scales each CustomerQuantity into the range [0, upper], i.e.
+// (count - min) / (max - min) * upper, returning one value per element of data. The smallest
+// count maps to 0 and the largest to upper, so bar charts of datasets of any size share one
+// scale. When all counts are equal, including a single domain, every value is upper, as every
+// domain is then as large as the largest. upper must be positive and finite.
+func ScaleCounts(data []customerimporter.DomainData, upper float64) ([]float64, error) {
+	if !(upper > 0) || math.IsInf(upper, 0) {
+		return nil, fmt.Errorf("invalid scale upper bound %v: want a positive number", upper)
+	}
+	scaled := make([]float64, len(data))
+	if len(data) == 0 {
+		return scaled, nil
+	}
+	lowest, highest := data[0].CustomerQuantity, data[0].CustomerQuantity
+	for _, d := range data[1:] {
+		lowest = min(lowest, d.CustomerQuantity)
+		highest = max(highest, d.CustomerQuantity)
+	}
+	spread := float64(highest - lowest)
+	for i, d := range data {
+		if spread == 0 {
+			scaled[i] = upper
+			continue
+		}
+		scaled[i] = float64(d.CustomerQuantity-lowest) / spread * upper
+	}
+	return scaled, nil
+}
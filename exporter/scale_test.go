@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScaleCounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []uint64
+		upper  float64
+		want   []float64
+	}{
+		{name: "unit range", counts: []uint64{10, 20, 30}, upper: 1, want: []float64{0, 0.5, 1}},
+		{name: "percent range", counts: []uint64{5, 1, 9, 3}, upper: 100, want: []float64{50, 0, 100, 25}},
+		{name: "single domain", counts: []uint64{42}, upper: 100, want: []float64{100}},
+		{name: "all equal", counts: []uint64{7, 7, 7}, upper: 1, want: []float64{1, 1, 1}},
+		{name: "no domains", counts: nil, upper: 1, want: []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]customerimporter.DomainData, len(tt.counts))
+			for i, c := range tt.counts {
+				data[i] = customerimporter.DomainData{CustomerQuantity: c}
+			}
+			got, err := ScaleCounts(data, tt.upper)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ScaleCounts(%v, %v) = %v, want %v", tt.counts, tt.upper, got, tt.want)
+			}
+		})
+	}
+
+	for _, upper := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		if _, err := ScaleCounts(nil, upper); err == nil {
+			t.Errorf("ScaleCounts with upper bound %v succeeded, want an error", upper)
+		}
+	}
+}
+
+func TestExportScaledCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 1},
+		{Domain: "b.com", CustomerQuantity: 2},
+		{Domain: "c.com", CustomerQuantity: 4},
+	}
+
+	if err := NewCustomerExporter(path, WithScaledCounts(100)).ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,scaled_count\na.com,1,0.0000\nb.com,2,33.3333\nc.com,4,100.0000\n"
+	if string(got) != want {
+		t.Errorf("exported CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if _, err := NewCustomerExporter("", WithScaledCounts(1)).NewRecordWriter(os.Stdout); err != ErrStreamUnsupported {
+		t.Errorf("NewRecordWriter() error = %v, want %v", err, ErrStreamUnsupported)
+	}
+}
@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"importer/customerimporter"
+	"io"
+)
+
+// ErrStreamUnsupported is returned by NewRecordWriter when an option needs the whole dataset
+// before the first record can be written.
+var ErrStreamUnsupported = errors.New("option requires the full dataset and cannot be streamed")
+
+// RecordWriter writes the CSV of ExportTo one record at a time, so results can be streamed from
+// customerimporter.CustomerImporter.StreamDomainData without building a []DomainData first.
+// Records are written in the order given.
+type RecordWriter struct {
+	ex   CustomerExporter
+	csv  *csv.Writer
+	cols []column
+	row  []string
+	rows int
+}
+
+// NewRecordWriter writes the header to w and returns a RecordWriter for the data rows. It fails
+// with ErrStreamUnsupported when WithNormalizedCounts, WithPerMille, WithScaledCounts,
+// WithSortOnExport or WithTemplate is set. Call Flush once all records are written.
+func (ex CustomerExporter) NewRecordWriter(w io.Writer) (*RecordWriter, error) {
+	if ex.config.NormalizeTo > 0 || ex.config.PerMille || ex.config.ScaleTo != 0 || ex.config.SortOnExport || ex.config.Template != nil {
+		return nil, ErrStreamUnsupported
+	}
+	cols, err := ex.columns(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rw := &RecordWriter{ex: ex, csv: ex.csvWriter(w), cols: cols, row: make([]string, len(cols))}
+	for i, c := range cols {
+		rw.row[i] = c.header
+	}
+	if err := rw.csv.Write(rw.row); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// Write writes a single record. With WithSignedCounts a count above the int64 range fails with
+// customerimporter.ErrCountOverflow before anything is written for it.
+func (rw *RecordWriter) Write(d customerimporter.DomainData) error {
+	if rw.ex.config.SignedCounts {
+		checked := d
+		if limit := rw.ex.config.CountCap; limit > 0 {
+			checked.CustomerQuantity = min(d.CustomerQuantity, limit)
+		}
+		if _, err := checked.SignedQuantity(); err != nil {
+			return err
+		}
+	}
+	for i, c := range rw.cols {
+		rw.row[i] = c.value(rw.rows, d)
+	}
+	rw.rows++
+	return rw.csv.Write(rw.row)
+}
+
+// Flush writes any buffered data to the underlying writer and reports any write error.
+func (rw *RecordWriter) Flush() error {
+	rw.csv.Flush()
+	return rw.csv.Error()
+}
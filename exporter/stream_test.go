@@ -0,0 +1,110 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRecordWriter(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "a.com", CustomerQuantity: 12, SampleEmail: "john@a.com"},
+		{Domain: "b.org", CustomerQuantity: 3, SampleEmail: "jane@b.org"},
+	}
+	opts := []Option{WithCountCap(10), WithDomainID(HashFNV), WithSampleEmail()}
+
+	var want strings.Builder
+	if err := NewCustomerExporter("", opts...).ExportTo(&want, data); err != nil {
+		t.Fatal(err)
+	}
+	var got strings.Builder
+	rw, err := NewCustomerExporter("", opts...).NewRecordWriter(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range data {
+		if err := rw.Write(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("streamed CSV mismatch:\nhave: %q\nwant: %q", got.String(), want.String())
+	}
+}
+
+func TestRecordWriterErrors(t *testing.T) {
+	for _, opt := range []Option{WithNormalizedCounts(100), WithSortOnExport(customerimporter.SortByCount)} {
+		if _, err := NewCustomerExporter("", opt).NewRecordWriter(io.Discard); !errors.Is(err, ErrStreamUnsupported) {
+			t.Errorf("expected ErrStreamUnsupported, got %v", err)
+		}
+	}
+
+	rw, err := NewCustomerExporter("", WithSignedCounts()).NewRecordWriter(io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rw.Write(customerimporter.DomainData{Domain: "big.com", CustomerQuantity: math.MaxUint64})
+	if !errors.Is(err, customerimporter.ErrCountOverflow) {
+		t.Errorf("expected ErrCountOverflow, got %v", err)
+	}
+}
+
+// highCardinalityCSV returns an input in which every row has its own domain.
+func highCardinalityCSV(rows int) string {
+	var sb strings.Builder
+	sb.WriteString("first_name,last_name,email,gender,ip_address\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&sb, "John,Doe,john@domain%d.com,Male,192.168.1.1\n", i)
+	}
+	return sb.String()
+}
+
+// BenchmarkOutputSlice imports into a []DomainData and then writes it, for comparison with
+// BenchmarkOutputStream.
+func BenchmarkOutputSlice(b *testing.B) {
+	input := highCardinalityCSV(50000)
+	importer := customerimporter.NewCustomerImporter("")
+	exporter := NewCustomerExporter("")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, _, err := importer.ImportReader(context.Background(), strings.NewReader(input))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := exporter.ExportTo(io.Discard, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkOutputStream writes every record as it is produced by StreamReader.
+func BenchmarkOutputStream(b *testing.B) {
+	input := highCardinalityCSV(50000)
+	importer := customerimporter.NewCustomerImporter("")
+	exporter := NewCustomerExporter("")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rw, err := exporter.NewRecordWriter(io.Discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := importer.StreamReader(context.Background(), strings.NewReader(input), rw.Write); err != nil {
+			b.Fatal(err)
+		}
+		if err := rw.Flush(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// ExportSubnets writes the per-subnet aggregation returned by customerimporter.ImportSubnets to
+// the output file, in the given order:
+//
+//	subnet,number_of_customers
+//	10.0.0.0/24,2
+//	192.168.1.0/24,5
+//
+// Column options do not apply. Returns an error if data is nil or the file cannot be written.
+func (ex CustomerExporter) ExportSubnets(data []customerimporter.SubnetData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportSubnetsTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("subnet aggregation written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportSubnetsTo writes the same CSV as ExportSubnets to w.
+func ExportSubnetsTo(w io.Writer, data []customerimporter.SubnetData) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"subnet", "number_of_customers"}); err != nil {
+		return err
+	}
+	for _, d := range data {
+		if err := csvWriter.Write([]string{d.Subnet, strconv.FormatUint(d.CustomerQuantity, 10)}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
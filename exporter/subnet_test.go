@@ -0,0 +1,33 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportSubnets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subnets.csv")
+	data := []customerimporter.SubnetData{
+		{Subnet: "10.0.0.0/24", CustomerQuantity: 2},
+		{Subnet: "192.168.1.0/24", CustomerQuantity: 5},
+		{Subnet: "2001:db8::/64", CustomerQuantity: 1},
+	}
+
+	if err := NewCustomerExporter(path).ExportSubnets(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "subnet,number_of_customers\n10.0.0.0/24,2\n192.168.1.0/24,5\n2001:db8::/64,1\n"
+	if string(got) != want {
+		t.Errorf("subnet CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if err := NewCustomerExporter(path).ExportSubnets(nil); err == nil {
+		t.Error("expected error for nil data, got nil")
+	}
+}
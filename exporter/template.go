@@ -0,0 +1,38 @@
+package exporter
+
+import (
+	"fmt"
+	"importer/customerimporter"
+	"io"
+)
+
+const (
+	// TemplateHeader names the optional template of WithTemplate executed once before the
+	// records, with the whole []customerimporter.DomainData as its data.
+	TemplateHeader = "header"
+	// TemplateFooter names the optional template of WithTemplate executed once after the
+	// records, with the whole []customerimporter.DomainData as its data.
+	TemplateFooter = "footer"
+)
+
+// exportTemplate writes data through the configured template: the TemplateHeader template, the
+// main template once per record and the TemplateFooter template, skipping undefined ones.
+func (ex CustomerExporter) exportTemplate(data []customerimporter.DomainData, w io.Writer) error {
+	tmpl := ex.config.Template
+	if header := tmpl.Lookup(TemplateHeader); header != nil {
+		if err := header.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute header template: %w", err)
+		}
+	}
+	for _, d := range data {
+		if err := tmpl.Execute(w, d); err != nil {
+			return fmt.Errorf("failed to execute template for %q: %w", d.Domain, err)
+		}
+	}
+	if footer := tmpl.Lookup(TemplateFooter); footer != nil {
+		if err := footer.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute footer template: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"errors"
+	"importer/customerimporter"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestWithTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("insert").Parse(
+		`INSERT INTO domains (domain, customers) VALUES ('{{.Domain}}', {{.CustomerQuantity}});` + "\n" +
+			`{{define "header"}}BEGIN; -- {{len .}} domains` + "\n" + `{{end}}` +
+			`{{define "footer"}}COMMIT;` + "\n" + `{{end}}`))
+	data := []customerimporter.DomainData{
+		{Domain: "zeta.com", CustomerQuantity: 3},
+		{Domain: "alpha.com", CustomerQuantity: 12},
+	}
+	path := filepath.Join(t.TempDir(), "out.sql")
+
+	ex := NewCustomerExporter(path, WithTemplate(tmpl), WithSortOnExport(customerimporter.SortByDomain))
+	if err := ex.ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "BEGIN; -- 2 domains\n" +
+		"INSERT INTO domains (domain, customers) VALUES ('alpha.com', 12);\n" +
+		"INSERT INTO domains (domain, customers) VALUES ('zeta.com', 3);\n" +
+		"COMMIT;\n"
+	if string(got) != want {
+		t.Errorf("templated output mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+
+	if _, err := ex.NewRecordWriter(io.Discard); !errors.Is(err, ErrStreamUnsupported) {
+		t.Errorf("expected ErrStreamUnsupported, got %v", err)
+	}
+}
+
+func TestWithTemplateWithoutHeaderAndError(t *testing.T) {
+	var sb strings.Builder
+	tmpl := template.Must(template.New("row").Parse("<tr><td>{{.Domain}}</td></tr>\n"))
+	data := []customerimporter.DomainData{{Domain: "a.com", CustomerQuantity: 1}}
+	if err := NewCustomerExporter("", WithTemplate(tmpl)).ExportTo(&sb, data); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<tr><td>a.com</td></tr>\n"; sb.String() != want {
+		t.Errorf("templated output = %q, want %q", sb.String(), want)
+	}
+
+	broken := template.Must(template.New("broken").Parse("{{.Missing}}"))
+	err := NewCustomerExporter("", WithTemplate(broken)).ExportTo(io.Discard, data)
+	if err == nil || !strings.Contains(err.Error(), `template for "a.com"`) {
+		t.Errorf("expected template execution error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// TreeSelfKey holds the count of a domain within the JSON tree when subdomains of it are counted
+// as well, as "@" names the zone apex in DNS zone files.
+const TreeSelfKey = "@"
+
+// treeNode is a domain label within the tree built by ExportJSONTreeTo.
+type treeNode struct {
+	count    uint64
+	counted  bool
+	children map[string]*treeNode
+}
+
+// ExportJSONTree writes data to the output file as a JSON tree keyed by the domain labels in
+// reverse order, for hierarchical visualizations such as treemaps:
+//
+//	{"com":{"example":{"@":42,"mail":5},"other":7},"org":{"example":3}}
+//
+// A domain's count is a number at the end of its path, or under TreeSelfKey when subdomains
+// of it are counted too, as for example.com above next to mail.example.com. Domains appearing
+// more than once in data are summed. Keys are sorted and column options do not apply. Returns
+// an error if data is nil or the file cannot be written.
+func (ex CustomerExporter) ExportJSONTree(data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ExportJSONTreeTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("domain tree written successfully", "file", ex.outputPath, "records", len(data))
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportJSONTreeTo writes the same JSON as ExportJSONTree to w, followed by a newline.
+func ExportJSONTreeTo(w io.Writer, data []customerimporter.DomainData) error {
+	root := &treeNode{children: make(map[string]*treeNode)}
+	for _, d := range data {
+		node := root
+		labels := strings.Split(strings.TrimSuffix(d.Domain, "."), ".")
+		for i := len(labels) - 1; i >= 0; i-- {
+			child, ok := node.children[labels[i]]
+			if !ok {
+				child = &treeNode{}
+				if node.children == nil {
+					node.children = make(map[string]*treeNode)
+				}
+				node.children[labels[i]] = child
+			}
+			node = child
+		}
+		node.count += d.CustomerQuantity
+		node.counted = true
+	}
+	// The root is always an object, {} without domains
+	return json.NewEncoder(w).Encode(root.object())
+}
+
+// value returns the JSON value of n: its count when it has no children and its object otherwise.
+func (n *treeNode) value() any {
+	if len(n.children) == 0 {
+		return n.count
+	}
+	return n.object()
+}
+
+// object returns the children of n by label, which encoding/json writes with sorted keys, plus
+// its count under TreeSelfKey when the domain itself was counted.
+func (n *treeNode) object() map[string]any {
+	object := make(map[string]any, len(n.children)+1)
+	for label, child := range n.children {
+		object[label] = child.value()
+	}
+	if n.counted {
+		object[TreeSelfKey] = n.count
+	}
+	return object
+}
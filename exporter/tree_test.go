@@ -0,0 +1,82 @@
+package exporter
+
+import (
+	"encoding/json"
+	"importer/customerimporter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportJSONTree(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "example.com", CustomerQuantity: 42},
+		{Domain: "mail.example.com", CustomerQuantity: 5},
+		{Domain: "eu.mail.example.com", CustomerQuantity: 2},
+		{Domain: "other.com", CustomerQuantity: 7},
+		{Domain: "example.org", CustomerQuantity: 3},
+	}
+	path := filepath.Join(t.TempDir(), "tree.json")
+	if err := NewCustomerExporter(path).ExportJSONTree(data); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(content, &tree); err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(path ...string) any {
+		var node any = tree
+		for _, label := range path {
+			object, ok := node.(map[string]any)
+			if !ok {
+				t.Fatalf("no object at %q in %s", label, content)
+			}
+			node = object[label]
+		}
+		return node
+	}
+	tests := []struct {
+		path []string
+		want float64
+	}{
+		{path: []string{"com", "example", "mail", "eu"}, want: 2},
+		{path: []string{"com", "example", "mail", TreeSelfKey}, want: 5},
+		{path: []string{"com", "example", TreeSelfKey}, want: 42},
+		{path: []string{"com", "other"}, want: 7},
+		{path: []string{"org", "example"}, want: 3},
+	}
+	for _, tt := range tests {
+		if got := lookup(tt.path...); got != tt.want {
+			t.Errorf("tree at %s = %v, want %v", strings.Join(tt.path, "."), got, tt.want)
+		}
+	}
+}
+
+func TestExportJSONTreeTo(t *testing.T) {
+	var b strings.Builder
+	data := []customerimporter.DomainData{
+		{Domain: "b.io", CustomerQuantity: 1},
+		{Domain: "a.io", CustomerQuantity: 2},
+		{Domain: "a.io", CustomerQuantity: 3},
+	}
+	if err := ExportJSONTreeTo(&b, data); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"io":{"a":5,"b":1}}` + "\n"; b.String() != want {
+		t.Errorf("ExportJSONTreeTo() = %q, want %q", b.String(), want)
+	}
+
+	b.Reset()
+	if err := ExportJSONTreeTo(&b, []customerimporter.DomainData{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{}\n"; b.String() != want {
+		t.Errorf("ExportJSONTreeTo() of no domains = %q, want %q", b.String(), want)
+	}
+}
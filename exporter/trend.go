@@ -0,0 +1,188 @@
+package exporter
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// Trend classifies how a domain's count changed against a baseline.
+type Trend string
+
+const (
+	// TrendUp marks a domain whose count grew.
+	TrendUp Trend = "up"
+	// TrendDown marks a domain whose count shrank but is not zero.
+	TrendDown Trend = "down"
+	// TrendFlat marks a domain whose count is unchanged.
+	TrendFlat Trend = "flat"
+	// TrendNew marks a domain missing from the baseline.
+	TrendNew Trend = "new"
+	// TrendGone marks a baseline domain missing from the current data.
+	TrendGone Trend = "gone"
+)
+
+// TrendRecord compares the count of one domain with its baseline count.
+type TrendRecord struct {
+	Domain   string
+	Current  uint64
+	Previous uint64
+	Trend    Trend
+	// PercentChange is the change relative to Previous, e.g. 25 for 8 -> 10 and -100 for a gone
+	// domain. It is NaN when Previous is zero, such as for TrendNew.
+	PercentChange float64
+}
+
+// LoadBaseline reads the domain counts of a previous export, such as one written by Export, from
+// the CSV file at path. The columns are located by their "domain" and "number_of_customers"
+// headers, so exports with extra columns are accepted.
+func LoadBaseline(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ReadBaseline(file)
+}
+
+// ReadBaseline is like LoadBaseline but reads the CSV from r.
+func ReadBaseline(r io.Reader) (map[string]uint64, error) {
+	records := csv.NewReader(r)
+	header, err := records.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline header: %w", err)
+	}
+	domainColumn := slices.Index(header, "domain")
+	countColumn := slices.Index(header, "number_of_customers")
+	if domainColumn < 0 || countColumn < 0 {
+		return nil, fmt.Errorf("invalid baseline header %q: want domain and number_of_customers columns", header)
+	}
+
+	baseline := make(map[string]uint64)
+	for {
+		record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			return baseline, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read baseline: %w", err)
+		}
+		count, err := strconv.ParseUint(record[countColumn], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid baseline count for %q: %w", record[domainColumn], err)
+		}
+		baseline[record[domainColumn]] = count
+	}
+}
+
+// CompareBaseline returns a TrendRecord for every domain of data or baseline, sorted by domain,
+// so domains that disappeared since the baseline are listed as TrendGone.
+func CompareBaseline(baseline map[string]uint64, data []customerimporter.DomainData) []TrendRecord {
+	trends := make([]TrendRecord, 0, max(len(data), len(baseline)))
+	current := make(map[string]struct{}, len(data))
+	for _, d := range data {
+		current[d.Domain] = struct{}{}
+		previous, found := baseline[d.Domain]
+		trends = append(trends, newTrendRecord(d.Domain, d.CustomerQuantity, previous, found))
+	}
+	for domain, previous := range baseline {
+		if _, found := current[domain]; !found {
+			trends = append(trends, TrendRecord{Domain: domain, Previous: previous, Trend: TrendGone, PercentChange: -100})
+		}
+	}
+	slices.SortFunc(trends, func(l, r TrendRecord) int {
+		return cmp.Compare(l.Domain, r.Domain)
+	})
+	return trends
+}
+
+// newTrendRecord classifies a domain present in the current data.
+func newTrendRecord(domain string, current, previous uint64, inBaseline bool) TrendRecord {
+	t := TrendRecord{Domain: domain, Current: current, Previous: previous, PercentChange: math.NaN()}
+	switch {
+	case !inBaseline:
+		t.Trend = TrendNew
+		return t
+	case current == previous:
+		t.Trend, t.PercentChange = TrendFlat, 0
+		return t
+	case current > previous:
+		t.Trend = TrendUp
+	default:
+		t.Trend = TrendDown
+	}
+	// Growth from zero has no relative change
+	if previous > 0 {
+		t.PercentChange = (float64(current) - float64(previous)) / float64(previous) * 100
+	}
+	return t
+}
+
+// ExportTrend writes the comparison of data with baseline, as returned by CompareBaseline, to the
+// output file for dashboards:
+//
+//	domain,number_of_customers,previous,trend,percent_change
+//	acme.com,10,8,up,25.0
+//	gone.com,0,3,gone,-100.0
+//	new.com,4,0,new,
+//
+// percent_change is rounded to one decimal and left empty when there is no previous count.
+// Column options do not apply. Returns an error if data or baseline is nil or the file cannot be
+// written.
+func (ex CustomerExporter) ExportTrend(baseline map[string]uint64, data []customerimporter.DomainData) error {
+	if data == nil || baseline == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+
+	trends := CompareBaseline(baseline, data)
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return writeTrends(w, trends)
+	}); err != nil {
+		return err
+	}
+	slog.Info("trend export written successfully", "file", ex.outputPath)
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(trends)}})
+}
+
+// ExportTrendTo writes the same CSV as ExportTrend to w.
+func ExportTrendTo(w io.Writer, baseline map[string]uint64, data []customerimporter.DomainData) error {
+	return writeTrends(w, CompareBaseline(baseline, data))
+}
+
+func writeTrends(w io.Writer, trends []TrendRecord) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"domain", "number_of_customers", "previous", "trend", "percent_change"}); err != nil {
+		return err
+	}
+	for _, t := range trends {
+		change := ""
+		if !math.IsNaN(t.PercentChange) {
+			change = strconv.FormatFloat(t.PercentChange, 'f', 1, 64)
+		}
+		record := []string{
+			t.Domain,
+			strconv.FormatUint(t.Current, 10),
+			strconv.FormatUint(t.Previous, 10),
+			string(t.Trend),
+			change,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
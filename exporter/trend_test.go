@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"importer/customerimporter"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareBaseline(t *testing.T) {
+	baseline := map[string]uint64{
+		"up.com":   8,
+		"down.com": 10,
+		"flat.com": 5,
+		"gone.com": 3,
+		"zero.com": 0,
+	}
+	data := []customerimporter.DomainData{
+		{Domain: "down.com", CustomerQuantity: 4},
+		{Domain: "flat.com", CustomerQuantity: 5},
+		{Domain: "new.com", CustomerQuantity: 2},
+		{Domain: "up.com", CustomerQuantity: 10},
+		{Domain: "zero.com", CustomerQuantity: 1},
+	}
+
+	want := []TrendRecord{
+		{Domain: "down.com", Current: 4, Previous: 10, Trend: TrendDown, PercentChange: -60},
+		{Domain: "flat.com", Current: 5, Previous: 5, Trend: TrendFlat, PercentChange: 0},
+		{Domain: "gone.com", Current: 0, Previous: 3, Trend: TrendGone, PercentChange: -100},
+		{Domain: "new.com", Current: 2, Previous: 0, Trend: TrendNew, PercentChange: math.NaN()},
+		{Domain: "up.com", Current: 10, Previous: 8, Trend: TrendUp, PercentChange: 25},
+		{Domain: "zero.com", Current: 1, Previous: 0, Trend: TrendUp, PercentChange: math.NaN()},
+	}
+	got := CompareBaseline(baseline, data)
+	if len(got) != len(want) {
+		t.Fatalf("CompareBaseline() returned %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		sameChange := g.PercentChange == w.PercentChange || math.IsNaN(g.PercentChange) && math.IsNaN(w.PercentChange)
+		if g.Domain != w.Domain || g.Current != w.Current || g.Previous != w.Previous || g.Trend != w.Trend || !sameChange {
+			t.Errorf("record %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestExportTrend(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "previous.csv")
+	previous := []customerimporter.DomainData{
+		{Domain: "acme.com", CustomerQuantity: 8},
+		{Domain: "gone.com", CustomerQuantity: 3},
+	}
+	if err := NewCustomerExporter(baselinePath, WithSampleEmail()).ExportData(previous); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(baselinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "trend.csv")
+	data := []customerimporter.DomainData{
+		{Domain: "acme.com", CustomerQuantity: 10},
+		{Domain: "new.com", CustomerQuantity: 4},
+	}
+	if err := NewCustomerExporter(path).ExportTrend(baseline, data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,number_of_customers,previous,trend,percent_change\n" +
+		"acme.com,10,8,up,25.0\n" +
+		"gone.com,0,3,gone,-100.0\n" +
+		"new.com,4,0,new,\n"
+	if string(got) != want {
+		t.Errorf("trend CSV mismatch:\nhave: %q\nwant: %q", got, want)
+	}
+}
+
+func TestReadBaselineInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "missing count column", content: "domain,customers\nacme.com,3\n"},
+		{name: "invalid count", content: "domain,number_of_customers\nacme.com,many\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadBaseline(strings.NewReader(tt.content)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
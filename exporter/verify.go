@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// ErrVerificationFailed is returned under WithVerifyAfterWrite when an output file read back
+// does not hold what was exported.
+var ErrVerificationFailed = errors.New("output verification failed")
+
+// writeVerified is writeOutput for the domain CSV holding records data records. Under
+// VerifyAfterWrite the bytes passed to the file are hashed on the way and the closed file is
+// read back and checked with verifyOutput.
+func (ex CustomerExporter) writeVerified(records int, write func(w io.Writer) error) error {
+	if !ex.config.VerifyAfterWrite {
+		return ex.writeOutput(write)
+	}
+	hash := sha256.New()
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return write(io.MultiWriter(w, hash))
+	}); err != nil {
+		return err
+	}
+	return ex.verifyOutput(records, hash.Sum(nil))
+}
+
+// verifyOutput re-reads the output file and compares its SHA-256 with sum and, unless a
+// template replaced the CSV, its record count with the header plus records.
+func (ex CustomerExporter) verifyOutput(records int, sum []byte) error {
+	file, err := os.Open(ex.outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read back output file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hash := sha256.New()
+	input := io.TeeReader(file, hash)
+	if ex.config.Template == nil {
+		reader := csv.NewReader(input)
+		reader.ReuseRecord = true
+		read := 0
+		for {
+			_, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %s does not parse as CSV: %v", ErrVerificationFailed, ex.outputPath, err)
+			}
+			read++
+		}
+		if want := records + 1; read != want {
+			return fmt.Errorf("%w: %s holds %d records, want %d", ErrVerificationFailed, ex.outputPath, read, want)
+		}
+	}
+	// Whatever the CSV reader left unread still has to be hashed
+	if _, err := io.Copy(io.Discard, input); err != nil {
+		return fmt.Errorf("failed to read back output file: %w", err)
+	}
+	if !bytes.Equal(hash.Sum(nil), sum) {
+		return fmt.Errorf("%w: %s checksum does not match the exported content", ErrVerificationFailed, ex.outputPath)
+	}
+	slog.Info("export verified", "file", ex.outputPath, "records", records)
+	return nil
+}
@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"importer/customerimporter"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+// corruptingWriter reports every write as complete while altering what reaches w.
+type corruptingWriter struct {
+	w       io.Writer
+	corrupt func(p []byte) []byte
+}
+
+func (c corruptingWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(c.corrupt(bytes.Clone(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func TestWithVerifyAfterWrite(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "alpha.com", CustomerQuantity: 12},
+		{Domain: "beta.org", CustomerQuantity: 4},
+	}
+	tests := []struct {
+		name    string
+		corrupt func(p []byte) []byte
+		wantErr bool
+	}{
+		{name: "intact", corrupt: func(p []byte) []byte { return p }},
+		{name: "flipped byte", corrupt: func(p []byte) []byte {
+			return bytes.ReplaceAll(p, []byte("4"), []byte("5"))
+		}, wantErr: true},
+		{name: "truncated", corrupt: func(p []byte) []byte {
+			return p[:bytes.IndexByte(p, '\n')+1]
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.csv")
+			ex := NewCustomerExporter(path, WithVerifyAfterWrite(), withOutputWrapper(func(w io.Writer) io.Writer {
+				return corruptingWriter{w: w, corrupt: tt.corrupt}
+			}))
+			err := ex.ExportData(data)
+			if tt.wantErr != errors.Is(err, ErrVerificationFailed) {
+				t.Fatalf("ExportData() error = %v, want verification failure %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestWithVerifyAfterWriteChunks(t *testing.T) {
+	data := []customerimporter.DomainData{
+		{Domain: "alpha.com", CustomerQuantity: 12},
+		{Domain: "beta.org", CustomerQuantity: 4},
+		{Domain: "gamma.net", CustomerQuantity: 1},
+	}
+	path := filepath.Join(t.TempDir(), "out.csv")
+	ex := NewCustomerExporter(path, WithVerifyAfterWrite(), WithChunkSize(2), WithSkipUnchanged())
+	if err := ex.ExportData(data); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,149 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"importer/customerimporter"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// XLSXSheetName is the name of the single worksheet written by ExportXLSX.
+const XLSXSheetName = "Domains"
+
+// MaxXLSXRecords is the largest number of records ExportXLSX writes: Excel sheets hold at most
+// 1,048,576 rows, one of which is the header.
+const MaxXLSXRecords = 1<<20 - 1
+
+// xlsxParts are the fixed parts of the workbook written by ExportXLSXTo, by path inside the
+// archive. styles.xml holds two cell formats: 0 is the default and 1 uses a bold font.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + XLSXSheetName + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// ExportXLSX writes data to the output file as an Excel workbook, so it opens in a spreadsheet
+// application without a CSV import dialog. The single sheet, XLSXSheetName, has a bold header
+// row of "domain" and "number_of_customers" followed by one row per domain in order after
+// applying WithSortOnExport; other column options do not apply. The file is created like the
+// CSV, e.g. refusing to overwrite it under WithNoClobber. Returns an error if data is nil, has
+// more than MaxXLSXRecords records, which Excel would truncate, or the file cannot be written.
+//
+// The workbook is assembled from its OOXML parts with archive/zip and encoding/xml rather than
+// a spreadsheet library: a single sheet of plain cells needs little of the format, and the
+// module keeps to the standard library.
+func (ex CustomerExporter) ExportXLSX(data []customerimporter.DomainData) error {
+	if data == nil {
+		return fmt.Errorf("provided data is empty (nil)")
+	}
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+	if err := checkXLSXRecords(data); err != nil {
+		return err
+	}
+
+	if err := ex.writeOutput(func(w io.Writer) error {
+		return ex.ExportXLSXTo(w, data)
+	}); err != nil {
+		return err
+	}
+	slog.Info("workbook written successfully", "file", ex.outputPath, "records", len(data))
+	return ex.writeManifest([]OutputFile{{Path: ex.outputPath, Records: len(data)}})
+}
+
+// ExportXLSXTo writes the same workbook as ExportXLSX to w. Spreadsheet applications hold
+// numbers as float64, so counts above 2^53 are displayed rounded.
+func (ex CustomerExporter) ExportXLSXTo(w io.Writer, data []customerimporter.DomainData) error {
+	if err := ex.checkRealDomains(); err != nil {
+		return err
+	}
+	if err := checkXLSXRecords(data); err != nil {
+		return err
+	}
+	data, err := ex.ordered(data)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		partWriter, err := archive.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to write workbook: %w", err)
+		}
+		if _, err := io.WriteString(partWriter, part.content); err != nil {
+			return fmt.Errorf("failed to write workbook: %w", err)
+		}
+	}
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	if err := writeXLSXSheet(sheet, data); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return nil
+}
+
+// checkXLSXRecords fails when data does not fit in a worksheet.
+func checkXLSXRecords(data []customerimporter.DomainData) error {
+	if len(data) > MaxXLSXRecords {
+		return fmt.Errorf("%d records exceed the %d rows of a workbook sheet; use CSV output instead", len(data), MaxXLSXRecords)
+	}
+	return nil
+}
+
+// writeXLSXSheet writes the worksheet XML: the bold header in row 1 and one row per record,
+// domains as inline strings and counts as numbers.
+func writeXLSXSheet(w io.Writer, data []customerimporter.DomainData) error {
+	buf := bufio.NewWriter(w)
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	buf.WriteString(`<row r="1"><c r="A1" t="inlineStr" s="1"><is><t>domain</t></is></c>`)
+	buf.WriteString(`<c r="B1" t="inlineStr" s="1"><is><t>number_of_customers</t></is></c></row>`)
+	for i, d := range data {
+		row := strconv.Itoa(i + 2)
+		buf.WriteString(`<row r="` + row + `"><c r="A` + row + `" t="inlineStr"><is><t>`)
+		if err := xml.EscapeText(buf, []byte(d.Domain)); err != nil {
+			return err
+		}
+		buf.WriteString(`</t></is></c><c r="B` + row + `"><v>`)
+		buf.WriteString(strconv.FormatUint(d.CustomerQuantity, 10))
+		buf.WriteString(`</v></c></row>`)
+	}
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.Flush()
+}
@@ -0,0 +1,126 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"importer/customerimporter"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xlsxWorksheet is the part of a worksheet read back by the tests.
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Style  string `xml:"s,attr"`
+			Inline string `xml:"is>t"`
+			Value  string `xml:"v"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXPart returns the content of the named part of the workbook at path.
+func readXLSXPart(t *testing.T, path, name string) []byte {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	part, err := archive.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer part.Close()
+	content, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestExportXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	data := []customerimporter.DomainData{
+		{Domain: "beta.org", CustomerQuantity: 4},
+		{Domain: "alpha.com", CustomerQuantity: 12},
+		{Domain: "a&b.net", CustomerQuantity: 1},
+	}
+	ex := NewCustomerExporter(path, WithSortOnExport(customerimporter.SortByCount))
+	if err := ex.ExportXLSX(data); err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet xlsxWorksheet
+	if err := xml.Unmarshal(readXLSXPart(t, path, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, row := range sheet.Rows {
+		var values []string
+		for _, c := range row.Cells {
+			values = append(values, c.Ref+"="+c.Inline+c.Value)
+		}
+		got = append(got, values)
+	}
+	want := [][]string{
+		{"A1=domain", "B1=number_of_customers"},
+		{"A2=alpha.com", "B2=12"},
+		{"A3=beta.org", "B3=4"},
+		{"A4=a&b.net", "B4=1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sheet rows = %v, want %v", got, want)
+	}
+
+	for _, c := range sheet.Rows[0].Cells {
+		if c.Style != "1" {
+			t.Errorf("header cell %s uses style %q, want the bold style 1", c.Ref, c.Style)
+		}
+	}
+	if styles := string(readXLSXPart(t, path, "xl/styles.xml")); !strings.Contains(styles, "<font><b/>") {
+		t.Errorf("styles lack a bold font: %s", styles)
+	}
+	if workbook := string(readXLSXPart(t, path, "xl/workbook.xml")); !strings.Contains(workbook, `name="`+XLSXSheetName+`"`) {
+		t.Errorf("workbook lacks sheet %q: %s", XLSXSheetName, workbook)
+	}
+}
+
+func TestExportXLSXNoClobber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := NewCustomerExporter(path, WithNoClobber()).ExportXLSX([]customerimporter.DomainData{})
+	if !errors.Is(err, ErrOutputExists) {
+		t.Fatalf("ExportXLSX() error = %v, want %v", err, ErrOutputExists)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "keep" {
+		t.Errorf("existing file was modified: %q", content)
+	}
+}
+
+func TestExportXLSXTooManyRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	data := make([]customerimporter.DomainData, MaxXLSXRecords+1)
+	if err := NewCustomerExporter(path).ExportXLSX(data); err == nil {
+		t.Fatal("ExportXLSX() error = nil, want row limit error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("output was created: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewCustomerExporter("").ExportXLSXTo(&buf, data); err == nil {
+		t.Fatal("ExportXLSXTo() error = nil, want row limit error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("ExportXLSXTo() wrote %d bytes", buf.Len())
+	}
+}
@@ -0,0 +1,148 @@
+// Package config fills command-line flags that were not given explicitly from IMPORTER_*
+// environment variables and a key=value config file, so teams can store settings once.
+//
+// Each flag is resolved with the precedence explicit flag > environment variable > config file
+// > flag default. The environment variable of a flag is its name upper-cased with dashes
+// replaced by underscores behind the IMPORTER_ prefix, e.g. IMPORTER_STRIP_WWW for -strip-www.
+//
+// A config file holds one flag per line, keyed by the flag name without the leading dash:
+//
+//	# shared import settings
+//	strip-www=true
+//	on-error=skip
+//	email-columns=2,5
+//
+// Blank lines and lines starting with "#" are ignored; keys and values are trimmed of
+// surrounding whitespace, and a later line overrides an earlier one for the same key.
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvPrefix is prepended to the environment variable name of every flag.
+const EnvPrefix = "IMPORTER_"
+
+// EnvName returns the environment variable consulted for the flag name, e.g.
+// "IMPORTER_STRIP_WWW" for "strip-www".
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Parse reads key=value lines from r. It returns an error naming the line for lines without
+// "=" or with an empty key.
+func Parse(r io.Reader) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected key=value, got %q", line, text)
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ReadFile parses the config file at path, see Parse.
+func ReadFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	values, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Apply sets every flag of fs that was not set on the command line from its environment
+// variable in environ ("KEY=value" entries as returned by os.Environ) or, failing that, from the
+// file values. It must be called after fs.Parse. Keys in file that name no flag and values the
+// flag rejects are errors; IMPORTER_* variables that name no flag are ignored, since the
+// environment is shared with other programs.
+func Apply(fs *flag.FlagSet, file map[string]string, environ []string) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for key := range file {
+		if fs.Lookup(key) == nil {
+			return fmt.Errorf("config file: unknown flag %q", key)
+		}
+	}
+
+	env := environMap(environ)
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] {
+			return
+		}
+		if value, ok := env[EnvName(f.Name)]; ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("environment variable %s: %w", EnvName(f.Name), setErr)
+			}
+			return
+		}
+		if value, ok := file[f.Name]; ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("config file key %s: %w", f.Name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// Load resolves the config file path from the flag named pathFlag itself (explicit flag, then
+// its environment variable), reads that file if one is named and calls Apply. The path flag must
+// not appear in the config file.
+func Load(fs *flag.FlagSet, pathFlag string, environ []string) error {
+	path := fs.Lookup(pathFlag).Value.String()
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == pathFlag
+	})
+	if value, ok := environMap(environ)[EnvName(pathFlag)]; ok && !explicit {
+		path = value
+	}
+
+	var file map[string]string
+	if path != "" {
+		values, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, ok := values[pathFlag]; ok {
+			return fmt.Errorf("config file %s: %q cannot be set in a config file", path, pathFlag)
+		}
+		file = values
+	}
+	return Apply(fs, file, environ)
+}
+
+func environMap(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(key, EnvPrefix) {
+			env[key] = value
+		}
+	}
+	return env
+}
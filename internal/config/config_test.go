@@ -0,0 +1,210 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newFlagSet mirrors a few CLI flags with distinct types and defaults.
+func newFlagSet() (*flag.FlagSet, *string, *bool, *int, *string) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	onError := fs.String("on-error", "abort", "")
+	stripWWW := fs.Bool("strip-www", false, "")
+	top := fs.Int("top", 0, "")
+	configPath := fs.String("config", "", "")
+	return fs, onError, stripWWW, top, configPath
+}
+
+func TestEnvName(t *testing.T) {
+	for name, want := range map[string]string{
+		"strip-www":          "IMPORTER_STRIP_WWW",
+		"top":                "IMPORTER_TOP",
+		"customer-id-column": "IMPORTER_CUSTOMER_ID_COLUMN",
+	} {
+		if got := EnvName(name); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := "# shared settings\n\n  on-error = skip \nemail-columns=2,5\nemail-regex=a=(.+)\ntop=1\ntop=3\n"
+	got, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"on-error": "skip", "email-columns": "2,5", "email-regex": "a=(.+)", "top": "3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+
+	for _, input := range []string{"strip-www\n", "top=1\n=2\n"} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("Parse(%q) returned no error", input)
+		}
+	}
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	file := map[string]string{"on-error": "skip", "strip-www": "true", "top": "5"}
+
+	tests := []struct {
+		name         string
+		args         []string
+		file         map[string]string
+		environ      []string
+		wantOnError  string
+		wantStripWWW bool
+		wantTop      int
+	}{
+		{
+			name:        "defaults",
+			wantOnError: "abort",
+		},
+		{
+			name:         "config file over default",
+			file:         file,
+			wantOnError:  "skip",
+			wantStripWWW: true,
+			wantTop:      5,
+		},
+		{
+			name:         "environment over config file",
+			file:         file,
+			environ:      []string{"IMPORTER_TOP=7", "IMPORTER_ON_ERROR=abort", "PATH=/usr/bin"},
+			wantOnError:  "abort",
+			wantStripWWW: true,
+			wantTop:      7,
+		},
+		{
+			name:         "flag over environment and config file",
+			args:         []string{"-top=9", "-strip-www=false"},
+			file:         file,
+			environ:      []string{"IMPORTER_TOP=7", "IMPORTER_STRIP_WWW=true"},
+			wantOnError:  "skip",
+			wantStripWWW: false,
+			wantTop:      9,
+		},
+		{
+			name:        "flag set to its default still wins",
+			args:        []string{"-on-error=abort"},
+			environ:     []string{"IMPORTER_ON_ERROR=skip"},
+			wantOnError: "abort",
+		},
+		{
+			name:        "unknown environment variables are ignored",
+			environ:     []string{"IMPORTER_UNKNOWN=1", "TOP=3"},
+			wantOnError: "abort",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, onError, stripWWW, top, _ := newFlagSet()
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := Apply(fs, tt.file, tt.environ); err != nil {
+				t.Fatalf("Apply() error: %v", err)
+			}
+			if *onError != tt.wantOnError || *stripWWW != tt.wantStripWWW || *top != tt.wantTop {
+				t.Errorf("on-error=%q strip-www=%v top=%d, want %q %v %d",
+					*onError, *stripWWW, *top, tt.wantOnError, tt.wantStripWWW, tt.wantTop)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    map[string]string
+		environ []string
+		want    string
+	}{
+		{name: "unknown config key", file: map[string]string{"tpo": "3"}, want: `unknown flag "tpo"`},
+		{name: "invalid config value", file: map[string]string{"top": "many"}, want: "config file key top"},
+		{name: "invalid environment value", environ: []string{"IMPORTER_STRIP_WWW=maybe"}, want: "IMPORTER_STRIP_WWW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, _, _, _, _ := newFlagSet()
+			if err := fs.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			err := Apply(fs, tt.file, tt.environ)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Apply() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	flagFile := filepath.Join(dir, "flag.conf")
+	envFile := filepath.Join(dir, "env.conf")
+	if err := os.WriteFile(flagFile, []byte("top=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(envFile, []byte("top=2\nstrip-www=true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("path from environment", func(t *testing.T) {
+		fs, _, stripWWW, top, _ := newFlagSet()
+		if err := fs.Parse(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := Load(fs, "config", []string{"IMPORTER_CONFIG=" + envFile}); err != nil {
+			t.Fatal(err)
+		}
+		if *top != 2 || !*stripWWW {
+			t.Errorf("top=%d strip-www=%v, want 2 true", *top, *stripWWW)
+		}
+	})
+
+	t.Run("path flag over environment", func(t *testing.T) {
+		fs, _, stripWWW, top, configPath := newFlagSet()
+		if err := fs.Parse([]string{"-config=" + flagFile}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Load(fs, "config", []string{"IMPORTER_CONFIG=" + envFile}); err != nil {
+			t.Fatal(err)
+		}
+		if *top != 1 || *stripWWW || *configPath != flagFile {
+			t.Errorf("top=%d strip-www=%v config=%q, want 1 false %q", *top, *stripWWW, *configPath, flagFile)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		fs, _, _, _, _ := newFlagSet()
+		if err := fs.Parse([]string{"-config=" + filepath.Join(dir, "missing.conf")}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Load(fs, "config", nil); err == nil {
+			t.Error("Load() returned no error for a missing config file")
+		}
+	})
+
+	t.Run("nested config path", func(t *testing.T) {
+		nested := filepath.Join(dir, "nested.conf")
+		if err := os.WriteFile(nested, []byte("config="+flagFile+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fs, _, _, _, _ := newFlagSet()
+		if err := fs.Parse([]string{"-config=" + nested}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Load(fs, "config", nil); err == nil {
+			t.Error("Load() returned no error for a config file setting the config path")
+		}
+	})
+}
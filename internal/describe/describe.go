@@ -0,0 +1,92 @@
+// Package describe renders configuration structs as key=value lines for diagnostics
+// such as the CLI's -explain output.
+//
+// Every exported field of the struct becomes one line, in declaration order, keyed by the
+// snake_case field name behind an optional prefix:
+//
+//	importer.strip_www_prefix=true
+//	importer.validate_domain_labels=false
+//
+// Function fields are rendered as "set" or "unset", nil values as "none", values
+// implementing fmt.Stringer through their String method, other pointers by the value they
+// point to and other interface values by their dynamic type.
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Lines returns one "prefix.key=value" line per exported field of cfg, which must be a struct
+// or a pointer to one. An empty prefix omits the leading "prefix.".
+func Lines(prefix string, cfg any) []string {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+
+	lines := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := snakeCase(field.Name)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		lines = append(lines, key+"="+formatValue(v.Field(i)))
+	}
+	return lines
+}
+
+// String joins Lines with newlines.
+func String(prefix string, cfg any) string {
+	return strings.Join(Lines(prefix, cfg), "\n")
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Func:
+		if v.IsNil() {
+			return "unset"
+		}
+		return "set"
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return "none"
+		}
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if v.Kind() == reflect.Pointer {
+		return formatValue(v.Elem())
+	}
+	if v.Kind() == reflect.Interface {
+		// Implementations such as sinks or publishers are identified by their type.
+		return fmt.Sprintf("%T", v.Interface())
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// snakeCase converts a Go identifier such as "StripWWWPrefix" to "strip_www_prefix".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (prevLower || nextLower) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package describe
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "SkipUnchanged", want: "skip_unchanged"},
+		{name: "StripWWWPrefix", want: "strip_www_prefix"},
+		{name: "DomainID", want: "domain_id"},
+		{name: "ValidateDomainLabels", want: "validate_domain_labels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snakeCase(tt.name); got != tt.want {
+				t.Errorf("snakeCase(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// nopWriter is a non-Stringer interface implementation.
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestLines(t *testing.T) {
+	column := 0
+	cfg := struct {
+		Enabled   bool
+		Columns   []int
+		Pattern   *regexp.Regexp
+		Validator func(string) error
+		Output    interface{ Write([]byte) (int, error) }
+		Column    *int
+		Missing   *int
+		hidden    int
+	}{
+		Enabled:   true,
+		Pattern:   regexp.MustCompile(`^a+$`),
+		Validator: func(string) error { return nil },
+		Output:    nopWriter{},
+		Column:    &column,
+		hidden:    1,
+	}
+
+	got := strings.Join(Lines("test", &cfg), "\n")
+	want := strings.Join([]string{
+		"test.enabled=true",
+		"test.columns=none",
+		"test.pattern=^a+$",
+		"test.validator=set",
+		"test.output=describe.nopWriter",
+		"test.column=0",
+		"test.missing=none",
+	}, "\n")
+	if got != want {
+		t.Errorf("Lines mismatch:\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
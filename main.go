@@ -18,44 +18,397 @@
 //	# Enable verbose logging for detailed progress
 //	go run main.go -verbose
 //
+//	# Merge "www." domains into their bare form
+//	go run main.go -strip-www
+//
+//	# Print the effective configuration before processing
+//	go run main.go -explain
+//
+//	# Leave the output file untouched when its content would not change
+//	go run main.go -out=output.csv -skip-unchanged
+//
 // The application reads customer data from a CSV file, aggregates customers by email domain,
 // and outputs the results either to stdout or to a CSV file.
 //
 // Flags:
+//   - config: key=value file with default flag values, see internal/config (default: none, or $IMPORTER_CONFIG)
 //   - path: Input CSV file path (default: ./customers.csv)
 //   - out: Output CSV file path (default: stdout)
 //   - verbose: Enable detailed logging (default: false)
+//   - strict: Enable all recommended validation checks; see customerimporter.WithStrictMode (default: false)
+//   - local-part-chars: With -strict, the only characters allowed in local parts, with ranges such as "a-z0-9._-" (default: none)
+//   - strip-www: Strip a leading "www." label from domains before aggregation (default: false)
+//   - skip-unchanged: Skip rewriting -out when a matching .sha256 sidecar exists (default: false)
+//   - email-columns: Comma-separated zero-based email column indexes, e.g. "2,5" (default: 2)
+//   - email-regex: Regexp whose first capture group extracts the email from the column (default: none)
+//   - email-separators: Characters splitting an email column into several addresses, e.g. ";," (default: none)
+//   - email-pattern: Regexp every whole email must match before validation, e.g. "[^+]+@.+" (default: none)
+//   - ascii-only: Reject domains containing non-ASCII characters instead of aggregating them (default: false)
+//   - valid-hostnames: Reject domains that are IP addresses or not syntactically valid hostnames (default: false)
+//   - on-error: How invalid rows are handled, "abort" or "skip" (default: abort)
+//   - rejects: Write rows skipped with -on-error=skip plus their error to this CSV file (default: none)
+//   - skip-reasons: Write "reason,rows" counts of the rows skipped with -on-error=skip to this CSV file (default: none)
+//   - max-error-rate: With -on-error=skip, abort when more than this share of rows is invalid, e.g. 0.05 (default: 0, no limit)
+//   - spill-threshold: Spill counts to sorted temporary files once this many domains are held in memory, merging them at the end (default: 0, in memory)
+//   - spill-dir: Directory of the -spill-threshold files (default: system temp dir)
+//   - two-pass: Read the input twice, collecting the domains first and then counting them into a fixed table (default: false)
+//   - sketch-top: Estimate counts with a count-min sketch in fixed memory, reporting this many largest domains (default: 0, exact counts)
+//   - sketch-epsilon: With -sketch-top, bound of the overcount of every estimate as a share of all customers (default: 0.001)
+//   - sketch-delta: With -sketch-top, probability of an estimate exceeding the -sketch-epsilon bound (default: 0.01)
+//   - cache: Gob file caching the aggregation; a later run over the same input and options loads it instead of parsing the CSV (default: none)
+//   - state-file: Gob file recording how far the input was read; a later run only reads the rows appended since and merges them with the recorded counts (default: none)
+//   - sample-every: Only process the first of every N data rows for a fast approximation (default: 0, all rows)
+//   - sample-scale: With -sample-every, count each sampled row N times to estimate full counts (default: false)
+//   - read-timeout: Fail when the input is not read to its end within this long, e.g. from a stalled FIFO (default: 0, wait forever)
+//   - max-duration: Stop importing after this long and output the partial result, exiting with 0 (default: 0, no limit)
+//   - dedup: Count each distinct email address once per domain (default: false)
+//   - customer-id-column: With -dedup, zero-based column whose customer ID is counted once per domain instead of each email (default: -1, disabled)
+//   - gmail-dots: With -dedup, ignore dots in Gmail local parts (default: false)
+//   - pad-short-rows: Pad rows shorter than the header with empty fields instead of failing (default: false)
+//   - skip-repeated-headers: Skip rows repeating the header, e.g. in concatenated exports, instead of failing (default: false)
+//   - grep: Only output domains containing this substring, case-insensitive (default: none)
+//   - weight-column: Zero-based column whose positive integer is added per row instead of 1 (default: -1, disabled)
+//   - weight-policy: With -weight-column, how blank or invalid weights are handled, "error", "skip" or "one" (default: error)
+//   - filter-column: Zero-based column that must equal -filter-value for a row to be counted (default: -1, all rows)
+//   - filter-value: Value -filter-column must hold, compared after trimming whitespace, e.g. "active" (default: empty)
+//   - date-column: Zero-based column holding each row's date for -by-month (default: -1, disabled)
+//   - date-layout: Go time layout of the -date-column values (default: 2006-01-02)
+//   - date-policy: With -date-column, how blank or unparseable dates are handled, "error", "skip" or "unknown" (default: error)
+//   - ip-column: Zero-based column holding each row's IP address for -by-subnet; invalid addresses are row errors (default: -1, disabled)
+//   - subnet-prefix: Prefix length IPv4 addresses are grouped by for -by-subnet; IPv6 uses /64 (default: 24)
+//   - max-age: Fail when the input file was modified longer ago than this, e.g. 24h (default: 0, disabled)
+//   - check-header: Validate the header before processing any rows, failing fast on a wrong layout (default: false)
+//   - detect-delimiter: Sniff comma, semicolon or tab from the header and strip a UTF-8 BOM (default: false, comma)
+//   - fixed-width: Parse fixed-width records using "offset:length" fields, e.g. "0:10,10:10,20:30" (default: CSV)
+//   - fingerprint: Print a hash of the input content and effective configuration to stderr as "fingerprint=<hex>" (default: false)
+//   - fingerprint-file: Also write that fingerprint to this file, for orchestrators that skip unchanged runs (default: none)
+//   - summary-out: Write the outcome of the run as one JSON line to this file, e.g. /dev/fd/3, on success and failure (default: none)
+//   - explain: Print the resolved importer/exporter configuration to stderr (default: false)
+//   - count-cap: Clamp reported counts to this ceiling and add a capped column (default: 0, disabled)
+//   - sample-email: Add a sample_email column with the first address seen per domain (default: false)
+//   - casing-report: Print to stderr the domains seen with more than one casing, e.g. Example.com and example.com (default: false)
+//   - prefer-last-seen: Keep the last instead of the first value seen per domain, such as the -sample-email address (default: false)
+//   - normalize-to: Add a normalized_count column scaling counts to sum to this total (default: 0, disabled)
+//   - per-mille: Add a per_mille column with customers per thousand of the total (default: false)
+//   - decimals: Decimal places of the per_mille column, rounded half up (default: 2)
+//   - scale-to: Add a scaled_count column min-max scaling counts from 0 up to this value, e.g. 1 or 100 (default: 0, disabled)
+//   - log-count: Add a log_count column holding log10(count+1) for plotting skewed distributions (default: false)
+//   - max-share: Fail when a single domain holds more than this share of all customers, e.g. 0.4 (default: 0, disabled)
+//   - health: Print a data quality report to stderr after the import, "text" or "json" (default: none)
+//   - signed-counts: Write counts as int64 and fail on counts above math.MaxInt64 (default: false)
+//   - category: Add a category column classifying domains as freemail, corporate or unknown (default: false)
+//   - company-names: CSV file with domain and company columns adding a company column, the domain when unmapped (default: none)
+//   - freemail-providers: With -category, comma-separated provider domains replacing the built-in list (default: built-in)
+//   - public-suffixes: Comma-separated multi-level public suffixes such as co.uk replacing the built-in list used by -strip-www and -category (default: built-in)
+//   - domain-groups: JSON file mapping group names to domain lists, e.g. {"partners": ["c.com"]}, counting customers per group
+//     with an "ungrouped" catch-all instead of per domain (default: none)
+//   - equivalent-tlds: Semicolon-separated classes of comma-separated equivalent TLDs, canonical first, e.g. "com,co;de,at", merging brand.co into brand.com (default: none)
+//   - exclude: Comma-separated domains left out of the output (default: none)
+//   - min-count: Only output domains with at least this many customers (default: 0, all)
+//   - top: Only output the domains with the most customers, this many at most (default: 0, all)
+//   - post-process: Command, split on spaces, receiving the domains as JSON on stdin after -top and printing the
+//     transformed JSON array to stdout, which is then exported (default: none)
+//   - post-process-timeout: Kill -post-process when it runs longer than this (default: 30s)
+//   - sort: Sort the output by "domain" or by "count" (descending) before writing it (default: import order)
+//   - format: Output format, "csv", "list" for sorted domain names only, one per line, "breakdown" for
+//     role/personal account counts and category per domain, "json" for the domains wrapped in an
+//     envelope with import metadata, "json-tree" for the counts nested by reversed domain labels, e.g.
+//     {"com":{"example":42}}, or "xlsx" for an Excel workbook written to -out (default: csv)
+//   - reverse-index: Output "number_of_customers,domains" rows grouping domains by count instead (default: false)
+//   - chart: Print a horizontal bar chart of the -top domains (10 unless set) to the terminal instead (default: false)
+//   - chart-width: Width in terminal cells of the longest -chart bar (default: 40)
+//   - rollup: Output "length" totals summing counts by domain length bucket instead (default: none)
+//   - length-buckets: With -rollup=length, comma-separated inclusive upper bounds of the buckets (default: 5,10)
+//   - no-clobber: Fail instead of overwriting an existing -out file (default: false)
+//   - force: Overwrite existing output files even with -no-clobber (default: false)
+//   - verify: Read every written -out file back and fail unless its records and checksum match the export (default: false)
+//   - chunk-size: Split -out into numbered files of at most this many records, e.g. out-0001.csv (default: 0, disabled)
+//   - manifest: Write manifest.json next to -out listing every output file with its size and record count (default: false)
+//   - baseline: Previous export to compare with, outputting "domain,number_of_customers,previous,trend,percent_change" rows instead (default: none)
+//   - reference: Expected domain distribution, a CSV with domain and share or number_of_customers columns, outputting
+//     "domain,observed,expected,chi_square" rows and a total row with the chi-square statistic instead (default: none)
+//   - compare: Other input file, e.g. yesterday's, imported with the same options, outputting "domain,count_a,count_b,delta" rows
+//     with its counts as count_a and those of -path as count_b instead (default: none)
+//   - by-month: Output "domain,month,count" rows counting customers per -date-column month instead (default: false)
+//   - by-subnet: Output "subnet,number_of_customers" rows counting customers per -ip-column subnet instead (default: false)
+//   - anonymize: Replace domains in the CSV with stable "domain-<hash8>.example" pseudonyms; only with -format=csv (default: false)
+//   - domain-id: Add a domain_id hash column to -out, "fnv" or "sha256" (default: none)
+//   - template: text/template file executed per domain instead of writing CSV, e.g. for SQL INSERTs; {{define "header"}} and
+//     {{define "footer"}} blocks run once around the records (default: none, CSV)
+//   - delta-api: Base URL of an HTTP API answering GET <url>/<domain> with the current count, adding a delta column (default: none)
+//   - line-ending: Terminate CSV records with "lf" or "crlf" on every platform (default: lf)
+//
+// Flags not given on the command line are read from IMPORTER_* environment variables, e.g.
+// IMPORTER_STRIP_WWW=true, and then from the -config file, before falling back to the defaults.
+//
+// Interrupting the import (Ctrl-C / SIGINT or SIGTERM) stops reading, still prints or exports
+// the domains aggregated so far with a "partial result" notice on stderr, and exits with 130.
 //
 // Exit codes:
 //   - 0: Success
 //   - 1: Error occurred (file not found, invalid CSV, etc.)
+//   - 130: Interrupted; the output holds a partial result
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
 	"time"
 
 	"importer/customerimporter"
 	"importer/exporter"
+	"importer/internal/config"
 	"log/slog"
 )
 
+// exitInterrupted is the exit code used when an interrupted import produced a partial result
+// (128 + SIGINT, as shells report it).
+const exitInterrupted = 130
+
+// deltaAPITimeout bounds each -delta-api request.
+const deltaAPITimeout = 10 * time.Second
+
 // Options holds command-line flags for the application
 type Options struct {
 	path    *string
 	outFile *string
 	verbose *bool
+	explain *bool
+
+	strict        *bool
+	localChars    *string
+	stripWWW      *bool
+	emailColumns  *string
+	emailRegex    *string
+	emailSeps     *string
+	onError       *string
+	rejects       *string
+	skipReasons   *string
+	readTimeout   *time.Duration
+	maxDuration   *time.Duration
+	dedup         *bool
+	gmailDots     *bool
+	padShortRows  *bool
+	skipHeaders   *bool
+	fixedWidth    *string
+	skipUnchanged *bool
+	domainID      *string
+	countCap      *uint64
+	sampleEmail   *bool
+	lastSeen      *bool
+	casingReport  *bool
+	normalizeTo   *uint64
+	perMille      *bool
+	decimals      *int
+	scaleTo       *float64
+	checkHeader   *bool
+	signedCounts  *bool
+	category      *bool
+	companyNames  *string
+	freemail      *string
+	suffixes      *string
+	domainGroups  *string
+	sortOrder     *string
+	weightColumn  *int
+	weightPolicy  *string
+	reverseIndex  *bool
+	chart         *bool
+	chartWidth    *int
+	rollup        *string
+	maxAge        *time.Duration
+	format        *string
+	emailPattern  *string
+	lengthBuckets *string
+	grep          *string
+	detectDelim   *bool
+	chunkSize     *int
+	manifest      *bool
+	asciiOnly     *bool
+	validHosts    *bool
+	equivTLDs     *string
+	exclude       *string
+	minCount      *uint64
+	top           *int
+	postProcess   *string
+	postTimeout   *time.Duration
+	dateColumn    *int
+	filterColumn  *int
+	filterValue   *string
+	dateLayout    *string
+	datePolicy    *string
+	byMonth       *bool
+	ipColumn      *int
+	subnetPrefix  *int
+	bySubnet      *bool
+	noClobber     *bool
+	force         *bool
+	verify        *bool
+	maxErrorRate  *float64
+	baseline      *string
+	reference     *string
+	compare       *string
+	customerID    *int
+	spillAt       *int
+	spillDir      *string
+	twoPass       *bool
+	sketchTop     *int
+	sketchEpsilon *float64
+	sketchDelta   *float64
+	cache         *string
+	stateFile     *string
+	sampleEvery   *int
+	sampleScale   *bool
+	logCount      *bool
+	health        *string
+	maxShare      *float64
+	lineEnding    *string
+	deltaAPI      *string
+	anonymize     *bool
+	template      *string
+	configFile    *string
+	fingerprint   *bool
+	fpFile        *string
+	summaryOut    *string
 }
 
-func readOptions() *Options {
+// readOptions parses the flags and applies the -config file and environment variables. Invalid
+// flags exit the process; an invalid configuration is returned as error along with the options
+// parsed so far.
+func readOptions() (*Options, error) {
 	opts := &Options{}
 	opts.path = flag.String("path", "./customers.csv", "Path to the file with customer data")
 	opts.outFile = flag.String("out", "", "Optional: output file path. If empty program will output results to the terminal")
 	opts.verbose = flag.Bool("verbose", false, "Enable verbose logging with detailed progress information")
+	opts.explain = flag.Bool("explain", false, "Print the resolved importer/exporter configuration to stderr before processing")
+	opts.strict = flag.Bool("strict", false, "Enable all recommended validation checks: no whitespace, RFC length limits, dotted domains and DNS label syntax")
+	opts.localChars = flag.String("local-part-chars", "", "Optional: with -strict, the only characters allowed in local parts, with ranges such as \"a-z0-9._-\"")
+	opts.stripWWW = flag.Bool("strip-www", false, "Strip a leading \"www.\" label from email domains before aggregation")
+	opts.emailColumns = flag.String("email-columns", "", "Optional: comma-separated zero-based indexes of email columns, e.g. \"2,5\"")
+	opts.emailSeps = flag.String("email-separators", "", "Optional: characters splitting an email column holding several addresses, e.g. \";,\"")
+	opts.emailRegex = flag.String("email-regex", "", "Optional: regexp whose first capture group extracts the email from the email column")
+	opts.onError = flag.String("on-error", string(customerimporter.ErrorPolicyAbort), "How invalid rows are handled: \"abort\" or \"skip\"")
+	opts.rejects = flag.String("rejects", "", "Optional: write rows skipped with -on-error=skip and their validation error to this CSV file")
+	opts.skipReasons = flag.String("skip-reasons", "", "Optional: write how many rows -on-error=skip skipped per validation failure reason to this CSV file")
+	opts.maxDuration = flag.Duration("max-duration", 0, "Optional: stop importing after this long, e.g. \"5m\", and output the domains aggregated so far")
+	opts.readTimeout = flag.Duration("read-timeout", 0, "Optional: fail when the input is not read to its end within this long, e.g. \"30s\" for a stalled FIFO producer")
+	opts.dedup = flag.Bool("dedup", false, "Count each distinct email address once per domain")
+	opts.gmailDots = flag.Bool("gmail-dots", false, "With -dedup, treat john.doe@gmail.com and johndoe@gmail.com as the same customer")
+	opts.padShortRows = flag.Bool("pad-short-rows", false, "Pad rows shorter than the header with empty fields instead of failing")
+	opts.skipHeaders = flag.Bool("skip-repeated-headers", false, "Skip rows identical to the header, e.g. in concatenated exports, instead of failing them")
+	opts.fixedWidth = flag.String("fixed-width", "", "Optional: parse fixed-width records with comma-separated \"offset:length\" fields instead of CSV")
+	opts.skipUnchanged = flag.Bool("skip-unchanged", false, "Hash the output and skip rewriting -out when its .sha256 sidecar matches")
+	opts.domainID = flag.String("domain-id", "", "Optional: add a domain_id column to -out hashed with \"fnv\" or \"sha256\"")
+	opts.countCap = flag.Uint64("count-cap", 0, "Optional: report counts above this ceiling as the ceiling and add a capped column")
+	opts.sampleEmail = flag.Bool("sample-email", false, "Add a sample_email column with the first address seen for each domain")
+	opts.casingReport = flag.Bool("casing-report", false, "Print every domain seen with more than one casing, e.g. Example.com and example.com, to stderr")
+	opts.lastSeen = flag.Bool("prefer-last-seen", false, "Keep the last value seen per domain instead of the first, e.g. for the -sample-email address")
+	opts.normalizeTo = flag.Uint64("normalize-to", 0, "Optional: add a normalized_count column scaling counts so they sum to this total")
+	opts.perMille = flag.Bool("per-mille", false, "Add a per_mille column holding customers per thousand of the total")
+	opts.scaleTo = flag.Float64("scale-to", 0, "Optional: add a scaled_count column min-max scaling counts from 0 up to this value, e.g. 1 or 100")
+	opts.decimals = flag.Int("decimals", 2, "Decimal places of the -per-mille column, rounded half up")
+	opts.checkHeader = flag.Bool("check-header", false, "Validate the header (column count, email column name) before processing any rows")
+	opts.signedCounts = flag.Bool("signed-counts", false, "Write counts as int64 and fail instead of writing a count above the int64 range")
+	opts.category = flag.Bool("category", false, "Add a category column classifying each domain as freemail, corporate or unknown")
+	opts.companyNames = flag.String("company-names", "", "Optional: CSV file with domain and company columns; adds a company column holding the company name, or the domain when unmapped")
+	opts.domainGroups = flag.String("domain-groups", "", "Optional: JSON file mapping group names to domain lists, counting customers per group instead of per domain")
+	opts.freemail = flag.String("freemail-providers", "", "Optional: comma-separated free email provider domains replacing the built-in list for -category")
+	opts.suffixes = flag.String("public-suffixes", "", "Optional: comma-separated multi-level public suffixes such as co.uk replacing the built-in list for -strip-www and -category")
+	opts.sortOrder = flag.String("sort", "", "Optional: sort the output by \"domain\" or by \"count\" (descending) before writing it")
+	opts.weightColumn = flag.Int("weight-column", -1, "Optional: zero-based column holding how many customers each row represents")
+	opts.weightPolicy = flag.String("weight-policy", string(customerimporter.WeightPolicyError), "How blank or invalid weights are handled: \"error\", \"skip\" or \"one\"")
+	opts.reverseIndex = flag.Bool("reverse-index", false, "Output one row per count listing the domains having it instead of one row per domain")
+	opts.chart = flag.Bool("chart", false, "Print a bar chart of the -top domains, 10 unless set, to the terminal instead of CSV")
+	opts.chartWidth = flag.Int("chart-width", exporter.DefaultChartWidth, "Width in terminal cells of the longest -chart bar")
+	opts.grep = flag.String("grep", "", "Optional: only output domains containing this substring (case-insensitive)")
+	opts.detectDelim = flag.Bool("detect-delimiter", false, "Detect a comma, semicolon or tab delimiter and UTF-8 byte order mark from the input")
+	opts.rollup = flag.String("rollup", "", "Optional: output totals per rollup dimension instead of per domain; \"length\" buckets by domain length")
+	opts.lengthBuckets = flag.String("length-buckets", "5,10", "Comma-separated inclusive upper bounds of the -rollup=length buckets")
+	opts.maxAge = flag.Duration("max-age", 0, "Optional: fail when the input file's modification time is older than this, e.g. 24h")
+	opts.format = flag.String("format", "csv", "Output format: \"csv\", \"list\" (sorted domain names only, one per line), \"breakdown\" (role/personal accounts and category), \"json\" (domains in an envelope with import metadata), \"json-tree\" (counts nested by reversed domain labels) or \"xlsx\" (Excel workbook, requires -out)")
+	opts.emailPattern = flag.String("email-pattern", "", "Optional: regexp every whole email address must match, e.g. to reject plus-addressing")
+	opts.chunkSize = flag.Int("chunk-size", 0, "Optional: split -out into numbered files of at most this many records each")
+	opts.manifest = flag.Bool("manifest", false, "Write manifest.json next to -out listing every output file with its size and record count")
+	opts.asciiOnly = flag.Bool("ascii-only", false, "Reject domains containing non-ASCII characters, such as bücher.de")
+	opts.validHosts = flag.Bool("valid-hostnames", false, "Reject domains that are IP addresses or not valid hostnames, such as exam_ple.com")
+	opts.equivTLDs = flag.String("equivalent-tlds", "", "Optional: semicolon-separated classes of comma-separated equivalent TLDs, canonical first, e.g. \"com,co;de,at\"")
+	opts.exclude = flag.String("exclude", "", "Optional: comma-separated domains left out of the output")
+	opts.minCount = flag.Uint64("min-count", 0, "Optional: only output domains with at least this many customers")
+	opts.top = flag.Int("top", 0, "Optional: only output this many domains with the most customers")
+	opts.postProcess = flag.String("post-process", "", "Optional: command receiving the domains as a JSON array on stdin and printing the transformed array to stdout, e.g. \"./transform.py --strict\"")
+	opts.postTimeout = flag.Duration("post-process-timeout", exporter.DefaultPostProcessTimeout, "Kill the -post-process command when it runs longer than this")
+	opts.filterColumn = flag.Int("filter-column", -1, "Optional: zero-based column that must equal -filter-value for a row to be counted")
+	opts.filterValue = flag.String("filter-value", "", "Value -filter-column must hold, compared after trimming whitespace, e.g. \"active\"")
+	opts.dateColumn = flag.Int("date-column", -1, "Optional: zero-based column holding each row's date for -by-month")
+	opts.dateLayout = flag.String("date-layout", "2006-01-02", "Go time layout of the -date-column values")
+	opts.datePolicy = flag.String("date-policy", string(customerimporter.DatePolicyError), "How blank or unparseable dates are handled: \"error\", \"skip\" or \"unknown\"")
+	opts.byMonth = flag.Bool("by-month", false, "Output customers per domain and -date-column month instead of one row per domain")
+	opts.ipColumn = flag.Int("ip-column", -1, "Optional: zero-based column holding each row's IP address for -by-subnet")
+	opts.subnetPrefix = flag.Int("subnet-prefix", 24, "Prefix length IPv4 addresses are grouped by for -by-subnet; IPv6 uses /64")
+	opts.bySubnet = flag.Bool("by-subnet", false, "Output customers per -ip-column subnet instead of one row per domain")
+	opts.noClobber = flag.Bool("no-clobber", false, "Fail instead of overwriting an existing -out file")
+	opts.force = flag.Bool("force", false, "Overwrite existing output files even with -no-clobber")
+	opts.verify = flag.Bool("verify", false, "Read every written -out file back and fail unless its records and checksum match the export")
+	opts.maxErrorRate = flag.Float64("max-error-rate", 0, "Optional: with -on-error=skip, abort when more than this share of rows is invalid, e.g. 0.05")
+	opts.baseline = flag.String("baseline", "", "Optional: previous export to compare with, outputting the up/down/flat/new/gone trend per domain")
+	opts.reference = flag.String("reference", "", "Optional: expected domain distribution (domain,share CSV or a previous export) to compare with, outputting chi-square contributions per domain")
+	opts.compare = flag.String("compare", "", "Optional: other input file, e.g. yesterday's, to compare with -path, outputting count_a,count_b,delta per domain")
+	opts.customerID = flag.Int("customer-id-column", -1, "Optional: with -dedup, zero-based column whose customer ID is counted once per domain")
+	opts.spillAt = flag.Int("spill-threshold", 0, "Optional: spill counts to sorted temporary files once this many domains are held in memory")
+	opts.cache = flag.String("cache", "", "Optional: gob file caching the aggregation, reused by later runs over the same input and options")
+	opts.stateFile = flag.String("state-file", "", "Optional: gob file recording how far the input was read, so later runs over the growing file only read appended rows")
+	opts.twoPass = flag.Bool("two-pass", false, "Read the input twice for exact counts in bounded memory: domains first, then their counts")
+	opts.sketchTop = flag.Int("sketch-top", 0, "Optional: estimate counts with a count-min sketch in fixed memory, reporting this many largest domains")
+	opts.sketchEpsilon = flag.Float64("sketch-epsilon", 0.001, "With -sketch-top, bound of the overcount of every estimate as a share of all customers")
+	opts.sketchDelta = flag.Float64("sketch-delta", 0.01, "With -sketch-top, probability of an estimate exceeding the -sketch-epsilon bound")
+	opts.spillDir = flag.String("spill-dir", "", "Optional: directory of the -spill-threshold files (default: system temp dir)")
+	opts.sampleEvery = flag.Int("sample-every", 0, "Optional: only process the first of every N data rows for a fast approximation, e.g. 100")
+	opts.sampleScale = flag.Bool("sample-scale", false, "With -sample-every, count each sampled row N times to estimate the full counts")
+	opts.maxShare = flag.Float64("max-share", 0, "Optional: exit non-zero when a single domain holds more than this share of all customers, e.g. 0.4")
+	opts.health = flag.String("health", "", "Optional: print a data quality report to stderr after the import, \"text\" or \"json\"")
+	opts.logCount = flag.Bool("log-count", false, "Add a log_count column holding log10(count+1) for plotting skewed distributions")
+	opts.template = flag.String("template", "", "Optional: text/template file executed per domain instead of writing CSV, with optional \"header\" and \"footer\" templates")
+	opts.anonymize = flag.Bool("anonymize", false, "Replace domains in the CSV with stable \"domain-<hash8>.example\" pseudonyms, keeping counts and order")
+	opts.deltaAPI = flag.String("delta-api", "", "Optional: base URL of an HTTP API answering GET <url>/<domain> with the current count, adding a delta column")
+	opts.lineEnding = flag.String("line-ending", string(exporter.LineEndingLF), "Terminate CSV records with \"lf\" or \"crlf\" regardless of the platform")
+	opts.fingerprint = flag.Bool("fingerprint", false, "Print a hash of the input content and effective configuration to stderr before processing")
+	opts.fpFile = flag.String("fingerprint-file", "", "Optional: also write the -fingerprint hash to this file")
+	opts.summaryOut = flag.String("summary-out", "", "Optional: write the outcome of the run (status, totals, duration) as one JSON line to this file, e.g. /dev/fd/3")
+	opts.configFile = flag.String("config", "", "Optional: key=value file with default flag values, overridden by IMPORTER_* environment variables and flags")
 	flag.Parse()
-	return opts
+	if err := config.Load(flag.CommandLine, "config", os.Environ()); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// runState is what -summary-out reports about the run, filled in as the run progresses.
+var runState struct {
+	summaryOut string
+	recorder   *exporter.RunRecorder
+}
+
+// exit writes the -summary-out file, if requested, and exits with code. Every exit after the
+// flags are parsed goes through it so the summary is written on failure too.
+func exit(code int) {
+	if runState.summaryOut != "" {
+		if err := exporter.WriteRunSummary(runState.summaryOut, runState.recorder.Summary(code)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write run summary to %s: %v\n", runState.summaryOut, err)
+		}
+	}
+	os.Exit(code)
 }
 
 // setupLogger configures the global slog logger based on verbosity setting.
@@ -73,43 +426,1005 @@ func setupLogger(verbose bool) {
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: level,
 	})
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(runState.recorder.Handler(handler)))
+}
+
+// importerOptions translates the command-line flags into customerimporter options.
+func importerOptions(opts *Options) ([]customerimporter.Option, error) {
+	var importOpts []customerimporter.Option
+	if *opts.strict {
+		importOpts = append(importOpts, customerimporter.WithStrictMode())
+	}
+	if *opts.localChars != "" {
+		if !*opts.strict {
+			return nil, fmt.Errorf("-local-part-chars requires -strict")
+		}
+		importOpts = append(importOpts, customerimporter.WithLocalPartAllowedChars(*opts.localChars))
+	}
+	if *opts.stripWWW {
+		importOpts = append(importOpts, customerimporter.WithStripWWWPrefix())
+	}
+	if *opts.emailColumns != "" {
+		columns, err := parseIntList(*opts.emailColumns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -email-columns: %w", err)
+		}
+		importOpts = append(importOpts, customerimporter.WithEmailColumns(columns))
+	}
+	if *opts.emailRegex != "" {
+		re, err := regexp.Compile(*opts.emailRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -email-regex: %w", err)
+		}
+		importOpts = append(importOpts, customerimporter.WithEmailRegex(re))
+	}
+	if *opts.emailSeps != "" {
+		importOpts = append(importOpts, customerimporter.WithEmailSeparators([]rune(*opts.emailSeps)))
+	}
+	if *opts.emailPattern != "" {
+		re, err := regexp.Compile(*opts.emailPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -email-pattern: %w", err)
+		}
+		// Any alternative matching the whole address is enough, as with an anchored pattern
+		re.Longest()
+		importOpts = append(importOpts, customerimporter.WithEmailPattern(re))
+	}
+	if *opts.asciiOnly {
+		importOpts = append(importOpts, customerimporter.WithASCIIOnlyDomains())
+	}
+	if *opts.validHosts {
+		importOpts = append(importOpts, customerimporter.WithHostnameValidation())
+	}
+	switch policy := customerimporter.ErrorPolicy(*opts.onError); policy {
+	case customerimporter.ErrorPolicyAbort, customerimporter.ErrorPolicySkip:
+		importOpts = append(importOpts, customerimporter.WithErrorPolicy(policy))
+	default:
+		return nil, fmt.Errorf("invalid -on-error %q: want %q or %q", *opts.onError,
+			customerimporter.ErrorPolicyAbort, customerimporter.ErrorPolicySkip)
+	}
+	if *opts.maxErrorRate > 0 {
+		importOpts = append(importOpts, customerimporter.WithMaxErrorRate(*opts.maxErrorRate))
+	}
+	if *opts.rejects != "" {
+		importOpts = append(importOpts, customerimporter.WithRejectsOutput(*opts.rejects))
+	}
+	if *opts.weightColumn >= 0 {
+		importOpts = append(importOpts, customerimporter.WithWeightColumn(*opts.weightColumn))
+	}
+	switch policy := customerimporter.WeightPolicy(*opts.weightPolicy); policy {
+	case customerimporter.WeightPolicyError, customerimporter.WeightPolicySkip, customerimporter.WeightPolicyOne:
+		importOpts = append(importOpts, customerimporter.WithWeightPolicy(policy))
+	default:
+		return nil, fmt.Errorf("invalid -weight-policy %q: want %q, %q or %q", *opts.weightPolicy,
+			customerimporter.WeightPolicyError, customerimporter.WeightPolicySkip, customerimporter.WeightPolicyOne)
+	}
+	if *opts.filterColumn >= 0 {
+		importOpts = append(importOpts, customerimporter.WithRowFilter(*opts.filterColumn, *opts.filterValue))
+	}
+	if *opts.dateColumn >= 0 {
+		importOpts = append(importOpts, customerimporter.WithDateColumn(*opts.dateColumn, *opts.dateLayout))
+	}
+	if *opts.ipColumn >= 0 {
+		importOpts = append(importOpts, customerimporter.WithIPColumn(*opts.ipColumn, *opts.subnetPrefix))
+	}
+	switch policy := customerimporter.DatePolicy(*opts.datePolicy); policy {
+	case customerimporter.DatePolicyError, customerimporter.DatePolicySkip, customerimporter.DatePolicyUnknown:
+		importOpts = append(importOpts, customerimporter.WithDatePolicy(policy))
+	default:
+		return nil, fmt.Errorf("invalid -date-policy %q: want %q, %q or %q", *opts.datePolicy,
+			customerimporter.DatePolicyError, customerimporter.DatePolicySkip, customerimporter.DatePolicyUnknown)
+	}
+	if *opts.maxDuration > 0 {
+		importOpts = append(importOpts, customerimporter.WithMaxDuration(*opts.maxDuration))
+	}
+	if *opts.readTimeout > 0 {
+		importOpts = append(importOpts, customerimporter.WithReadTimeout(*opts.readTimeout))
+	}
+	if *opts.domainGroups != "" {
+		groups, err := loadDomainGroups(*opts.domainGroups)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -domain-groups: %w", err)
+		}
+		importOpts = append(importOpts, customerimporter.WithDomainGroups(groups))
+	}
+	if *opts.twoPass {
+		importOpts = append(importOpts, customerimporter.WithTwoPassAggregation())
+	}
+	if *opts.cache != "" {
+		importOpts = append(importOpts, customerimporter.WithAggregationCache(*opts.cache))
+	}
+	if *opts.stateFile != "" {
+		importOpts = append(importOpts, customerimporter.WithIncrementalState(*opts.stateFile))
+	}
+	if *opts.spillAt > 0 {
+		importOpts = append(importOpts, customerimporter.WithExternalAggregation(*opts.spillAt, *opts.spillDir))
+	}
+	if *opts.sketchTop != 0 {
+		importOpts = append(importOpts, customerimporter.WithCountMinSketch(*opts.sketchEpsilon, *opts.sketchDelta, *opts.sketchTop))
+	}
+	if *opts.sampleEvery > 1 {
+		importOpts = append(importOpts, customerimporter.WithSampling(*opts.sampleEvery, *opts.sampleScale))
+	}
+	if *opts.dedup {
+		importOpts = append(importOpts, customerimporter.WithDeduplication())
+	}
+	if *opts.customerID >= 0 {
+		importOpts = append(importOpts, customerimporter.WithCustomerIDColumn(*opts.customerID))
+	}
+	if *opts.fixedWidth != "" {
+		layout, err := parseFixedWidth(*opts.fixedWidth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -fixed-width: %w", err)
+		}
+		importOpts = append(importOpts, customerimporter.WithFixedWidth(layout))
+	}
+	if *opts.skipHeaders {
+		importOpts = append(importOpts, customerimporter.WithSkipRepeatedHeaders())
+	}
+	if *opts.padShortRows {
+		importOpts = append(importOpts, customerimporter.WithShortRowPadding())
+	}
+	if *opts.sampleEmail {
+		importOpts = append(importOpts, customerimporter.WithSampleEmail())
+	}
+	if *opts.lastSeen {
+		importOpts = append(importOpts, customerimporter.WithPreferLastSeen())
+	}
+	if *opts.casingReport {
+		importOpts = append(importOpts, customerimporter.WithCasingReport(printCasingVariants))
+	}
+	if *opts.checkHeader {
+		importOpts = append(importOpts, customerimporter.WithHeaderValidation())
+	}
+	if *opts.detectDelim {
+		importOpts = append(importOpts, customerimporter.WithDelimiterDetection())
+	}
+	if *opts.grep != "" {
+		importOpts = append(importOpts, customerimporter.WithDomainFilter(customerimporter.DomainContains(*opts.grep)),
+			customerimporter.WithCacheKey("grep="+*opts.grep))
+	}
+	if *opts.category || *opts.format == "breakdown" {
+		importOpts = append(importOpts, customerimporter.WithCategory())
+	}
+	if *opts.format == "breakdown" {
+		importOpts = append(importOpts, customerimporter.WithRoleAccounts())
+	}
+	if *opts.freemail != "" {
+		var providers []string
+		for _, domain := range strings.Split(*opts.freemail, ",") {
+			providers = append(providers, strings.TrimSpace(domain))
+		}
+		importOpts = append(importOpts, customerimporter.WithFreemailProviders(providers))
+	}
+	if *opts.suffixes != "" {
+		var suffixes []string
+		for _, suffix := range strings.Split(*opts.suffixes, ",") {
+			suffixes = append(suffixes, strings.TrimSpace(suffix))
+		}
+		importOpts = append(importOpts, customerimporter.WithPublicSuffixes(suffixes))
+	}
+	if *opts.gmailDots {
+		importOpts = append(importOpts, customerimporter.WithDotInsensitiveDomains(customerimporter.GmailDomains))
+	}
+	return importOpts, nil
+}
+
+// parseFixedWidth parses a fixed-width layout such as "0:10,10:10,20:30".
+func parseFixedWidth(value string) (customerimporter.FixedWidthLayout, error) {
+	var layout customerimporter.FixedWidthLayout
+	for _, field := range strings.Split(value, ",") {
+		offset, length, found := strings.Cut(strings.TrimSpace(field), ":")
+		if !found {
+			return layout, fmt.Errorf("field %q: want offset:length", field)
+		}
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return layout, fmt.Errorf("field %q: %w", field, err)
+		}
+		l, err := strconv.Atoi(length)
+		if err != nil {
+			return layout, fmt.Errorf("field %q: %w", field, err)
+		}
+		if o < 0 || l < 1 {
+			return layout, fmt.Errorf("field %q: want a non-negative offset and a positive length", field)
+		}
+		layout.Fields = append(layout.Fields, customerimporter.FixedWidthField{Offset: o, Length: l})
+	}
+	return layout, nil
+}
+
+// parseIntList parses a comma-separated list of integers such as "2,5".
+func parseIntList(value string) ([]int, error) {
+	var columns []int
+	for _, field := range strings.Split(value, ",") {
+		column, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// pipeline assembles the post-aggregation stages selected by the flags, applied in a fixed
+// order: -equivalent-tlds, whose renamed domains are sorted alphabetically again, then -exclude,
+// then -min-count, then -top.
+func pipeline(opts *Options) customerimporter.Pipeline {
+	var stages customerimporter.Pipeline
+	if *opts.equivTLDs != "" {
+		var classes [][]string
+		for _, class := range strings.Split(*opts.equivTLDs, ";") {
+			var tlds []string
+			for _, tld := range strings.Split(class, ",") {
+				tlds = append(tlds, strings.TrimPrefix(strings.TrimSpace(tld), "."))
+			}
+			classes = append(classes, tlds)
+		}
+		stages = stages.Then(customerimporter.MergeEquivalentTLDs(classes)).
+			Then(customerimporter.Sort(customerimporter.SortByDomain))
+	}
+	if *opts.exclude != "" {
+		var domains []string
+		for _, domain := range strings.Split(*opts.exclude, ",") {
+			domains = append(domains, customerimporter.FoldDomain(strings.TrimSpace(domain)))
+		}
+		stages = stages.Then(customerimporter.Exclude(domains))
+	}
+	if *opts.minCount > 0 {
+		stages = stages.Then(customerimporter.MinCount(*opts.minCount))
+	}
+	if *opts.top > 0 {
+		stages = stages.Then(customerimporter.TopN(*opts.top))
+	}
+	return stages
+}
+
+// postProcess runs data through the -post-process command.
+func postProcess(ctx context.Context, opts *Options, data []customerimporter.DomainData) ([]customerimporter.DomainData, error) {
+	processor := exporter.CommandProcessor{Command: strings.Fields(*opts.postProcess), Timeout: *opts.postTimeout}
+	return processor.Process(ctx, data)
+}
+
+// exporterOptions translates the command-line flags into exporter options.
+func exporterOptions(opts *Options) ([]exporter.Option, error) {
+	var exportOpts []exporter.Option
+	if *opts.skipUnchanged {
+		exportOpts = append(exportOpts, exporter.WithSkipUnchanged())
+	}
+	if *opts.domainID != "" {
+		exportOpts = append(exportOpts, exporter.WithDomainID(exporter.HashAlgorithm(*opts.domainID)))
+	}
+	if *opts.countCap > 0 {
+		exportOpts = append(exportOpts, exporter.WithCountCap(*opts.countCap))
+	}
+	if *opts.sampleEmail {
+		exportOpts = append(exportOpts, exporter.WithSampleEmail())
+	}
+	if *opts.normalizeTo > 0 {
+		exportOpts = append(exportOpts, exporter.WithNormalizedCounts(*opts.normalizeTo))
+	}
+	if *opts.perMille {
+		exportOpts = append(exportOpts, exporter.WithPerMille(), exporter.WithDecimals(*opts.decimals))
+	}
+	if *opts.scaleTo != 0 {
+		exportOpts = append(exportOpts, exporter.WithScaledCounts(*opts.scaleTo))
+	}
+	if *opts.logCount {
+		exportOpts = append(exportOpts, exporter.WithLogCounts())
+	}
+	if *opts.signedCounts {
+		exportOpts = append(exportOpts, exporter.WithSignedCounts())
+	}
+	if *opts.category {
+		exportOpts = append(exportOpts, exporter.WithCategory())
+	}
+	if *opts.companyNames != "" {
+		names, err := exporter.LoadDomainNames(*opts.companyNames)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -company-names: %w", err)
+		}
+		exportOpts = append(exportOpts, exporter.WithDomainNames(names))
+	}
+	if *opts.chunkSize > 0 {
+		exportOpts = append(exportOpts, exporter.WithChunkSize(*opts.chunkSize))
+	}
+	if *opts.manifest {
+		exportOpts = append(exportOpts, exporter.WithManifest())
+	}
+	if *opts.noClobber && !*opts.force {
+		exportOpts = append(exportOpts, exporter.WithNoClobber())
+	}
+	if *opts.verify {
+		exportOpts = append(exportOpts, exporter.WithVerifyAfterWrite())
+	}
+	if *opts.anonymize {
+		exportOpts = append(exportOpts, exporter.WithAnonymizedDomains())
+	}
+	if *opts.deltaAPI != "" {
+		fetcher := exporter.HTTPCountFetcher{BaseURL: *opts.deltaAPI, Client: &http.Client{Timeout: deltaAPITimeout}}
+		exportOpts = append(exportOpts, exporter.WithCountDelta(fetcher))
+	}
+	if *opts.lineEnding != string(exporter.LineEndingLF) {
+		exportOpts = append(exportOpts, exporter.WithLineEnding(exporter.LineEnding(*opts.lineEnding)))
+	}
+	if *opts.sortOrder != "" {
+		exportOpts = append(exportOpts, exporter.WithSortOnExport(customerimporter.SortOrder(*opts.sortOrder)))
+	}
+	if *opts.template != "" {
+		tmpl, err := template.ParseFiles(*opts.template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -template: %w", err)
+		}
+		exportOpts = append(exportOpts, exporter.WithTemplate(tmpl))
+	}
+	return exportOpts, nil
+}
+
+// explain writes the fully resolved run configuration as key=value lines.
+func explain(w io.Writer, opts *Options, importer *customerimporter.CustomerImporter, exporter *exporter.CustomerExporter) {
+	fmt.Fprintf(w, "path=%s\n", *opts.path)
+	fmt.Fprintf(w, "out=%s\n", *opts.outFile)
+	fmt.Fprintf(w, "verbose=%t\n", *opts.verbose)
+	fmt.Fprintln(w, importer.Config())
+	fmt.Fprintln(w, exporter.Config())
+}
+
+// stdoutStream returns a RecordWriter on stdout when the results are printed as plain CSV and
+// the export options allow writing each record as soon as it is produced, avoiding the full
+// []DomainData. It returns nil when the buffered export path must be used, such as for pipeline
+// stages that need the complete result, which also reports any invalid export option.
+func stdoutStream(opts *Options, ex *exporter.CustomerExporter, buckets []int, stages customerimporter.Pipeline) *exporter.RecordWriter {
+	if *opts.outFile != "" || *opts.format != "csv" || *opts.reverseIndex || *opts.chart || *opts.cache != "" || *opts.stateFile != "" || *opts.byMonth || *opts.bySubnet || *opts.health != "" || *opts.maxShare > 0 || *opts.postProcess != "" || *opts.baseline != "" || *opts.reference != "" || *opts.compare != "" || buckets != nil || len(stages) > 0 {
+		return nil
+	}
+	stream, err := ex.NewRecordWriter(os.Stdout)
+	if err != nil {
+		return nil
+	}
+	return stream
+}
+
+// writeFingerprint prints the run fingerprint to stderr when -fingerprint is set and writes it
+// to -fingerprint-file when given. Besides the importer and exporter configuration it hashes the
+// resolved flag values, which also cover CLI-only settings such as -top or -format; flags that
+// do not affect the result, and the input path (only its content counts), are left out.
+func writeFingerprint(opts *Options, importer *customerimporter.CustomerImporter, exporter *exporter.CustomerExporter) error {
+	var flags []string
+	flag.VisitAll(func(f *flag.Flag) {
+		switch f.Name {
+		case "path", "verbose", "explain", "config", "fingerprint", "fingerprint-file":
+			return
+		}
+		flags = append(flags, f.Name+"="+f.Value.String())
+	})
+	fp, err := importer.Fingerprint(exporter.Config().String(), strings.Join(flags, "\n"))
+	if err != nil {
+		return err
+	}
+	if *opts.fingerprint {
+		fmt.Fprintf(os.Stderr, "fingerprint=%s\n", fp)
+	}
+	if *opts.fpFile != "" {
+		return os.WriteFile(*opts.fpFile, []byte(fp+"\n"), 0666)
+	}
+	return nil
+}
+
+// writeList prints or exports the sorted domain names, exiting on failure.
+func writeList(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportListTo(os.Stdout, data); err != nil {
+			slog.Error("failed to print domain list", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportList(data); err != nil {
+		slog.Error("failed to export domain list", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+}
+
+// defaultChartTop is the number of domains charted by -chart without -top.
+const defaultChartTop = 10
+
+// writeChart prints the -chart bar chart of the top domains, exiting on failure.
+func writeChart(opts *Options, data []customerimporter.DomainData) {
+	top := *opts.top
+	if top <= 0 {
+		top = defaultChartTop
+	}
+	if err := exporter.ExportChartTo(os.Stdout, customerimporter.TopN(top)(data), *opts.chartWidth); err != nil {
+		slog.Error("failed to print chart", "error", err)
+		exit(1)
+	}
+}
+
+// writeReverseIndex prints or exports the count -> domains reverse index, exiting on failure.
+func writeReverseIndex(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportReverseIndexTo(os.Stdout, data); err != nil {
+			slog.Error("failed to print reverse index", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportReverseIndex(data); err != nil {
+		slog.Error("failed to export reverse index", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "counts", len(customerimporter.GroupByCount(data)))
+}
+
+// writeBreakdown prints or exports the role/personal account breakdown, exiting on failure.
+func writeBreakdown(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.DomainData) {
+	breakdown := customerimporter.Breakdown(data)
+	if *opts.outFile == "" {
+		if err := exporter.ExportBreakdownTo(os.Stdout, breakdown); err != nil {
+			slog.Error("failed to print breakdown", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportBreakdown(breakdown); err != nil {
+		slog.Error("failed to export breakdown", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(breakdown))
+}
+
+// writeEnvelope prints or exports the domains as a JSON envelope with the import metadata,
+// exiting on failure.
+func writeEnvelope(opts *Options, ex *exporter.CustomerExporter, summary customerimporter.Summary, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := ex.ExportEnvelopeTo(os.Stdout, *opts.path, summary, data); err != nil {
+			slog.Error("failed to print envelope", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportEnvelope(*opts.path, summary, data); err != nil {
+		slog.Error("failed to export envelope", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+}
+
+// writeJSONTree prints or exports the -format=json-tree domain tree, exiting on failure.
+func writeJSONTree(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportJSONTreeTo(os.Stdout, data); err != nil {
+			slog.Error("failed to print domain tree", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportJSONTree(data); err != nil {
+		slog.Error("failed to export domain tree", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+}
+
+// writeXLSX exports the -format=xlsx workbook to -out, exiting on failure.
+func writeXLSX(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.DomainData) {
+	if err := ex.ExportXLSX(data); err != nil {
+		slog.Error("failed to export workbook", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+}
+
+// loadBaseline reads the -baseline export, exiting on failure. It returns nil without -baseline.
+func loadBaseline(opts *Options) map[string]uint64 {
+	if *opts.baseline == "" {
+		return nil
+	}
+	baseline, err := exporter.LoadBaseline(*opts.baseline)
+	if err != nil {
+		slog.Error("failed to load baseline", "error", err, "file", *opts.baseline)
+		exit(1)
+	}
+	return baseline
+}
+
+// loadReference reads the -reference distribution, exiting on failure. It returns nil without
+// -reference.
+func loadReference(opts *Options) map[string]float64 {
+	if *opts.reference == "" {
+		return nil
+	}
+	reference, err := exporter.LoadReference(*opts.reference)
+	if err != nil {
+		slog.Error("failed to load reference distribution", "error", err, "file", *opts.reference)
+		exit(1)
+	}
+	return reference
+}
+
+// loadComparison imports the -compare file with the options of the main import, except that its
+// rejects are not written and the -cache and -state-file of the main input are neither read nor
+// replaced, exiting on failure. It returns nil without -compare.
+func loadComparison(opts *Options, importOpts []customerimporter.Option, stages customerimporter.Pipeline) []customerimporter.DomainData {
+	if *opts.compare == "" {
+		return nil
+	}
+	importOpts = append(slices.Clip(importOpts),
+		customerimporter.WithRejectsOutput(""),
+		customerimporter.WithAggregationCache(""),
+		customerimporter.WithIncrementalState(""))
+	slog.Info("importing comparison input", "file", *opts.compare)
+	data, _, err := customerimporter.NewCustomerImporter(*opts.compare, importOpts...).ImportWithSummary(context.Background())
+	if err != nil {
+		slog.Error("failed to import comparison input", "error", err, "file", *opts.compare)
+		exit(1)
+	}
+	return stages.Apply(data)
+}
+
+// writeComparison prints or exports the comparison of the -compare input with data, exiting on
+// failure.
+func writeComparison(opts *Options, ex *exporter.CustomerExporter, compared, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportComparisonTo(os.Stdout, compared, data); err != nil {
+			slog.Error("failed to print comparison", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportComparison(compared, data); err != nil {
+		slog.Error("failed to export comparison", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "compare", *opts.compare)
+}
+
+// writeTrend prints or exports the comparison of data with the baseline, exiting on failure.
+func writeTrend(opts *Options, ex *exporter.CustomerExporter, baseline map[string]uint64, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportTrendTo(os.Stdout, baseline, data); err != nil {
+			slog.Error("failed to print trend", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportTrend(baseline, data); err != nil {
+		slog.Error("failed to export trend", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "baseline", *opts.baseline)
+}
+
+// writeChiSquare prints or exports the comparison of data with the reference distribution,
+// exiting on failure.
+func writeChiSquare(opts *Options, ex *exporter.CustomerExporter, reference map[string]float64, data []customerimporter.DomainData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportChiSquareTo(os.Stdout, reference, data); err != nil {
+			slog.Error("failed to print chi-square comparison", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportChiSquare(reference, data); err != nil {
+		slog.Error("failed to export chi-square comparison", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "reference", *opts.reference)
+}
+
+// writeSubnets prints or exports the per-subnet aggregation, exiting on failure.
+func writeSubnets(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.SubnetData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportSubnetsTo(os.Stdout, data); err != nil {
+			slog.Error("failed to print subnet aggregation", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportSubnets(data); err != nil {
+		slog.Error("failed to export subnet aggregation", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+}
+
+// writeMonthly prints or exports the per-domain monthly breakdown, exiting on failure.
+func writeMonthly(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.MonthlyData) {
+	if *opts.outFile == "" {
+		if err := exporter.ExportMonthlyTo(os.Stdout, data); err != nil {
+			slog.Error("failed to print monthly breakdown", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportMonthly(data); err != nil {
+		slog.Error("failed to export monthly breakdown", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+}
+
+// validateOutputMode checks that at most one alternative output mode is selected and that
+// -anonymize is only combined with the CSV output.
+func validateOutputMode(opts *Options) error {
+	switch *opts.format {
+	case "csv", "list", "breakdown", "json", "json-tree":
+	case "xlsx":
+		if *opts.outFile == "" {
+			return fmt.Errorf("-format=xlsx writes a binary workbook and requires -out")
+		}
+	default:
+		return fmt.Errorf("invalid -format %q: want \"csv\", \"list\", \"breakdown\", \"json\", \"json-tree\" or \"xlsx\"", *opts.format)
+	}
+	var modes []string
+	if *opts.format != "csv" {
+		modes = append(modes, "-format="+*opts.format)
+	}
+	if *opts.reverseIndex {
+		modes = append(modes, "-reverse-index")
+	}
+	if *opts.chart {
+		if *opts.outFile != "" {
+			return fmt.Errorf("-chart prints to the terminal and cannot be combined with -out")
+		}
+		if *opts.chartWidth <= 0 {
+			return fmt.Errorf("invalid -chart-width %d: want a positive width", *opts.chartWidth)
+		}
+		modes = append(modes, "-chart")
+	}
+	if *opts.rollup != "" {
+		modes = append(modes, "-rollup")
+	}
+	if *opts.baseline != "" {
+		modes = append(modes, "-baseline")
+	}
+	if *opts.reference != "" {
+		modes = append(modes, "-reference")
+	}
+	if *opts.compare != "" {
+		modes = append(modes, "-compare")
+	}
+	if *opts.byMonth {
+		if *opts.dateColumn < 0 {
+			return fmt.Errorf("-by-month requires -date-column")
+		}
+		modes = append(modes, "-by-month")
+	}
+	if *opts.bySubnet {
+		if *opts.ipColumn < 0 {
+			return fmt.Errorf("-by-subnet requires -ip-column")
+		}
+		modes = append(modes, "-by-subnet")
+	}
+	if len(modes) > 1 {
+		return fmt.Errorf("%s cannot be combined", strings.Join(modes, " and "))
+	}
+	if *opts.anonymize {
+		// Only the CSV columns are anonymized; every other output would leak the real domains
+		if *opts.template != "" {
+			modes = append(modes, "-template")
+		}
+		if *opts.health != "" {
+			modes = append(modes, "-health")
+		}
+		if len(modes) > 0 {
+			return fmt.Errorf("-anonymize only applies to -format=csv and cannot be combined with %s", strings.Join(modes, " or "))
+		}
+	}
+	if *opts.chunkSize > 0 && (*opts.outFile == "" || len(modes) > 0) {
+		return fmt.Errorf("-chunk-size requires -out with -format=csv")
+	}
+	if *opts.manifest && *opts.outFile == "" {
+		return fmt.Errorf("-manifest requires -out")
+	}
+	switch *opts.health {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid -health %q: want \"text\" or \"json\"", *opts.health)
+	}
+	if *opts.health != "" && *opts.byMonth {
+		return fmt.Errorf("-health cannot be combined with -by-month")
+	}
+	if *opts.health != "" && *opts.bySubnet {
+		return fmt.Errorf("-health cannot be combined with -by-subnet")
+	}
+	if *opts.maxShare < 0 || *opts.maxShare > 1 {
+		return fmt.Errorf("invalid -max-share %v: want a share between 0 and 1", *opts.maxShare)
+	}
+	if *opts.stateFile != "" && (*opts.byMonth || *opts.bySubnet) {
+		return fmt.Errorf("-state-file cannot be combined with -by-month or -by-subnet")
+	}
+	if *opts.postProcess != "" && (*opts.byMonth || *opts.bySubnet) {
+		return fmt.Errorf("-post-process cannot be combined with -by-month or -by-subnet")
+	}
+	if *opts.maxShare > 0 && (*opts.byMonth || *opts.bySubnet) {
+		return fmt.Errorf("-max-share cannot be combined with -by-month or -by-subnet")
+	}
+	return nil
+}
+
+// printCasingVariants prints the -casing-report to stderr, one "domain: spelling, ..." line per
+// inconsistently cased domain, so it is shown without -verbose.
+func printCasingVariants(variants []customerimporter.CasingVariants) {
+	for _, v := range variants {
+		fmt.Fprintf(os.Stderr, "inconsistent casing: %s: %s\n", v.Domain, strings.Join(v.Variants, ", "))
+	}
+}
+
+// writeHealth prints the -health report to stderr, exiting on failure.
+func writeHealth(opts *Options, report customerimporter.HealthReport) {
+	if *opts.health == "text" {
+		fmt.Fprint(os.Stderr, report)
+		return
+	}
+	encoder := json.NewEncoder(os.Stderr)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		slog.Error("failed to print health report", "error", err)
+		exit(1)
+	}
+}
+
+// loadDomainGroups reads the -domain-groups JSON object of group names to domain lists.
+func loadDomainGroups(path string) (map[string][]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var groups map[string][]string
+	if err := json.Unmarshal(content, &groups); err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		return nil, fmt.Errorf("%s: want a JSON object of group names to domain lists", path)
+	}
+	return groups, nil
+}
+
+// rollupBuckets validates -rollup and returns the domain length buckets to use, or nil when no
+// rollup was requested.
+func rollupBuckets(opts *Options) ([]int, error) {
+	switch *opts.rollup {
+	case "":
+		return nil, nil
+	case "length":
+		buckets, err := parseIntList(*opts.lengthBuckets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -length-buckets: %w", err)
+		}
+		return buckets, nil
+	default:
+		return nil, fmt.Errorf("invalid -rollup %q: want \"length\"", *opts.rollup)
+	}
+}
+
+// writeLengthRollup prints or exports the customer totals per domain length bucket, exiting on
+// failure.
+func writeLengthRollup(opts *Options, ex *exporter.CustomerExporter, data []customerimporter.DomainData, buckets []int) {
+	const dimension = "domain_length"
+	totals := customerimporter.GroupByDomainLength(data, buckets)
+	labels := customerimporter.LengthBucketLabels(buckets)
+	if *opts.outFile == "" {
+		if err := exporter.ExportRollupTo(os.Stdout, dimension, labels, totals); err != nil {
+			slog.Error("failed to print rollup", "error", err)
+			exit(1)
+		}
+		return
+	}
+	if err := ex.ExportRollup(dimension, labels, totals); err != nil {
+		slog.Error("failed to export rollup", "error", err, "file", *opts.outFile)
+		exit(1)
+	}
+	slog.Info("export complete", "file", *opts.outFile, "buckets", len(labels))
+}
+
+// writeSkipReasons exports the -skip-reasons table of the import, exiting on failure.
+func writeSkipReasons(opts *Options, summary customerimporter.Summary) {
+	if err := exporter.NewCustomerExporter(*opts.skipReasons).ExportSkipReasons(summary.SkipReasons); err != nil {
+		slog.Error("failed to export skip reasons", "error", err, "file", *opts.skipReasons)
+		exit(1)
+	}
+}
+
+// addRejectsToManifest lists the rejects file in the manifest written by the export, so it
+// covers every file the run produced.
+func addRejectsToManifest(opts *Options, ex *exporter.CustomerExporter, summary customerimporter.Summary) error {
+	if !*opts.manifest || *opts.rejects == "" {
+		return nil
+	}
+	manifest, err := exporter.ReadManifest(ex.ManifestPath())
+	if err != nil {
+		return err
+	}
+	if err := manifest.Add(*opts.rejects, int(summary.SkippedRows)); err != nil {
+		return err
+	}
+	return manifest.Write(ex.ManifestPath())
 }
 
 func main() {
-	opts := readOptions()
+	opts, err := readOptions()
+	runState.summaryOut, runState.recorder = *opts.summaryOut, exporter.NewRunRecorder(*opts.path)
 	setupLogger(*opts.verbose)
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		exit(1)
+	}
+
+	importOpts, err := importerOptions(opts)
+	if err != nil {
+		slog.Error("invalid options", "error", err)
+		exit(1)
+	}
+	if err := validateOutputMode(opts); err != nil {
+		slog.Error("invalid options", "error", err)
+		exit(1)
+	}
+	buckets, err := rollupBuckets(opts)
+	if err != nil {
+		slog.Error("invalid options", "error", err)
+		exit(1)
+	}
+	importer := customerimporter.NewCustomerImporter(*opts.path, importOpts...)
+	exportOpts, err := exporterOptions(opts)
+	if err != nil {
+		slog.Error("invalid options", "error", err)
+		exit(1)
+	}
+	exporter := exporter.NewCustomerExporter(*opts.outFile, exportOpts...)
+	if *opts.explain {
+		explain(os.Stderr, opts, importer, exporter)
+	}
+
+	if *opts.fingerprint || *opts.fpFile != "" {
+		if err := writeFingerprint(opts, importer, exporter); err != nil {
+			slog.Error("failed to fingerprint run", "error", err, "file", *opts.path)
+			exit(1)
+		}
+	}
+
+	if *opts.maxAge > 0 {
+		if err := importer.CheckFreshness(*opts.maxAge); err != nil {
+			slog.Error("input freshness check failed", "error", err, "file", *opts.path)
+			exit(1)
+		}
+	}
+
+	// Reject files with the wrong layout before the potentially long full import
+	if importer.Config().ValidateHeader {
+		if err := importer.CheckHeader(); err != nil {
+			slog.Error("header validation failed", "error", err, "file", *opts.path)
+			exit(1)
+		}
+	}
+
+	baseline := loadBaseline(opts)
+	reference := loadReference(opts)
+	stages := pipeline(opts)
+	compared := loadComparison(opts, importOpts, stages)
 
 	startTime := time.Now()
 	slog.Info("starting customer domain import", "file", *opts.path)
 
-	importer := customerimporter.NewCustomerImporter(*opts.path)
-	data, err := importer.ImportDomainData()
-	if err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	var data []customerimporter.DomainData
+	var monthly []customerimporter.MonthlyData
+	var subnets []customerimporter.SubnetData
+	var summary customerimporter.Summary
+	var health customerimporter.HealthReport
+	var perDomain bool
+	stream := stdoutStream(opts, exporter, buckets, stages)
+	if stream != nil {
+		summary, err = importer.StreamDomainData(ctx, stream.Write)
+	} else if *opts.byMonth {
+		monthly, summary, err = importer.ImportMonthly(ctx)
+	} else if *opts.bySubnet {
+		subnets, summary, err = importer.ImportSubnets(ctx)
+	} else {
+		data, summary, err = importer.ImportWithSummary(ctx)
+		perDomain = true
+	}
+	runState.recorder.SetSummary(summary)
+	if perDomain {
+		if *opts.health != "" {
+			health = customerimporter.NewHealthReport(data, summary)
+		}
+		if shareErr := customerimporter.CheckMaxShare(data, *opts.maxShare); shareErr != nil && err == nil {
+			slog.Error("domain concentration check failed", "error", shareErr, "file", *opts.path)
+			exit(1)
+		}
+		data = stages.Apply(data)
+		if *opts.postProcess != "" && err == nil {
+			if data, err = postProcess(ctx, opts, data); err != nil {
+				slog.Error("failed to post-process domain data", "error", err, "command", *opts.postProcess)
+				exit(1)
+			}
+		}
+	}
+	stop()
+	partial := errors.Is(err, customerimporter.ErrPartialResult)
+	if err != nil && !partial {
 		slog.Error("failed to import customer data", "error", err, "file", *opts.path)
-		os.Exit(1)
+		exit(1)
+	}
+	if errors.Is(err, customerimporter.ErrMaxDurationExceeded) {
+		// The time budget is part of the job definition, so its partial result is a success
+		slog.Error("import stopped at -max-duration, output contains a partial result", "rows", summary.Rows, "domains", summary.Domains)
+		partial = false
+	} else if partial {
+		slog.Error("import interrupted, output contains a partial result", "error", err, "domains", summary.Domains)
 	}
 
 	duration := time.Since(startTime)
 	slog.Info("import complete",
-		"domains", len(data),
+		"domains", summary.Domains,
+		"rows", summary.Rows,
+		"skipped_rows", summary.SkippedRows,
+		"duplicates", summary.Duplicates,
+		"trimmed_values", summary.TrimmedValues,
+		"unsampled_rows", summary.UnsampledRows,
+		"filtered_rows", summary.FilteredRows,
 		"duration", duration.Round(time.Millisecond).String())
+	if *opts.health != "" {
+		writeHealth(opts, health)
+	}
 
-	if *opts.outFile == "" {
-		printData(data)
+	if stream != nil {
+		if printErr := stream.Flush(); printErr != nil {
+			slog.Error("failed to print domain data", "error", printErr)
+			exit(1)
+		}
+	} else if *opts.byMonth {
+		writeMonthly(opts, exporter, monthly)
+	} else if *opts.bySubnet {
+		writeSubnets(opts, exporter, subnets)
+	} else if baseline != nil {
+		writeTrend(opts, exporter, baseline, data)
+	} else if reference != nil {
+		writeChiSquare(opts, exporter, reference, data)
+	} else if compared != nil {
+		writeComparison(opts, exporter, compared, data)
+	} else if buckets != nil {
+		writeLengthRollup(opts, exporter, data, buckets)
+	} else if *opts.chart {
+		writeChart(opts, data)
+	} else if *opts.reverseIndex {
+		writeReverseIndex(opts, exporter, data)
+	} else if *opts.format == "list" {
+		writeList(opts, exporter, data)
+	} else if *opts.format == "breakdown" {
+		writeBreakdown(opts, exporter, data)
+	} else if *opts.format == "json" {
+		writeEnvelope(opts, exporter, summary, data)
+	} else if *opts.format == "json-tree" {
+		writeJSONTree(opts, exporter, data)
+	} else if *opts.format == "xlsx" {
+		writeXLSX(opts, exporter, data)
+	} else if *opts.outFile == "" {
+		if printErr := exporter.ExportTo(os.Stdout, data); printErr != nil {
+			slog.Error("failed to print domain data", "error", printErr)
+			exit(1)
+		}
 	} else {
-		exporter := exporter.NewCustomerExporter(*opts.outFile)
-		if saveErr := exporter.ExportData(data); saveErr != nil {
+		status, saveErr := exporter.Export(data)
+		if saveErr != nil {
 			slog.Error("failed to export domain data", "error", saveErr, "file", *opts.outFile)
-			os.Exit(1)
+			exit(1)
 		}
-		slog.Info("export complete", "file", *opts.outFile, "records", len(data))
+		slog.Info("export complete", "file", *opts.outFile, "records", len(data), "status", status)
+	}
+	if *opts.skipReasons != "" {
+		writeSkipReasons(opts, summary)
+	}
+	if err := addRejectsToManifest(opts, exporter, summary); err != nil {
+		slog.Error("failed to update manifest", "error", err, "file", exporter.ManifestPath())
+		exit(1)
 	}
-}
 
-func printData(data []customerimporter.DomainData) {
-	fmt.Println("domain,number_of_customers")
-	for _, v := range data {
-		fmt.Printf("%s,%v\n", v.Domain, v.CustomerQuantity)
+	if partial {
+		exit(exitInterrupted)
 	}
+	exit(0)
 }